@@ -1,4 +1,4 @@
-.PHONY: help jwt-test TestGenerateKeyPair_Success TestGenerateKeyPair_InvalidUserData TestGenerateKeyPair_NilPrivateKey TestGenerateKeyPair_NilHelpers TestRefreshAccessToken_Success TestRefreshAccessToken_InvalidToken TestRefreshAccessToken_ExpiredToken TestRefreshAccessToken_MissingClaims
+.PHONY: help jwt-test TestGenerateKeyPair_Success TestGenerateKeyPair_InvalidUserData TestGenerateKeyPair_NilPrivateKey TestGenerateKeyPair_NilHelpers TestRefreshAccessToken_Success TestRefreshAccessToken_InvalidToken TestRefreshAccessToken_ExpiredToken TestRefreshAccessToken_MissingClaims TestRefreshAccessToken_AccessToken
 
 help:
 	@ECHO +------------------------------------------------------+
@@ -14,6 +14,7 @@ help:
 	@ECHO   > make TestRefreshAccessToken_InvalidToken          - Run JwtFuncsTestSuite/TestRefreshAccessToken_InvalidToken
 	@ECHO   > make TestRefreshAccessToken_ExpiredToken          - Run JwtFuncsTestSuite/TestRefreshAccessToken_ExpiredToken
 	@ECHO   > make TestRefreshAccessToken_MissingClaims         - Run JwtFuncsTestSuite/TestRefreshAccessToken_MissingClaims
+	@ECHO   > make TestRefreshAccessToken_AccessToken           - Run JwtFuncsTestSuite/TestRefreshAccessToken_AccessToken
 	@ECHO   > make help                                        - Display this help information
 
 jwt-test:
@@ -44,4 +45,7 @@ TestRefreshAccessToken_ExpiredToken:
 	go test -v ../test/jwt/functions -run 'TestJwtFuncsTestSuite/TestRefreshAccessToken_ExpiredToken'
 
 TestRefreshAccessToken_MissingClaims:
-	go test -v ../test/jwt/functions -run 'TestJwtFuncsTestSuite/TestRefreshAccessToken_MissingClaims'
\ No newline at end of file
+	go test -v ../test/jwt/functions -run 'TestJwtFuncsTestSuite/TestRefreshAccessToken_MissingClaims'
+
+TestRefreshAccessToken_AccessToken:
+	go test -v ../test/jwt/functions -run 'TestJwtFuncsTestSuite/TestRefreshAccessToken_AccessToken'
@@ -0,0 +1,23 @@
+.PHONY: help jwt-keys-test TestRotation TestJWKS TestLoad
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    JWT Key Ring Tests - Make Commands                ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make jwt-keys-test                                 - Run all tests for TestKeyRingTestSuite
+	@ECHO   ^> make TestRotation                                  - Run TestKeyRingTestSuite/TestRotation
+	@ECHO   ^> make TestJWKS                                      - Run TestKeyRingTestSuite/TestJWKS
+	@ECHO   ^> make TestLoad                                      - Run TestKeyRingTestSuite/TestLoad
+	@ECHO   ^> make help                                          - Display this help information
+
+jwt-keys-test:
+	go test -v ../test/jwt/keys -run 'TestKeyRingTestSuite'
+
+TestRotation:
+	go test -v ../test/jwt/keys -run 'TestKeyRingTestSuite/TestRotation'
+
+TestJWKS:
+	go test -v ../test/jwt/keys -run 'TestKeyRingTestSuite/TestJWKS'
+
+TestLoad:
+	go test -v ../test/jwt/keys -run 'TestKeyRingTestSuite/TestLoad'
@@ -0,0 +1,35 @@
+.PHONY: help jwt-service-test TestIssueTokens_InvalidUserData TestRefreshTokens_Rotates TestRefreshTokens_KeepsRoles TestRefreshTokens_ReuseRevokesFamily TestRefreshTokens_Unknown TestRevokeTokens
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    JWT Service Tests - Make Commands                 ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make jwt-service-test                              - Run all tests for TestJwtServiceTestSuite
+	@ECHO   ^> make TestIssueTokens_InvalidUserData               - Run TestJwtServiceTestSuite/TestIssueTokens_InvalidUserData
+	@ECHO   ^> make TestRefreshTokens_Rotates                     - Run TestJwtServiceTestSuite/TestRefreshTokens_Rotates
+	@ECHO   ^> make TestRefreshTokens_KeepsRoles                  - Run TestJwtServiceTestSuite/TestRefreshTokens_KeepsRoles
+	@ECHO   ^> make TestRefreshTokens_ReuseRevokesFamily          - Run TestJwtServiceTestSuite/TestRefreshTokens_ReuseRevokesFamily
+	@ECHO   ^> make TestRefreshTokens_Unknown                     - Run TestJwtServiceTestSuite/TestRefreshTokens_Unknown
+	@ECHO   ^> make TestRevokeTokens                              - Run TestJwtServiceTestSuite/TestRevokeTokens
+	@ECHO   ^> make help                                          - Display this help information
+
+jwt-service-test:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite'
+
+TestIssueTokens_InvalidUserData:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestIssueTokens_InvalidUserData'
+
+TestRefreshTokens_Rotates:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestRefreshTokens_Rotates'
+
+TestRefreshTokens_KeepsRoles:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestRefreshTokens_KeepsRoles'
+
+TestRefreshTokens_ReuseRevokesFamily:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestRefreshTokens_ReuseRevokesFamily'
+
+TestRefreshTokens_Unknown:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestRefreshTokens_Unknown'
+
+TestRevokeTokens:
+	go test -v ../test/jwt/service -run 'TestJwtServiceTestSuite/TestRevokeTokens'
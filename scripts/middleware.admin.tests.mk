@@ -0,0 +1,19 @@
+.PHONY: help auth-middleware-test TestAuthenticated TestRequireScopes
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Auth Middleware Tests - Make Commands             ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make auth-middleware-test                          - Run all tests for TestAuthMiddlewareTestSuite
+	@ECHO   ^> make TestAuthenticated                             - Run TestAuthMiddlewareTestSuite/TestAuthenticated
+	@ECHO   ^> make TestRequireScopes                             - Run TestAuthMiddlewareTestSuite/TestRequireScopes
+	@ECHO   ^> make help                                          - Display this help information
+
+auth-middleware-test:
+	go test -v ../test/middleware/admin -run 'TestAuthMiddlewareTestSuite'
+
+TestAuthenticated:
+	go test -v ../test/middleware/admin -run 'TestAuthMiddlewareTestSuite/TestAuthenticated'
+
+TestRequireScopes:
+	go test -v ../test/middleware/admin -run 'TestAuthMiddlewareTestSuite/TestRequireScopes'
@@ -0,0 +1,75 @@
+.PHONY: help directory-test TestHttpGetReferrer_Success TestHttpGetReferrer_RetriesServerErrors TestHttpGetReferrer_RetriesExhausted TestHttpGetReferrer_NotFoundIsNotRetried TestHttpGetReferrer_Timeout TestFixtureGetReferrer_Success TestFixtureGetReferrer_NotFound TestFixtureDirectory_MissingFile cache-test TestCachedDirectory_HitsUpstreamOnce TestCachedDirectory_Expires TestCachedDirectory_ErrorsAreNotCached TestResolveChain_FullChain TestResolveChain_LimitedByDepth TestResolveChain_Memoized TestResolveChain_PartialOnError
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Referrer Directory Tests - Make Commands          ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make directory-test                                - Run all tests for TestReferrerDirectoryTestSuite
+	@ECHO   ^> make TestHttpGetReferrer_Success                   - Run TestReferrerDirectoryTestSuite/TestHttpGetReferrer_Success
+	@ECHO   ^> make TestHttpGetReferrer_RetriesServerErrors       - Run TestReferrerDirectoryTestSuite/TestHttpGetReferrer_RetriesServerErrors
+	@ECHO   ^> make TestHttpGetReferrer_RetriesExhausted          - Run TestReferrerDirectoryTestSuite/TestHttpGetReferrer_RetriesExhausted
+	@ECHO   ^> make TestHttpGetReferrer_NotFoundIsNotRetried      - Run TestReferrerDirectoryTestSuite/TestHttpGetReferrer_NotFoundIsNotRetried
+	@ECHO   ^> make TestHttpGetReferrer_Timeout                   - Run TestReferrerDirectoryTestSuite/TestHttpGetReferrer_Timeout
+	@ECHO   ^> make TestFixtureGetReferrer_Success                - Run TestReferrerDirectoryTestSuite/TestFixtureGetReferrer_Success
+	@ECHO   ^> make TestFixtureGetReferrer_NotFound               - Run TestReferrerDirectoryTestSuite/TestFixtureGetReferrer_NotFound
+	@ECHO   ^> make TestFixtureDirectory_MissingFile              - Run TestReferrerDirectoryTestSuite/TestFixtureDirectory_MissingFile
+	@ECHO   ^> make cache-test                                    - Run all tests for TestReferrerCacheTestSuite
+	@ECHO   ^> make TestCachedDirectory_HitsUpstreamOnce          - Run TestReferrerCacheTestSuite/TestCachedDirectory_HitsUpstreamOnce
+	@ECHO   ^> make TestCachedDirectory_Expires                   - Run TestReferrerCacheTestSuite/TestCachedDirectory_Expires
+	@ECHO   ^> make TestCachedDirectory_ErrorsAreNotCached        - Run TestReferrerCacheTestSuite/TestCachedDirectory_ErrorsAreNotCached
+	@ECHO   ^> make TestResolveChain_FullChain                    - Run TestReferrerCacheTestSuite/TestResolveChain_FullChain
+	@ECHO   ^> make TestResolveChain_LimitedByDepth               - Run TestReferrerCacheTestSuite/TestResolveChain_LimitedByDepth
+	@ECHO   ^> make TestResolveChain_Memoized                     - Run TestReferrerCacheTestSuite/TestResolveChain_Memoized
+	@ECHO   ^> make TestResolveChain_PartialOnError               - Run TestReferrerCacheTestSuite/TestResolveChain_PartialOnError
+	@ECHO   ^> make help                                          - Display this help information
+
+directory-test:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite'
+
+TestHttpGetReferrer_Success:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestHttpGetReferrer_Success'
+
+TestHttpGetReferrer_RetriesServerErrors:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestHttpGetReferrer_RetriesServerErrors'
+
+TestHttpGetReferrer_RetriesExhausted:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestHttpGetReferrer_RetriesExhausted'
+
+TestHttpGetReferrer_NotFoundIsNotRetried:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestHttpGetReferrer_NotFoundIsNotRetried'
+
+TestHttpGetReferrer_Timeout:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestHttpGetReferrer_Timeout'
+
+TestFixtureGetReferrer_Success:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestFixtureGetReferrer_Success'
+
+TestFixtureGetReferrer_NotFound:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestFixtureGetReferrer_NotFound'
+
+TestFixtureDirectory_MissingFile:
+	go test -v ../test/referral/directory -run 'TestReferrerDirectoryTestSuite/TestFixtureDirectory_MissingFile'
+
+cache-test:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite'
+
+TestCachedDirectory_HitsUpstreamOnce:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestCachedDirectory_HitsUpstreamOnce'
+
+TestCachedDirectory_Expires:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestCachedDirectory_Expires'
+
+TestCachedDirectory_ErrorsAreNotCached:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestCachedDirectory_ErrorsAreNotCached'
+
+TestResolveChain_FullChain:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestResolveChain_FullChain'
+
+TestResolveChain_LimitedByDepth:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestResolveChain_LimitedByDepth'
+
+TestResolveChain_Memoized:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestResolveChain_Memoized'
+
+TestResolveChain_PartialOnError:
+	go test -v ../test/referral/directory -run 'TestReferrerCacheTestSuite/TestResolveChain_PartialOnError'
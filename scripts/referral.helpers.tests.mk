@@ -0,0 +1,35 @@
+.PHONY: help helpers-test TestMergeJettonEntries_SameAddress TestMergeJettonEntries_BounceFlagIgnored TestMergeJettonEntries_DoesNotModifyInput TestPackJettonEntries TestPackJettonEntries_TooLarge TestPackJettonEntries_Empty
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Referral Helpers Tests - Make Commands            ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make helpers-test                                  - Run all tests for TestBatchHelpersTestSuite
+	@ECHO   ^> make TestMergeJettonEntries_SameAddress            - Run TestBatchHelpersTestSuite/TestMergeJettonEntries_SameAddress
+	@ECHO   ^> make TestMergeJettonEntries_BounceFlagIgnored      - Run TestBatchHelpersTestSuite/TestMergeJettonEntries_BounceFlagIgnored
+	@ECHO   ^> make TestMergeJettonEntries_DoesNotModifyInput     - Run TestBatchHelpersTestSuite/TestMergeJettonEntries_DoesNotModifyInput
+	@ECHO   ^> make TestPackJettonEntries                         - Run TestBatchHelpersTestSuite/TestPackJettonEntries
+	@ECHO   ^> make TestPackJettonEntries_TooLarge                - Run TestBatchHelpersTestSuite/TestPackJettonEntries_TooLarge
+	@ECHO   ^> make TestPackJettonEntries_Empty                   - Run TestBatchHelpersTestSuite/TestPackJettonEntries_Empty
+	@ECHO   ^> make help                                          - Display this help information
+
+helpers-test:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite'
+
+TestMergeJettonEntries_SameAddress:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestMergeJettonEntries_SameAddress'
+
+TestMergeJettonEntries_BounceFlagIgnored:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestMergeJettonEntries_BounceFlagIgnored'
+
+TestMergeJettonEntries_DoesNotModifyInput:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestMergeJettonEntries_DoesNotModifyInput'
+
+TestPackJettonEntries:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestPackJettonEntries'
+
+TestPackJettonEntries_TooLarge:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestPackJettonEntries_TooLarge'
+
+TestPackJettonEntries_Empty:
+	go test -v ../test/referral/helpers -run 'TestBatchHelpersTestSuite/TestPackJettonEntries_Empty'
@@ -1,4 +1,4 @@
-.PHONY: help referral-test TestGetPaymentOrdersByAuthorID TestGetAllPaymentOrders TestCreatePaymentOrder TestGetPaymentOrdersByAuthorID_Empty
+.PHONY: help referral-test TestGetPaymentOrdersByAuthorID TestGetAllPaymentOrders TestCreatePaymentOrder TestGetPaymentOrdersByAuthorID_Empty TestClaimIdempotencyKey TestClaimIdempotencyKey_Expired TestClaimIdempotencyKey_StaleProcessing TestPayoutLifecycle TestRequeuePayout TestCancelPaymentOrder TestCancelAllPaymentOrders TestPaymentOrderLifecycle TestExpirePaymentOrders TestPaymentBundleLifecycle TestLeaderProgramVersions TestBonusScheduleInForce
 
 help:
 	@ECHO +------------------------------------------------------+
@@ -10,6 +10,18 @@ help:
 	@ECHO   ^> make TestCreatePaymentOrder                        - Run TestReferralRepositoryTestSuite/TestCreatePaymentOrder
 	@ECHO   ^> make TestGetPaymentOrdersByAuthorID_Empty          - Run TestReferralRepositoryTestSuite/TestGetPaymentOrdersByAuthorID_Empty
 	@ECHO   ^> make TestAddTrHashToPaymentOrder                    - Run TestReferralRepositoryTestSuite/TestAddTrHashToPaymentOrder
+	@ECHO   ^> make TestClaimIdempotencyKey                       - Run TestReferralRepositoryTestSuite/TestClaimIdempotencyKey
+	@ECHO   ^> make TestClaimIdempotencyKey_Expired               - Run TestReferralRepositoryTestSuite/TestClaimIdempotencyKey_Expired
+	@ECHO   ^> make TestClaimIdempotencyKey_StaleProcessing       - Run TestReferralRepositoryTestSuite/TestClaimIdempotencyKey_StaleProcessing
+	@ECHO   ^> make TestPayoutLifecycle                           - Run TestReferralRepositoryTestSuite/TestPayoutLifecycle
+	@ECHO   ^> make TestRequeuePayout                             - Run TestReferralRepositoryTestSuite/TestRequeuePayout
+	@ECHO   ^> make TestCancelPaymentOrder                        - Run TestReferralRepositoryTestSuite/TestCancelPaymentOrder
+	@ECHO   ^> make TestCancelAllPaymentOrders                    - Run TestReferralRepositoryTestSuite/TestCancelAllPaymentOrders
+	@ECHO   ^> make TestPaymentOrderLifecycle                     - Run TestReferralRepositoryTestSuite/TestPaymentOrderLifecycle
+	@ECHO   ^> make TestExpirePaymentOrders                       - Run TestReferralRepositoryTestSuite/TestExpirePaymentOrders
+	@ECHO   ^> make TestPaymentBundleLifecycle                    - Run TestReferralRepositoryTestSuite/TestPaymentBundleLifecycle
+	@ECHO   ^> make TestLeaderProgramVersions                     - Run TestReferralRepositoryTestSuite/TestLeaderProgramVersions
+	@ECHO   ^> make TestBonusScheduleInForce                      - Run TestReferralRepositoryTestSuite/TestBonusScheduleInForce
 	@ECHO   ^> make help                                          - Display this help information
 
 referral-test:
@@ -27,11 +39,44 @@ TestCreatePaymentOrder:
 TestGetPaymentOrdersByAuthorID_Empty:
 	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestGetPaymentOrdersByAuthorID_Empty'
 
-TestDeletePaymentOrder:
-	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestDeletePaymentOrder'
+TestCancelPaymentOrder:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestCancelPaymentOrder'
 
 TestGetDebtFromAuthorToReferrer:
 	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestGetDebtFromAuthorToReferrer'
 
 TestAddTrHashToPaymentOrder:
-	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestAddTrHashToPaymentOrder'
\ No newline at end of file
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestAddTrHashToPaymentOrder'
+
+TestClaimIdempotencyKey:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestClaimIdempotencyKey'
+
+TestClaimIdempotencyKey_Expired:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestClaimIdempotencyKey_Expired'
+
+TestClaimIdempotencyKey_StaleProcessing:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestClaimIdempotencyKey_StaleProcessing'
+
+TestPayoutLifecycle:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestPayoutLifecycle'
+
+TestRequeuePayout:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestRequeuePayout'
+
+TestCancelAllPaymentOrders:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestCancelAllPaymentOrders'
+
+TestPaymentOrderLifecycle:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestPaymentOrderLifecycle'
+
+TestExpirePaymentOrders:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestExpirePaymentOrders'
+
+TestPaymentBundleLifecycle:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestPaymentBundleLifecycle'
+
+TestLeaderProgramVersions:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestLeaderProgramVersions'
+
+TestBonusScheduleInForce:
+	go test -v ../test/referral/repository -run 'TestReferralRepositoryTestSuite/TestBonusScheduleInForce'
@@ -0,0 +1,51 @@
+.PHONY: help service-test TestBonusSchedule_DefaultFallback TestBonusSchedule_InForce TestBonusSchedule_Immutable TestBonusSchedule_PastStart TestLeaderProgram_Rates TestLeaderProgram_Depth TestLeaderProgram_MaxDebt TestLeaderProgram_Deleted TestLeaderProgram_Invalid TestReferralProcess_CompletionFailed
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Referral Service Tests - Make Commands            ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make service-test                                  - Run all tests for TestReferralProgramTestSuite
+	@ECHO   ^> make TestBonusSchedule_DefaultFallback             - Run TestReferralProgramTestSuite/TestBonusSchedule_DefaultFallback
+	@ECHO   ^> make TestBonusSchedule_InForce                     - Run TestReferralProgramTestSuite/TestBonusSchedule_InForce
+	@ECHO   ^> make TestBonusSchedule_Immutable                   - Run TestReferralProgramTestSuite/TestBonusSchedule_Immutable
+	@ECHO   ^> make TestBonusSchedule_PastStart                   - Run TestReferralProgramTestSuite/TestBonusSchedule_PastStart
+	@ECHO   ^> make TestLeaderProgram_Rates                       - Run TestReferralProgramTestSuite/TestLeaderProgram_Rates
+	@ECHO   ^> make TestLeaderProgram_Depth                       - Run TestReferralProgramTestSuite/TestLeaderProgram_Depth
+	@ECHO   ^> make TestLeaderProgram_MaxDebt                     - Run TestReferralProgramTestSuite/TestLeaderProgram_MaxDebt
+	@ECHO   ^> make TestLeaderProgram_Deleted                     - Run TestReferralProgramTestSuite/TestLeaderProgram_Deleted
+	@ECHO   ^> make TestLeaderProgram_Invalid                     - Run TestReferralProgramTestSuite/TestLeaderProgram_Invalid
+	@ECHO   ^> make TestReferralProcess_CompletionFailed          - Run TestReferralProgramTestSuite/TestReferralProcess_CompletionFailed
+	@ECHO   ^> make help                                          - Display this help information
+
+service-test:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite'
+
+TestBonusSchedule_DefaultFallback:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestBonusSchedule_DefaultFallback'
+
+TestBonusSchedule_InForce:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestBonusSchedule_InForce'
+
+TestBonusSchedule_Immutable:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestBonusSchedule_Immutable'
+
+TestBonusSchedule_PastStart:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestBonusSchedule_PastStart'
+
+TestLeaderProgram_Rates:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestLeaderProgram_Rates'
+
+TestLeaderProgram_Depth:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestLeaderProgram_Depth'
+
+TestLeaderProgram_MaxDebt:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestLeaderProgram_MaxDebt'
+
+TestLeaderProgram_Deleted:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestLeaderProgram_Deleted'
+
+TestLeaderProgram_Invalid:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestLeaderProgram_Invalid'
+
+TestReferralProcess_CompletionFailed:
+	go test -v ../test/referral/service -run 'TestReferralProgramTestSuite/TestReferralProcess_CompletionFailed'
@@ -0,0 +1,27 @@
+.PHONY: help proof-test TestCheckProof TestCheckProof_ChainKey TestCheckProof_Rejected TestCheckProof_ForgedKeepsPayload
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    TON Proof Tests - Make Commands                   ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make proof-test                                    - Run all tests for TestTonProofTestSuite
+	@ECHO   ^> make TestCheckProof                                - Run TestTonProofTestSuite/TestCheckProof
+	@ECHO   ^> make TestCheckProof_ChainKey                       - Run TestTonProofTestSuite/TestCheckProof_ChainKey
+	@ECHO   ^> make TestCheckProof_Rejected                       - Run TestTonProofTestSuite/TestCheckProof_Rejected
+	@ECHO   ^> make TestCheckProof_ForgedKeepsPayload             - Run TestTonProofTestSuite/TestCheckProof_ForgedKeepsPayload
+	@ECHO   ^> make help                                          - Display this help information
+
+proof-test:
+	go test -v ../test/ton/service -run 'TestTonProofTestSuite'
+
+TestCheckProof:
+	go test -v ../test/ton/service -run 'TestTonProofTestSuite/TestCheckProof'
+
+TestCheckProof_ChainKey:
+	go test -v ../test/ton/service -run 'TestTonProofTestSuite/TestCheckProof_ChainKey'
+
+TestCheckProof_Rejected:
+	go test -v ../test/ton/service -run 'TestTonProofTestSuite/TestCheckProof_Rejected'
+
+TestCheckProof_ForgedKeepsPayload:
+	go test -v ../test/ton/service -run 'TestTonProofTestSuite/TestCheckProof_ForgedKeepsPayload'
@@ -0,0 +1,27 @@
+.PHONY: help distribution-test TestVerifyDistribution_Success TestVerifyDistribution_TotalMismatch TestVerifyDistribution_RecipientMismatch TestVerifyDistribution_Waiting
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Distribution Tests - Make Commands     ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make distribution-test                             - Run all tests for TestDistributionTestSuite
+	@ECHO   ^> make TestVerifyDistribution_Success                - Run TestDistributionTestSuite/TestVerifyDistribution_Success
+	@ECHO   ^> make TestVerifyDistribution_TotalMismatch          - Run TestDistributionTestSuite/TestVerifyDistribution_TotalMismatch
+	@ECHO   ^> make TestVerifyDistribution_RecipientMismatch      - Run TestDistributionTestSuite/TestVerifyDistribution_RecipientMismatch
+	@ECHO   ^> make TestVerifyDistribution_Waiting                - Run TestDistributionTestSuite/TestVerifyDistribution_Waiting
+	@ECHO   ^> make help                                          - Display this help information
+
+distribution-test:
+	go test -v ../test/validation/service -run 'TestDistributionTestSuite'
+
+TestVerifyDistribution_Success:
+	go test -v ../test/validation/service -run 'TestDistributionTestSuite/TestVerifyDistribution_Success'
+
+TestVerifyDistribution_TotalMismatch:
+	go test -v ../test/validation/service -run 'TestDistributionTestSuite/TestVerifyDistribution_TotalMismatch'
+
+TestVerifyDistribution_RecipientMismatch:
+	go test -v ../test/validation/service -run 'TestDistributionTestSuite/TestVerifyDistribution_RecipientMismatch'
+
+TestVerifyDistribution_Waiting:
+	go test -v ../test/validation/service -run 'TestDistributionTestSuite/TestVerifyDistribution_Waiting'
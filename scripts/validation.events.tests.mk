@@ -0,0 +1,27 @@
+.PHONY: help events-test TestSubscribeByTransactionID TestSubscribeByPaymentOrderID TestUnsubscribe TestSlowSubscriberDoesNotBlock
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Events Tests - Make Commands           ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make events-test                                   - Run all tests for TestStatusBrokerTestSuite
+	@ECHO   ^> make TestSubscribeByTransactionID                  - Run TestStatusBrokerTestSuite/TestSubscribeByTransactionID
+	@ECHO   ^> make TestSubscribeByPaymentOrderID                 - Run TestStatusBrokerTestSuite/TestSubscribeByPaymentOrderID
+	@ECHO   ^> make TestUnsubscribe                               - Run TestStatusBrokerTestSuite/TestUnsubscribe
+	@ECHO   ^> make TestSlowSubscriberDoesNotBlock                - Run TestStatusBrokerTestSuite/TestSlowSubscriberDoesNotBlock
+	@ECHO   ^> make help                                          - Display this help information
+
+events-test:
+	go test -v ../test/validation/events -run 'TestStatusBrokerTestSuite'
+
+TestSubscribeByTransactionID:
+	go test -v ../test/validation/events -run 'TestStatusBrokerTestSuite/TestSubscribeByTransactionID'
+
+TestSubscribeByPaymentOrderID:
+	go test -v ../test/validation/events -run 'TestStatusBrokerTestSuite/TestSubscribeByPaymentOrderID'
+
+TestUnsubscribe:
+	go test -v ../test/validation/events -run 'TestStatusBrokerTestSuite/TestUnsubscribe'
+
+TestSlowSubscriberDoesNotBlock:
+	go test -v ../test/validation/events -run 'TestStatusBrokerTestSuite/TestSlowSubscriberDoesNotBlock'
@@ -0,0 +1,19 @@
+.PHONY: help history-test TestSummarizeTrace_Success TestSummarizeTrace_FirstFailure
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation History Tests - Make Commands          ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make history-test                                  - Run all tests for TestHistoryTestSuite
+	@ECHO   ^> make TestSummarizeTrace_Success                    - Run TestHistoryTestSuite/TestSummarizeTrace_Success
+	@ECHO   ^> make TestSummarizeTrace_FirstFailure               - Run TestHistoryTestSuite/TestSummarizeTrace_FirstFailure
+	@ECHO   ^> make help                                          - Display this help information
+
+history-test:
+	go test -v ../test/validation/service -run 'TestHistoryTestSuite'
+
+TestSummarizeTrace_Success:
+	go test -v ../test/validation/service -run 'TestHistoryTestSuite/TestSummarizeTrace_Success'
+
+TestSummarizeTrace_FirstFailure:
+	go test -v ../test/validation/service -run 'TestHistoryTestSuite/TestSummarizeTrace_FirstFailure'
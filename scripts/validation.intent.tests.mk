@@ -0,0 +1,35 @@
+.PHONY: help intent-test TestCreatePaymentIntent TestCreatePaymentIntent_Invalid TestMatchPaymentIntents TestMatchPaymentIntents_MismatchFirst TestMatchPaymentIntents_Expired TestMatchPaymentIntents_Overdue
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Payment Intent Tests - Make Commands   ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make intent-test                                   - Run all tests for TestPaymentIntentTestSuite
+	@ECHO   ^> make TestCreatePaymentIntent                       - Run TestPaymentIntentTestSuite/TestCreatePaymentIntent
+	@ECHO   ^> make TestCreatePaymentIntent_Invalid               - Run TestPaymentIntentTestSuite/TestCreatePaymentIntent_Invalid
+	@ECHO   ^> make TestMatchPaymentIntents                       - Run TestPaymentIntentTestSuite/TestMatchPaymentIntents
+	@ECHO   ^> make TestMatchPaymentIntents_MismatchFirst         - Run TestPaymentIntentTestSuite/TestMatchPaymentIntents_MismatchFirst
+	@ECHO   ^> make TestMatchPaymentIntents_Expired               - Run TestPaymentIntentTestSuite/TestMatchPaymentIntents_Expired
+	@ECHO   ^> make TestMatchPaymentIntents_Overdue               - Run TestPaymentIntentTestSuite/TestMatchPaymentIntents_Overdue
+	@ECHO   ^> make help                                          - Display this help information
+
+intent-test:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite'
+
+TestCreatePaymentIntent:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestCreatePaymentIntent'
+
+TestCreatePaymentIntent_Invalid:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestCreatePaymentIntent_Invalid'
+
+TestMatchPaymentIntents:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestMatchPaymentIntents'
+
+TestMatchPaymentIntents_MismatchFirst:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestMatchPaymentIntents_MismatchFirst'
+
+TestMatchPaymentIntents_Expired:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestMatchPaymentIntents_Expired'
+
+TestMatchPaymentIntents_Overdue:
+	go test -v ../test/validation/service -run 'TestPaymentIntentTestSuite/TestMatchPaymentIntents_Overdue'
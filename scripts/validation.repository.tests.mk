@@ -9,8 +9,12 @@ help:
 	@ECHO   ^> make TestCreateTransactionObserver                 - Run TestCreateTransactionObserver test
 	@ECHO   ^> make TestGetTransactionObserver                    - Run TestGetTransactionObserver test
 	@ECHO   ^> make TestUpdateStatus                              - Run TestUpdateStatus test
+	@ECHO   ^> make TestUpdateStatusConflict                      - Run TestUpdateStatusConflict test
 	@ECHO   ^> make TestPrecheckoutTransaction                    - Run TestPrecheckoutTransaction test
 	@ECHO   ^> make TestDeleteTransactionObserver                 - Run TestDeleteTransactionObserver test
+	@ECHO   ^> make TestClaimTransactionObserver                  - Run TestClaimTransactionObserver test
+	@ECHO   ^> make TestGetTransactionObservers                   - Run TestGetTransactionObservers test
+	@ECHO   ^> make TestUniqueTxHash                              - Run TestUniqueTxHash test
 validation-test:
 	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite'
 
@@ -23,9 +27,20 @@ TestGetTransactionObserver:
 TestUpdateStatus:
 	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestUpdateStatus'
 
+TestUpdateStatusConflict:
+	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestUpdateStatusConflict'
+
 TestPrecheckoutTransaction:
 	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestPrecheckoutTransaction'
 
 TestDeleteTransactionObserver:
 	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestDeleteTransactionObserver'
 
+TestClaimTransactionObserver:
+	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestClaimTransactionObserver'
+
+TestGetTransactionObservers:
+	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestGetTransactionObservers'
+
+TestUniqueTxHash:
+	go test -v ../test/validation/repository -run 'TestValidationRepositoryTestSuite/TestUniqueTxHash'
@@ -0,0 +1,15 @@
+.PHONY: help scheduler-test TestBackoff
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Scheduler Tests - Make Commands        ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make scheduler-test                                - Run all tests for TestSchedulerTestSuite
+	@ECHO   ^> make TestBackoff                                   - Run TestSchedulerTestSuite/TestBackoff
+	@ECHO   ^> make help                                          - Display this help information
+
+scheduler-test:
+	go test -v ../test/validation/worker -run 'TestSchedulerTestSuite'
+
+TestBackoff:
+	go test -v ../test/validation/worker -run 'TestSchedulerTestSuite/TestBackoff'
@@ -0,0 +1,23 @@
+.PHONY: help trace-test TestFixtureProvider TestFixtureNotFound TestFallbackProvider
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Trace Tests - Make Commands            ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make trace-test                                    - Run all tests for TestTraceProviderTestSuite
+	@ECHO   ^> make TestFixtureProvider                           - Run TestTraceProviderTestSuite/TestFixtureProvider
+	@ECHO   ^> make TestFixtureNotFound                           - Run TestTraceProviderTestSuite/TestFixtureNotFound
+	@ECHO   ^> make TestFallbackProvider                          - Run TestTraceProviderTestSuite/TestFallbackProvider
+	@ECHO   ^> make help                                          - Display this help information
+
+trace-test:
+	go test -v ../test/validation/trace -run 'TestTraceProviderTestSuite'
+
+TestFixtureProvider:
+	go test -v ../test/validation/trace -run 'TestTraceProviderTestSuite/TestFixtureProvider'
+
+TestFixtureNotFound:
+	go test -v ../test/validation/trace -run 'TestTraceProviderTestSuite/TestFixtureNotFound'
+
+TestFallbackProvider:
+	go test -v ../test/validation/trace -run 'TestTraceProviderTestSuite/TestFallbackProvider'
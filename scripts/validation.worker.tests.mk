@@ -0,0 +1,39 @@
+.PHONY: help worker-test TestRunnerTransaction TestSubWorkerTransaction TestWorkerTransaction TestWorkerTransaction_Report TestWorkerTransaction_SettlementPending TestWorkerTransaction_TraceFetchError TestEvaluateTrace
+
+help:
+	@ECHO +------------------------------------------------------+
+	@ECHO ^|    Validation Worker Tests - Make Commands           ^|
+	@ECHO +------------------------------------------------------+
+	@ECHO   ^> make worker-test                                   - Run all tests for TestWorkerTestSuite
+	@ECHO   ^> make TestRunnerTransaction                         - Run TestWorkerTestSuite/TestRunnerTransaction
+	@ECHO   ^> make TestSubWorkerTransaction                      - Run TestWorkerTestSuite/TestSubWorkerTransaction
+	@ECHO   ^> make TestWorkerTransaction                         - Run TestWorkerTestSuite/TestWorkerTransaction
+	@ECHO   ^> make TestWorkerTransaction_Report                  - Run TestWorkerTestSuite/TestWorkerTransaction_Report
+	@ECHO   ^> make TestWorkerTransaction_SettlementPending       - Run TestWorkerTestSuite/TestWorkerTransaction_SettlementPending
+	@ECHO   ^> make TestWorkerTransaction_TraceFetchError         - Run TestWorkerTestSuite/TestWorkerTransaction_TraceFetchError
+	@ECHO   ^> make TestEvaluateTrace                             - Run TestWorkerTestSuite/TestEvaluateTrace
+	@ECHO   ^> make help                                          - Display this help information
+
+worker-test:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite'
+
+TestRunnerTransaction:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestRunnerTransaction'
+
+TestSubWorkerTransaction:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestSubWorkerTransaction'
+
+TestWorkerTransaction:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestWorkerTransaction'
+
+TestWorkerTransaction_Report:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestWorkerTransaction_Report'
+
+TestWorkerTransaction_SettlementPending:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestWorkerTransaction_SettlementPending'
+
+TestWorkerTransaction_TraceFetchError:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestWorkerTransaction_TraceFetchError'
+
+TestEvaluateTrace:
+	go test -v ../test/validation/service -run 'TestWorkerTestSuite/TestEvaluateTrace'
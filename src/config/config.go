@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -22,6 +23,44 @@ type Config struct {
 
 	PrivateKey string `mapstructure:"PRIVATE_KEY"`
 	PublicKey  string `mapstructure:"PUBLIC_KEY"`
+	// JwtKeyID is the kid of PRIVATE_KEY, its RFC 7638 thumbprint when empty.
+	JwtKeyID string `mapstructure:"JWT_KEY_ID"`
+	// JwtVerifyKeys are retired public keys as kid:base64, tokens they signed are
+	// accepted until they expire.
+	JwtVerifyKeys []string `mapstructure:"JWT_VERIFY_KEYS"`
+
+	ReferralDirectoryUrl     string        `mapstructure:"REFERRAL_DIRECTORY_URL"`
+	ReferralDirectoryTimeout time.Duration `mapstructure:"REFERRAL_DIRECTORY_TIMEOUT"`
+	ReferralDirectoryRetries int           `mapstructure:"REFERRAL_DIRECTORY_RETRIES"`
+	ReferralDirectoryTTL     time.Duration `mapstructure:"REFERRAL_DIRECTORY_CACHE_TTL"`
+	// ReferralDirectoryFixture replaces the HTTP directory with a JSON fixture when set.
+	ReferralDirectoryFixture string `mapstructure:"REFERRAL_DIRECTORY_FIXTURE"`
+
+	PayoutDispatchInterval time.Duration `mapstructure:"PAYOUT_DISPATCH_INTERVAL"`
+	PayoutBatching         bool          `mapstructure:"PAYOUT_BATCHING"`
+	PayoutBatchInterval    time.Duration `mapstructure:"PAYOUT_BATCH_INTERVAL"`
+
+	// PaymentOrderTTL expires payment orders unpaid for longer, zero keeps them open.
+	PaymentOrderTTL time.Duration `mapstructure:"PAYMENT_ORDER_TTL"`
+
+	ValidationPollInterval time.Duration `mapstructure:"VALIDATION_POLL_INTERVAL"`
+	ValidationMaxBackoff   time.Duration `mapstructure:"VALIDATION_MAX_BACKOFF"`
+	// ValidationDeadline is how long after creation a waiting transaction is finalized.
+	ValidationDeadline time.Duration `mapstructure:"VALIDATION_DEADLINE"`
+	// ValidationTraceProvider is tonapi, liteserver or empty to try tonapi first
+	// and fall back to the liteserver.
+	ValidationTraceProvider string `mapstructure:"VALIDATION_TRACE_PROVIDER"`
+	// ValidationTraceFixtures replaces the trace providers with recorded JSON traces when set.
+	ValidationTraceFixtures string `mapstructure:"VALIDATION_TRACE_FIXTURES"`
+
+	// PaymentIntentTTL is how long a payment intent stays open unless the client asks otherwise.
+	PaymentIntentTTL          time.Duration `mapstructure:"PAYMENT_INTENT_TTL"`
+	PaymentIntentPollInterval time.Duration `mapstructure:"PAYMENT_INTENT_POLL_INTERVAL"`
+
+	// TonProofDomains lists the domains a ton_proof may be signed for.
+	TonProofDomains []string `mapstructure:"TON_PROOF_DOMAINS"`
+	// TonProofTTL bounds the lifetime of a proof payload and the age of a proof.
+	TonProofTTL time.Duration `mapstructure:"TON_PROOF_TTL"`
 }
 
 func (c *Config) Address() string {
@@ -29,7 +29,10 @@ var (
 	once     sync.Once
 )
 
-func InitApp() *Core {
+// InitApp wires the application, it returns an error when a module can not be
+// built from the config or its database indexes can not be ensured.
+func InitApp() (*Core, error) {
+	var err error
 	instance = &Core{}
 	once.Do(func() {
 		instance.init_logger() // Initialize logger first
@@ -40,10 +43,18 @@ func InitApp() *Core {
 		instance.init_ton_api()
 
 		instance.init_http_server()
-		instance.init_modules()
+		if err = instance.init_modules(); err != nil {
+			instance.logger.Errorf("Failed to initialize modules: %v", err)
+			return
+		}
+		if err = instance.init_indexes(); err != nil {
+			instance.logger.Errorf("Failed to ensure database indexes: %v", err)
+			return
+		}
 		instance.init_routes()
+		instance.init_workers()
 	})
-	return instance
+	return instance, err
 }
 
 func (app *Core) Start() {
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -108,10 +110,54 @@ func (app *Core) init_ton_api() {
 
 func (app *Core) init_routes() {
 	app.http_server.Get("/web3/swagger/*", swagger.HandlerDefault)
+	app.modules.jwt.RegisterWellKnownRoutes(app.http_server)
 	api := app.http_server.Group("/api")
 	app.modules.test.RegisterRoutes(api)
 	app.modules.referral.RegisterRoutes(api)
 	app.modules.validation.RegisterRoutes(api)
 	app.modules.ton.RegisterRoutes(api)
 	app.modules.conference.InitRoutes(api)
+	app.modules.jwt.RegisterRoutes(api)
+}
+
+// init_indexes ensures the indexes of every module, a failing module does not
+// keep the others from being ensured. Startup stops on any failure, the unique
+// and TTL indexes back invariants the modules rely on.
+func (app *Core) init_indexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	modules := []struct {
+		name   string
+		ensure func(ctx context.Context) error
+	}{
+		// duplicates left from before the unique index have to be resolved by hand
+		{name: "validation", ensure: app.modules.validation.Repository().EnsureIndexes},
+		{name: "jwt", ensure: app.modules.jwt.Repository().EnsureIndexes},
+		{name: "ton", ensure: app.modules.ton.Repository().EnsureIndexes},
+		{name: "referral", ensure: app.modules.referral.Repository().EnsureIndexes},
+	}
+
+	var errs []error
+	for _, module := range modules {
+		if err := module.ensure(ctx); err != nil {
+			app.logger.Errorf("Failed to create %s indexes: %v", module.name, err)
+			errs = append(errs, fmt.Errorf("%s indexes: %w", module.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	app.logger.Info("🗂️ Database indexes ensured")
+	return nil
+}
+
+func (app *Core) init_workers() {
+	go app.modules.referral.Dispatcher().Run(context.Background())
+	go app.modules.referral.Expirer().Run(context.Background())
+	go app.modules.validation.Scheduler().Run(context.Background())
+	go app.modules.validation.IntentWatcher().Run(context.Background())
+
+	app.logger.Info("⚙️ Background workers started")
 }
@@ -2,6 +2,7 @@ package core
 
 import (
 	conference_module "github.com/root9464/Go_GamlerDefi/src/modules/conference"
+	jwt_module "github.com/root9464/Go_GamlerDefi/src/modules/jwt"
 	referral_module "github.com/root9464/Go_GamlerDefi/src/modules/referral"
 	test_module "github.com/root9464/Go_GamlerDefi/src/modules/test"
 	ton_module "github.com/root9464/Go_GamlerDefi/src/modules/ton"
@@ -14,16 +15,24 @@ type Modules struct {
 	validation *validation_module.ValidationModule
 	ton        *ton_module.TonModule
 	conference *conference_module.ConferenceModule
-	// jwt        *jwt_module.JwtModule
+	jwt        *jwt_module.JwtModule
 }
 
-func (m *Core) init_modules() {
+func (m *Core) init_modules() error {
+	jwt, err := jwt_module.NewJwtModule(m.config, m.logger, m.validator, m.database)
+	if err != nil {
+		return err
+	}
+	auth := jwt.Middleware()
+	referral := referral_module.NewReferralModule(m.config, m.logger, m.validator, m.database, m.ton_client, m.ton_api, auth)
+
 	m.modules = &Modules{
 		test:       test_module.NewTestModule(m.logger),
-		referral:   referral_module.NewReferralModule(m.config, m.logger, m.validator, m.database, m.ton_client, m.ton_api),
-		validation: validation_module.NewValidationModule(m.config, m.logger, m.validator, m.database, m.ton_api),
+		referral:   referral,
+		validation: validation_module.NewValidationModule(m.config, m.logger, m.validator, m.database, m.ton_client, m.ton_api, referral.Service(), auth),
 		conference: conference_module.NewConferenceModule(m.logger),
-		ton:        ton_module.NewTonModule(m.config, m.logger),
-		// jwt:        jwt_module.NewJwtModule(m.logger, m.validator, m.config.PrivateKey, m.config.PublicKey),
+		ton:        ton_module.NewTonModule(m.config, m.logger, m.validator, m.database, m.ton_client, jwt.Service(), auth),
+		jwt:        jwt,
 	}
+	return nil
 }
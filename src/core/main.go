@@ -1,6 +1,8 @@
 package main
 
 import (
+	"log"
+
 	_ "github.com/root9464/Go_GamlerDefi/docs"
 	core "github.com/root9464/Go_GamlerDefi/src/core/head"
 )
@@ -11,6 +13,9 @@ import (
 // @host			localhost:6069
 // @BasePath		/
 func main() {
-	app := core.InitApp()
+	app, err := core.InitApp()
+	if err != nil {
+		log.Fatalf("Failed to start application: %v", err)
+	}
 	app.Start()
 }
@@ -0,0 +1,144 @@
+package jwt_controller
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+)
+
+type IJwtController interface {
+	IssueTokens(c *fiber.Ctx) error
+	RefreshTokens(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+	GetJwks(c *fiber.Ctx) error
+}
+
+type JwtController struct {
+	logger    *logger.Logger
+	validator *validator.Validate
+
+	jwt_service jwt_service.IJwtService
+}
+
+func NewJwtController(logger *logger.Logger, validator *validator.Validate, jwt_service jwt_service.IJwtService) IJwtController {
+	return &JwtController{
+		logger:      logger,
+		validator:   validator,
+		jwt_service: jwt_service,
+	}
+}
+
+// @Summary Issue tokens
+// @Description Issue an access and refresh token pair for the user, called by the platform backend with an admin token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param user body jwt_dto.UserData true "User the tokens are issued for"
+// @Success 200 {object} jwt_dto.TokenPairResponse "Success response"
+// @Failure 400 {object} errors.MapError "Invalid request body"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Admin access required"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/auth/token [post]
+func (c *JwtController) IssueTokens(ctx *fiber.Ctx) error {
+	userData := new(jwt_dto.UserData)
+	if err := ctx.BodyParser(userData); err != nil {
+		c.logger.Errorf("failed to parse user data: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.validator.Struct(userData); err != nil {
+		c.logger.Errorf("failed to validate user data: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	tokens, err := c.jwt_service.IssueTokens(ctx.Context(), *userData)
+	if err != nil {
+		c.logger.Errorf("error issuing tokens: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(tokens)
+}
+
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new pair. The refresh token is single-use, presenting it again revokes the session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body jwt_dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} jwt_dto.TokenPairResponse "Success response"
+// @Failure 400 {object} errors.MapError "Invalid request body"
+// @Failure 401 {object} errors.MapError "Invalid, expired or reused refresh token"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/auth/refresh [post]
+func (c *JwtController) RefreshTokens(ctx *fiber.Ctx) error {
+	request := new(jwt_dto.RefreshTokenRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("failed to parse refresh token request: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("failed to validate refresh token request: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	tokens, err := c.jwt_service.RefreshTokens(ctx.Context(), request.RefreshToken)
+	if err != nil {
+		c.logger.Errorf("error refreshing tokens: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(tokens)
+}
+
+// @Summary Logout
+// @Description Revoke the refresh token and every token rotated from the same login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body jwt_dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} fiber.Map "Success response"
+// @Failure 400 {object} errors.MapError "Invalid request body"
+// @Failure 401 {object} errors.MapError "Invalid or expired refresh token"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/auth/logout [post]
+func (c *JwtController) Logout(ctx *fiber.Ctx) error {
+	request := new(jwt_dto.RefreshTokenRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("failed to parse logout request: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("failed to validate logout request: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.jwt_service.RevokeTokens(ctx.Context(), request.RefreshToken); err != nil {
+		c.logger.Errorf("error revoking tokens: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
+// @Summary Get JWKS
+// @Description Public keys our tokens are signed with, the kid header of a token names its key
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwt_dto.JWKSet "Success response"
+// @Router /.well-known/jwks.json [get]
+func (c *JwtController) GetJwks(ctx *fiber.Ctx) error {
+	// verifiers cache the set, a rotated key is announced while the retired
+	// one still verifies tokens
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(200).JSON(c.jwt_service.PublicKeys())
+}
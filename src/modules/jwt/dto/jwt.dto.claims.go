@@ -0,0 +1,123 @@
+package jwt_dto
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleHost  Role = "host"
+	RoleAdmin Role = "admin"
+)
+
+type Scope string
+
+const (
+	ScopeReferralPay    Scope = "referral:pay"
+	ScopeOrdersRead     Scope = "orders:read"
+	ScopeOrdersDelete   Scope = "orders:delete"
+	ScopeConferenceHost Scope = "conference:host"
+)
+
+// TokenType keeps the two tokens of a pair apart, a refresh token is not
+// accepted as a bearer token and an access token is not exchanged.
+type TokenType string
+
+const (
+	TokenAccess  TokenType = "access"
+	TokenRefresh TokenType = "refresh"
+)
+
+// RoleScopes are the scopes granted with every role, tokens may carry more.
+var RoleScopes = map[Role][]Scope{
+	RoleUser:  {ScopeReferralPay, ScopeOrdersRead, ScopeOrdersDelete},
+	RoleHost:  {ScopeConferenceHost},
+	RoleAdmin: {ScopeReferralPay, ScopeOrdersRead, ScopeOrdersDelete, ScopeConferenceHost},
+}
+
+// ScopesOf merges the scopes of the roles with the extra ones, keeping the
+// first occurrence of each.
+func ScopesOf(roles []Role, extra []Scope) []Scope {
+	scopes := []Scope{}
+	for _, role := range roles {
+		for _, scope := range RoleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	for _, scope := range extra {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// UserClaims are the claims of the access and refresh tokens. The subject is
+// the numeric user ID, so the registered claims of jwt are not reused.
+type UserClaims struct {
+	Iss    string    `json:"iss"`
+	Sub    int64     `json:"sub"`
+	Iat    int64     `json:"iat"`
+	Exp    int64     `json:"exp"`
+	Hash   string    `json:"user_hash"`
+	Typ    TokenType `json:"typ"`
+	Wallet string    `json:"wallet,omitempty"`
+	Roles  []Role    `json:"roles,omitempty"`
+	Scopes []Scope   `json:"scopes,omitempty"`
+	// Role is the single role of the tokens issued before roles and scopes,
+	// admin tokens still carry it.
+	Role Role `json:"role,omitempty"`
+}
+
+var _ jwt.Claims = (*UserClaims)(nil)
+
+// Normalize fills the roles and scopes of tokens issued before they existed:
+// the legacy role or the user role, and the scopes of those roles.
+func (c *UserClaims) Normalize() {
+	if len(c.Roles) == 0 {
+		if c.Role != "" {
+			c.Roles = []Role{c.Role}
+		} else {
+			c.Roles = []Role{RoleUser}
+		}
+	}
+	c.Scopes = ScopesOf(c.Roles, c.Scopes)
+}
+
+func (c UserClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return numericDate(c.Exp), nil
+}
+
+func (c UserClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return numericDate(c.Iat), nil
+}
+
+func (c UserClaims) GetNotBefore() (*jwt.NumericDate, error) {
+	return nil, nil
+}
+
+func (c UserClaims) GetIssuer() (string, error) {
+	return c.Iss, nil
+}
+
+func (c UserClaims) GetSubject() (string, error) {
+	return "", nil
+}
+
+func (c UserClaims) GetAudience() (jwt.ClaimStrings, error) {
+	return nil, nil
+}
+
+func numericDate(unix int64) *jwt.NumericDate {
+	if unix == 0 {
+		return nil
+	}
+	return jwt.NewNumericDate(time.Unix(unix, 0))
+}
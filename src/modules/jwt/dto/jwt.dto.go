@@ -1,7 +1,15 @@
 package jwt_dto
 
+import "slices"
+
 type UserData struct {
 	ID int64 `json:"id" validate:"required"`
+	// Wallet is set only once the user proved control of it with ton_proof.
+	Wallet string `json:"-"`
+	// Roles of the user, the user role when empty.
+	Roles []Role `json:"roles,omitempty" validate:"dive,oneof=user host admin"`
+	// Scopes granted on top of the scopes of the roles.
+	Scopes []Scope `json:"scopes,omitempty" validate:"dive,required"`
 }
 
 type UserJwtPayload struct {
@@ -10,4 +18,54 @@ type UserJwtPayload struct {
 	Iat  int64  `json:"iat" validate:"required"`
 	Exp  int64  `json:"exp" validate:"required"`
 	Hash string `json:"user_hash" validate:"required"`
+	// Wallet is the address verified with ton_proof, empty when none was.
+	Wallet string  `json:"wallet,omitempty"`
+	Roles  []Role  `json:"roles,omitempty"`
+	Scopes []Scope `json:"scopes,omitempty"`
+}
+
+func (p *UserJwtPayload) HasRole(role Role) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasScopes reports whether the token carries every one of the scopes.
+func (p *UserJwtPayload) HasScopes(scopes ...Scope) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+type TokenPairResponse struct {
+	// Short-lived token sent as `Authorization: Bearer <token>`
+	// example: "eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."
+	AccessToken string `json:"access_token"`
+
+	// Single-use token exchanged for a new pair at /api/auth/refresh
+	// example: "eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenRequest struct {
+	// Refresh token of the last issued pair
+	// required: true
+	// example: "eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// JWK is the public part of a signing key in the RFC 7517 format.
+type JWK struct {
+	Kty string `json:"kty" example:"EC"`
+	Crv string `json:"crv" example:"P-256"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"ES256"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
 }
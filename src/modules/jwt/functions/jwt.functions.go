@@ -1,7 +1,6 @@
 package jwt_functions
 
 import (
-	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -10,6 +9,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
 )
 
 const (
@@ -39,30 +39,31 @@ func (f *JwtFuncs) GenerateKeyPair(userData jwt_dto.UserData) (*string, *string,
 	hash.Write([]byte(userRaw))
 	refinedHash := hex.EncodeToString(hash.Sum(nil))
 
-	accessClaims := jwt.MapClaims{
-		"iss":       Issuer,
-		"sub":       userData.ID,
-		"iat":       Now,
-		"exp":       time.Now().Add(AccessTokenExpiry).Unix(),
-		"user_hash": refinedHash,
+	accessClaims := &jwt_dto.UserClaims{
+		Iss:    Issuer,
+		Sub:    userData.ID,
+		Iat:    Now,
+		Exp:    time.Now().Add(AccessTokenExpiry).Unix(),
+		Hash:   refinedHash,
+		Typ:    jwt_dto.TokenAccess,
+		Wallet: userData.Wallet,
+		Roles:  userData.Roles,
+		Scopes: userData.Scopes,
 	}
+	accessClaims.Normalize()
 
 	f.logger.Infof("access claims: %+v", accessClaims)
 
-	refreshClaims := jwt.MapClaims{
-		"iss":       Issuer,
-		"sub":       userData.ID,
-		"iat":       Now,
-		"exp":       time.Now().Add(RefreshTokenExpiry).Unix(),
-		"user_hash": refinedHash,
-	}
+	refreshClaims := *accessClaims
+	refreshClaims.Exp = time.Now().Add(RefreshTokenExpiry).Unix()
+	refreshClaims.Typ = jwt_dto.TokenRefresh
 
 	f.logger.Infof("refresh claims: %+v", refreshClaims)
 
-	if f.privateKey == nil {
+	if f.keys == nil {
 		return nil, nil, &fiber.Error{
 			Code:    500,
-			Message: "privateKey is not initialized",
+			Message: "signing keys are not initialized",
 		}
 	}
 	if f.helpers == nil {
@@ -72,7 +73,7 @@ func (f *JwtFuncs) GenerateKeyPair(userData jwt_dto.UserData) (*string, *string,
 		}
 	}
 
-	accessToken, err := f.helpers.CreateJwt(accessClaims, f.privateKey)
+	accessToken, err := f.helpers.SignJwt(accessClaims, f.keys.Active())
 	if err != nil {
 		f.logger.Warnf("create access token error: %s", err.Error())
 		return nil, nil, &fiber.Error{
@@ -81,7 +82,7 @@ func (f *JwtFuncs) GenerateKeyPair(userData jwt_dto.UserData) (*string, *string,
 		}
 	}
 
-	refreshToken, err := f.helpers.CreateJwt(refreshClaims, f.privateKey)
+	refreshToken, err := f.helpers.SignJwt(&refreshClaims, f.keys.Active())
 	if err != nil {
 		f.logger.Warnf("create refresh token error: %s", err.Error())
 		return nil, nil, &fiber.Error{
@@ -93,13 +94,10 @@ func (f *JwtFuncs) GenerateKeyPair(userData jwt_dto.UserData) (*string, *string,
 	return accessToken, refreshToken, nil
 }
 
-func (f *JwtFuncs) RefreshAccessToken(refreshToken string, publicKey *ecdsa.PublicKey, privateKey *ecdsa.PrivateKey) (*string, error) {
-	parsedToken, err := jwt.ParseWithClaims(refreshToken, jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return publicKey, nil
-	})
+// RefreshAccessToken verifies the refresh token with the key named by its kid
+// and signs the new access token with the active key.
+func (f *JwtFuncs) RefreshAccessToken(refreshToken string, keys *jwt_keys.KeyRing) (*string, error) {
+	parsedToken, err := jwt.ParseWithClaims(refreshToken, jwt.MapClaims{}, keys.Keyfunc)
 	if err != nil {
 		f.logger.Warnf("invalid refresh token: %s", err.Error())
 		return nil, fmt.Errorf("invalid refresh token: %s", err.Error())
@@ -135,15 +133,28 @@ func (f *JwtFuncs) RefreshAccessToken(refreshToken string, publicKey *ecdsa.Publ
 		return nil, fmt.Errorf("refresh token missing user hash")
 	}
 
-	accessClaims := jwt.MapClaims{
-		"iss":       Issuer,
-		"sub":       int64(userID),
-		"iat":       Now,
-		"exp":       time.Now().Add(AccessTokenExpiry).Unix(),
-		"user_hash": userHash,
+	if typ, _ := claims["typ"].(string); jwt_dto.TokenType(typ) != jwt_dto.TokenRefresh {
+		f.logger.Warn("token is not a refresh token")
+		return nil, fmt.Errorf("token is not a refresh token")
+	}
+
+	wallet, _ := claims["wallet"].(string)
+	role, _ := claims["role"].(string)
+	accessClaims := &jwt_dto.UserClaims{
+		Iss:    Issuer,
+		Sub:    int64(userID),
+		Iat:    Now,
+		Exp:    time.Now().Add(AccessTokenExpiry).Unix(),
+		Hash:   userHash,
+		Typ:    jwt_dto.TokenAccess,
+		Wallet: wallet,
+		Roles:  claimList[jwt_dto.Role](claims["roles"]),
+		Scopes: claimList[jwt_dto.Scope](claims["scopes"]),
+		Role:   jwt_dto.Role(role),
 	}
+	accessClaims.Normalize()
 
-	accessToken, err := f.helpers.CreateJwt(accessClaims, privateKey)
+	accessToken, err := f.helpers.SignJwt(accessClaims, keys.Active())
 	if err != nil {
 		f.logger.Warnf("create access token error: %s", err.Error())
 		return nil, &fiber.Error{
@@ -156,27 +167,42 @@ func (f *JwtFuncs) RefreshAccessToken(refreshToken string, publicKey *ecdsa.Publ
 }
 
 func (f *JwtFuncs) GenerateAdminToken(userID jwt_dto.UserData) (string, error) {
-	if f.privateKey == nil {
-		return "", fmt.Errorf("private key is not initialized")
+	if f.keys == nil {
+		return "", jwt_keys.ErrNoSigningKey
 	}
 
 	hash := sha256.New()
 	hash.Write([]byte(fmt.Sprintf("%d", userID.ID)))
 	refinedHash := hex.EncodeToString(hash.Sum(nil))
 
-	adminClaims := jwt.MapClaims{
-		"iss":       Issuer,
-		"sub":       userID.ID,
-		"iat":       time.Now().Unix(),
-		"exp":       time.Now().Add(100 * 365 * 24 * time.Hour).Unix(),
-		"role":      "admin",
-		"user_hash": refinedHash,
+	adminClaims := &jwt_dto.UserClaims{
+		Iss:   Issuer,
+		Sub:   userID.ID,
+		Iat:   time.Now().Unix(),
+		Exp:   time.Now().Add(100 * 365 * 24 * time.Hour).Unix(),
+		Hash:  refinedHash,
+		Typ:   jwt_dto.TokenAccess,
+		Roles: []jwt_dto.Role{jwt_dto.RoleAdmin},
+		Role:  jwt_dto.RoleAdmin,
 	}
+	adminClaims.Normalize()
 
-	token, err := f.helpers.CreateJwt(adminClaims, f.privateKey)
+	token, err := f.helpers.SignJwt(adminClaims, f.keys.Active())
 	if err != nil {
 		return "", fmt.Errorf("failed to create admin token: %w", err)
 	}
 
 	return *token, nil
 }
+
+// claimList reads a list claim decoded into a map, values of other types are skipped.
+func claimList[T ~string](value interface{}) []T {
+	items, _ := value.([]interface{})
+	list := make([]T, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			list = append(list, T(text))
+		}
+	}
+	return list
+}
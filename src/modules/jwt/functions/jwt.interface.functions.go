@@ -1,11 +1,10 @@
 package jwt_functions
 
 import (
-	"crypto/ecdsa"
-
 	"github.com/go-playground/validator/v10"
 	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
 	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 )
 
@@ -13,7 +12,7 @@ var _ IJwtFuncs = (*JwtFuncs)(nil)
 
 type IJwtFuncs interface {
 	GenerateKeyPair(userData jwt_dto.UserData) (*string, *string, error)
-	RefreshAccessToken(refreshToken string, publicKey *ecdsa.PublicKey, privateKey *ecdsa.PrivateKey) (*string, error)
+	RefreshAccessToken(refreshToken string, keys *jwt_keys.KeyRing) (*string, error)
 	GenerateAdminToken(userID jwt_dto.UserData) (string, error)
 }
 
@@ -21,18 +20,16 @@ type JwtFuncs struct {
 	logger    *logger.Logger
 	validator *validator.Validate
 
-	privateKey *ecdsa.PrivateKey
-	publicKey  *ecdsa.PublicKey
+	keys *jwt_keys.KeyRing
 
 	helpers jwt_helpers.IJwtHelper
 }
 
-func NewJwtFuncs(logger *logger.Logger, validator *validator.Validate, privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, helpers jwt_helpers.IJwtHelper) IJwtFuncs {
+func NewJwtFuncs(logger *logger.Logger, validator *validator.Validate, keys *jwt_keys.KeyRing, helpers jwt_helpers.IJwtHelper) IJwtFuncs {
 	return &JwtFuncs{
-		logger:     logger,
-		validator:  validator,
-		privateKey: privateKey,
-		publicKey:  publicKey,
-		helpers:    helpers,
+		logger:    logger,
+		validator: validator,
+		keys:      keys,
+		helpers:   helpers,
 	}
 }
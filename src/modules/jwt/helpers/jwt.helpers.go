@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
 )
 
 func (h *jwtHelper) CreateJwt(claims jwt.Claims, key *ecdsa.PrivateKey) (*string, error) {
@@ -18,6 +19,22 @@ func (h *jwtHelper) CreateJwt(claims jwt.Claims, key *ecdsa.PrivateKey) (*string
 	return &signedToken, nil
 }
 
+// SignJwt signs the claims with a key of the ring and names it in the kid
+// header, so the token is verified with the same key after a rotation.
+func (h *jwtHelper) SignJwt(claims jwt.Claims, key *jwt_keys.Key) (*string, error) {
+	if key == nil || key.PrivateKey == nil {
+		return nil, jwt_keys.ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = key.ID
+	signedToken, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &signedToken, nil
+}
+
 func (h *jwtHelper) VerifyJwt(tokenString string, key *ecdsa.PublicKey) (*jwt.Token, error) {
 	h.logger.Info("Verifying JWT token...")
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -61,27 +78,47 @@ func (h *jwtHelper) CheckTokenExpiration(tokenString string, publicKey *ecdsa.Pu
 }
 
 func (h *jwtHelper) ParseJwt(tokenString string, key *ecdsa.PublicKey) (*jwt_dto.UserJwtPayload, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+	return h.parseJwt(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return key, nil
 	})
+}
+
+// ParseJwtWithKeys parses a token verified with the key of the ring named by
+// its kid header.
+func (h *jwtHelper) ParseJwtWithKeys(tokenString string, keys *jwt_keys.KeyRing) (*jwt_dto.UserJwtPayload, error) {
+	return h.parseJwt(tokenString, keys.Keyfunc)
+}
+
+func (h *jwtHelper) parseJwt(tokenString string, keyfunc jwt.Keyfunc) (*jwt_dto.UserJwtPayload, error) {
+	claims := new(jwt_dto.UserClaims)
+	_, err := jwt.ParseWithClaims(tokenString, claims, keyfunc)
 
 	h.logger.Info("Parsing JWT token...")
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		return &jwt_dto.UserJwtPayload{
-			Iss:  claims["iss"].(string),
-			Sub:  int64(claims["sub"].(float64)),
-			Iat:  int64(claims["iat"].(float64)),
-			Exp:  int64(claims["exp"].(float64)),
-			Hash: claims["user_hash"].(string),
-		}, nil
+	if claims.Iss == "" || claims.Sub == 0 || claims.Iat == 0 || claims.Exp == 0 || claims.Hash == "" {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	// refresh tokens are only exchanged at /auth/refresh, logging out has to
+	// end the session they belong to
+	if claims.Typ != jwt_dto.TokenAccess {
+		return nil, fmt.Errorf("not an access token")
 	}
+	claims.Normalize()
 
-	return nil, fmt.Errorf("invalid token claims")
+	return &jwt_dto.UserJwtPayload{
+		Iss:    claims.Iss,
+		Sub:    claims.Sub,
+		Iat:    claims.Iat,
+		Exp:    claims.Exp,
+		Hash:   claims.Hash,
+		Wallet: claims.Wallet,
+		Roles:  claims.Roles,
+		Scopes: claims.Scopes,
+	}, nil
 }
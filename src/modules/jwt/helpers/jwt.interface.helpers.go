@@ -6,6 +6,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 )
 
@@ -13,9 +14,11 @@ var _ IJwtHelper = (*jwtHelper)(nil)
 
 type IJwtHelper interface {
 	CreateJwt(claims jwt.Claims, key *ecdsa.PrivateKey) (*string, error)
+	SignJwt(claims jwt.Claims, key *jwt_keys.Key) (*string, error)
 	VerifyJwt(tokenString string, key *ecdsa.PublicKey) (*jwt.Token, error)
 	CheckTokenExpiration(token string, publicKey *ecdsa.PublicKey) (bool, error)
 	ParseJwt(tokenString string, key *ecdsa.PublicKey) (*jwt_dto.UserJwtPayload, error)
+	ParseJwtWithKeys(tokenString string, keys *jwt_keys.KeyRing) (*jwt_dto.UserJwtPayload, error)
 }
 
 type jwtHelper struct {
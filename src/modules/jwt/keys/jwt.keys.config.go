@@ -0,0 +1,49 @@
+package jwt_keys
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/root9464/Go_GamlerDefi/src/config"
+	jwt_utils "github.com/root9464/Go_GamlerDefi/src/modules/jwt/utils"
+)
+
+// FromConfig loads the ring signing with PRIVATE_KEY and also verifying with
+// the retired JWT_VERIFY_KEYS.
+func FromConfig(config *config.Config) (*KeyRing, error) {
+	return Load(config.PrivateKey, config.PublicKey, config.JwtKeyID, config.JwtVerifyKeys)
+}
+
+// Load decodes the base64 keys of the configuration. The verify keys are
+// base64 PKIX public keys optionally prefixed with their kid as kid:key, keys
+// without a kid are named by their thumbprint.
+func Load(privateKey, publicKey, keyID string, verifyKeys []string) (*KeyRing, error) {
+	private, public, err := jwt_utils.Base64ToKeys(privateKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !private.PublicKey.Equal(public) {
+		return nil, fmt.Errorf("public key does not match the private key")
+	}
+
+	retired := make([]Key, 0, len(verifyKeys))
+	for _, entry := range verifyKeys {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found {
+			id, encoded = "", entry
+		}
+
+		key, err := jwt_utils.Base64ToPublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing verify key %q: %w", id, err)
+		}
+		retired = append(retired, Key{ID: id, PublicKey: key})
+	}
+
+	return NewKeyRing(Key{ID: keyID, PrivateKey: private, PublicKey: public}, retired...)
+}
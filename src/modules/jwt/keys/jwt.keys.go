@@ -0,0 +1,136 @@
+package jwt_keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+)
+
+var (
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrNoSigningKey = errors.New("signing key is not initialized")
+)
+
+// Key is a P-256 key of the ring. Retired keys have no private key, they are
+// only kept to verify the tokens they signed until those expire.
+type Key struct {
+	ID         string
+	PrivateKey *ecdsa.PrivateKey
+	PublicKey  *ecdsa.PublicKey
+}
+
+// KeyRing signs with its active key and verifies with the key named by the
+// kid header, so the signing key can be rotated without ending the sessions.
+type KeyRing struct {
+	active *Key
+	keys   map[string]*Key
+	// order keeps the active key first in the JWKS.
+	order []string
+}
+
+// NewKeyRing builds a ring signing with active and verifying with active and
+// the retired keys.
+func NewKeyRing(active Key, retired ...Key) (*KeyRing, error) {
+	if active.PrivateKey == nil {
+		return nil, ErrNoSigningKey
+	}
+	if active.PublicKey == nil {
+		active.PublicKey = &active.PrivateKey.PublicKey
+	}
+
+	ring := &KeyRing{keys: map[string]*Key{}}
+	for _, key := range append([]Key{active}, retired...) {
+		if key.PublicKey == nil || key.PublicKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key %q is not a P-256 key", key.ID)
+		}
+		if key.ID == "" {
+			key.ID = Thumbprint(key.PublicKey)
+		}
+		if _, ok := ring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+
+		key := key
+		ring.keys[key.ID] = &key
+		ring.order = append(ring.order, key.ID)
+	}
+	ring.active = ring.keys[ring.order[0]]
+
+	return ring, nil
+}
+
+// Active is the key new tokens are signed with.
+func (r *KeyRing) Active() *Key {
+	return r.active
+}
+
+// Keyfunc picks the verification key of a token for jwt.Parse. Tokens issued
+// before the ring carry no kid and are checked against every key.
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		set := jwt.VerificationKeySet{}
+		for _, id := range r.order {
+			set.Keys = append(set.Keys, r.keys[id].PublicKey)
+		}
+		return set, nil
+	}
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS publishes the public keys of the ring for the services verifying our
+// tokens, retired keys stay listed while they still verify tokens.
+func (r *KeyRing) JWKS() jwt_dto.JWKSet {
+	set := jwt_dto.JWKSet{Keys: make([]jwt_dto.JWK, 0, len(r.order))}
+	for _, id := range r.order {
+		x, y := coordinates(r.keys[id].PublicKey)
+		set.Keys = append(set.Keys, jwt_dto.JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   x,
+			Y:   y,
+			Kid: id,
+			Use: "sig",
+			Alg: jwt.SigningMethodES256.Alg(),
+		})
+	}
+	return set
+}
+
+// Thumbprint is the RFC 7638 thumbprint of the key, the kid of the keys
+// configured without one.
+func Thumbprint(key *ecdsa.PublicKey) string {
+	x, y := coordinates(key)
+	// the members are in lexicographic order as the RFC requires
+	canonical, _ := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{Crv: "P-256", Kty: "EC", X: x, Y: y})
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func coordinates(key *ecdsa.PublicKey) (string, string) {
+	x := key.X.FillBytes(make([]byte, 32))
+	y := key.Y.FillBytes(make([]byte, 32))
+	return base64.RawURLEncoding.EncodeToString(x), base64.RawURLEncoding.EncodeToString(y)
+}
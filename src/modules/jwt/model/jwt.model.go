@@ -0,0 +1,37 @@
+package jwt_model
+
+import (
+	"time"
+
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+)
+
+type RefreshTokenStatus string
+
+const (
+	RefreshTokenActive RefreshTokenStatus = "active"
+	// RefreshTokenRotated marks a token exchanged for a new pair, presenting it
+	// again means it leaked.
+	RefreshTokenRotated RefreshTokenStatus = "rotated"
+	RefreshTokenRevoked RefreshTokenStatus = "revoked"
+)
+
+// RefreshToken is an issued refresh token, only its hash is stored. Every token
+// rotated from the same login shares the family, so a detected reuse revokes
+// the whole chain.
+type RefreshToken struct {
+	ID     string `bson:"_id"`
+	UserID int64  `bson:"user_id"`
+	Family string `bson:"family"`
+	// Wallet is the ton_proof verified address carried by the tokens.
+	Wallet string `bson:"wallet,omitempty"`
+	// Roles and Scopes are the grants of the login, rotation keeps them.
+	Roles      []jwt_dto.Role     `bson:"roles,omitempty"`
+	Scopes     []jwt_dto.Scope    `bson:"scopes,omitempty"`
+	Status     RefreshTokenStatus `bson:"status"`
+	ReplacedBy string             `bson:"replaced_by,omitempty"`
+	// ExpiresAt is a date so the TTL index can drop expired tokens.
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt int64     `bson:"created_at"`
+	UpdatedAt int64     `bson:"updated_at"`
+}
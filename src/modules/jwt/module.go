@@ -1,22 +1,36 @@
 package jwt_module
 
 import (
+	"fmt"
+
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/root9464/Go_GamlerDefi/src/config"
+	jwt_controller "github.com/root9464/Go_GamlerDefi/src/modules/jwt/controller"
 	jwt_functions "github.com/root9464/Go_GamlerDefi/src/modules/jwt/functions"
 	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
-	jwt_utils "github.com/root9464/Go_GamlerDefi/src/modules/jwt/utils"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	jwt_repository "github.com/root9464/Go_GamlerDefi/src/modules/jwt/repository"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type JwtModule struct {
-	jwtFuncs   jwt_functions.IJwtFuncs
-	jwtHelpers jwt_helpers.IJwtHelper
+	jwtFuncs      jwt_functions.IJwtFuncs
+	jwtHelpers    jwt_helpers.IJwtHelper
+	jwtService    jwt_service.IJwtService
+	jwtRepository jwt_repository.IJwtRepository
+	jwtController jwt_controller.IJwtController
+	jwtMiddleware *admin_middleware.Middleware
 
-	privateKey string
-	publicKey  string
+	keyRing *jwt_keys.KeyRing
 
+	config    *config.Config
 	logger    *logger.Logger
 	validator *validator.Validate
+	db        *mongo.Database
 }
 
 func (m *JwtModule) JwtHelpers() jwt_helpers.IJwtHelper {
@@ -27,22 +41,71 @@ func (m *JwtModule) JwtHelpers() jwt_helpers.IJwtHelper {
 }
 
 func (m *JwtModule) JwtFuncs() jwt_functions.IJwtFuncs {
-	privateKey, publicKey, err := jwt_utils.HexToKeys(m.privateKey, m.publicKey)
-	if err != nil {
-		panic(err)
-	}
-
 	if m.jwtFuncs == nil {
-		m.jwtFuncs = jwt_functions.NewJwtFuncs(m.logger, m.validator, privateKey, publicKey, m.JwtHelpers())
+		m.jwtFuncs = jwt_functions.NewJwtFuncs(m.logger, m.validator, m.Keys(), m.JwtHelpers())
 	}
 	return m.jwtFuncs
 }
 
-func NewJwtModule(logger *logger.Logger, validator *validator.Validate, privateKey string, publicKey string) *JwtModule {
-	return &JwtModule{
-		logger:     logger,
-		validator:  validator,
-		privateKey: privateKey,
-		publicKey:  publicKey,
+func (m *JwtModule) Repository() jwt_repository.IJwtRepository {
+	if m.jwtRepository == nil {
+		m.jwtRepository = jwt_repository.NewJwtRepository(m.logger, m.db)
+	}
+	return m.jwtRepository
+}
+
+func (m *JwtModule) Service() jwt_service.IJwtService {
+	if m.jwtService == nil {
+		m.jwtService = jwt_service.NewJwtService(m.logger, m.Keys(), m.JwtFuncs(), m.Repository())
+	}
+	return m.jwtService
+}
+
+func (m *JwtModule) Controller() jwt_controller.IJwtController {
+	if m.jwtController == nil {
+		m.jwtController = jwt_controller.NewJwtController(m.logger, m.validator, m.Service())
+	}
+	return m.jwtController
+}
+
+func (m *JwtModule) Middleware() *admin_middleware.Middleware {
+	if m.jwtMiddleware == nil {
+		m.jwtMiddleware = admin_middleware.NewMiddleware(m.logger, m.JwtHelpers(), m.Keys())
 	}
+	return m.jwtMiddleware
+}
+
+func (m *JwtModule) RegisterRoutes(app fiber.Router) {
+	auth := app.Group("/auth")
+	auth.Post("/token", m.Middleware().AdminOnly(), m.Controller().IssueTokens)
+	auth.Post("/refresh", m.Controller().RefreshTokens)
+	auth.Post("/logout", m.Controller().Logout)
+}
+
+// RegisterWellKnownRoutes serves the JWKS at the root of the server, where
+// verifiers look for it.
+func (m *JwtModule) RegisterWellKnownRoutes(app fiber.Router) {
+	app.Get("/.well-known/jwks.json", m.Controller().GetJwks)
+}
+
+func (m *JwtModule) Keys() *jwt_keys.KeyRing {
+	return m.keyRing
+}
+
+// NewJwtModule loads the signing keys from the config, the module can not be
+// used without them. The middleware of the module is shared with the other
+// modules, so every one of them verifies tokens with the same key ring.
+func NewJwtModule(config *config.Config, logger *logger.Logger, validator *validator.Validate, db *mongo.Database) (*JwtModule, error) {
+	keys, err := jwt_keys.FromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	return &JwtModule{
+		keyRing:   keys,
+		config:    config,
+		logger:    logger,
+		validator: validator,
+		db:        db,
+	}, nil
 }
@@ -0,0 +1,149 @@
+package jwt_repository
+
+import (
+	"context"
+	"time"
+
+	jwt_model "github.com/root9464/Go_GamlerDefi/src/modules/jwt/model"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type IJwtRepository interface {
+	CreateRefreshToken(ctx context.Context, token jwt_model.RefreshToken) (jwt_model.RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (jwt_model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, tokenHash string, replacedBy string) (jwt_model.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, family string) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type JwtRepository struct {
+	logger *logger.Logger
+	db     *mongo.Database
+}
+
+const (
+	refresh_token_collection = "jwt_refresh_token"
+)
+
+func NewJwtRepository(logger *logger.Logger, db *mongo.Database) IJwtRepository {
+	return &JwtRepository{logger: logger, db: db}
+}
+
+func (r *JwtRepository) CreateRefreshToken(ctx context.Context, token jwt_model.RefreshToken) (jwt_model.RefreshToken, error) {
+	r.logger.Infof("storing refresh token of user %d in family %s", token.UserID, token.Family)
+
+	now := time.Now().Unix()
+	if token.CreatedAt == 0 {
+		token.CreatedAt = now
+	}
+	if token.UpdatedAt == 0 {
+		token.UpdatedAt = now
+	}
+	if token.Status == "" {
+		token.Status = jwt_model.RefreshTokenActive
+	}
+
+	collection := r.db.Collection(refresh_token_collection)
+	if _, err := collection.InsertOne(ctx, token); err != nil {
+		r.logger.Errorf("failed to insert refresh token: %v", err)
+		return jwt_model.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *JwtRepository) GetRefreshToken(ctx context.Context, tokenHash string) (jwt_model.RefreshToken, error) {
+	collection := r.db.Collection(refresh_token_collection)
+
+	var token jwt_model.RefreshToken
+	if err := collection.FindOne(ctx, bson.D{{Key: "_id", Value: tokenHash}}).Decode(&token); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to get refresh token: %v", err)
+		}
+		return jwt_model.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken marks an active token as exchanged for replacedBy.
+// Returns mongo.ErrNoDocuments when the token is not active anymore, which
+// happens when the same token is presented twice.
+func (r *JwtRepository) RotateRefreshToken(ctx context.Context, tokenHash string, replacedBy string) (jwt_model.RefreshToken, error) {
+	collection := r.db.Collection(refresh_token_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: tokenHash},
+		{Key: "status", Value: jwt_model.RefreshTokenActive},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: jwt_model.RefreshTokenRotated},
+		{Key: "replaced_by", Value: replacedBy},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token jwt_model.RefreshToken
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&token); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to rotate refresh token: %v", err)
+		}
+		return jwt_model.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshTokenFamily revokes the tokens of the family that are still
+// usable and returns how many were revoked.
+func (r *JwtRepository) RevokeRefreshTokenFamily(ctx context.Context, family string) (int64, error) {
+	r.logger.Infof("revoking refresh token family %s", family)
+
+	collection := r.db.Collection(refresh_token_collection)
+
+	filter := bson.D{
+		{Key: "family", Value: family},
+		{Key: "status", Value: jwt_model.RefreshTokenActive},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: jwt_model.RefreshTokenRevoked},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to revoke refresh token family: %v", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// EnsureIndexes creates the family index used on revocation and the TTL index
+// removing tokens once they expire.
+func (r *JwtRepository) EnsureIndexes(ctx context.Context) error {
+	r.logger.Infof("ensuring indexes of %s", refresh_token_collection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "family", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("family_status"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+
+	names, err := r.db.Collection(refresh_token_collection).Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		r.logger.Errorf("failed to create indexes of %s: %v", refresh_token_collection, err)
+		return err
+	}
+
+	r.logger.Infof("indexes of %s are ready: %v", refresh_token_collection, names)
+	return nil
+}
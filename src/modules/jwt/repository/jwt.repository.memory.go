@@ -0,0 +1,96 @@
+package jwt_repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	jwt_model "github.com/root9464/Go_GamlerDefi/src/modules/jwt/model"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// duplicateKeyCode is the mongo error code for a unique index violation.
+const duplicateKeyCode = 11000
+
+// MemoryRepository keeps refresh tokens in memory with the errors of the mongo
+// repository, it is meant for tests and local runs without a database.
+type MemoryRepository struct {
+	mu     sync.Mutex
+	tokens map[string]jwt_model.RefreshToken
+}
+
+func NewMemoryRepository() IJwtRepository {
+	return &MemoryRepository{tokens: map[string]jwt_model.RefreshToken{}}
+}
+
+func (r *MemoryRepository) CreateRefreshToken(ctx context.Context, token jwt_model.RefreshToken) (jwt_model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[token.ID]; ok {
+		return jwt_model.RefreshToken{}, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: duplicateKeyCode}}}
+	}
+
+	now := time.Now().Unix()
+	if token.CreatedAt == 0 {
+		token.CreatedAt = now
+	}
+	if token.UpdatedAt == 0 {
+		token.UpdatedAt = now
+	}
+	if token.Status == "" {
+		token.Status = jwt_model.RefreshTokenActive
+	}
+
+	r.tokens[token.ID] = token
+	return token, nil
+}
+
+func (r *MemoryRepository) GetRefreshToken(ctx context.Context, tokenHash string) (jwt_model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return jwt_model.RefreshToken{}, mongo.ErrNoDocuments
+	}
+	return token, nil
+}
+
+func (r *MemoryRepository) RotateRefreshToken(ctx context.Context, tokenHash string, replacedBy string) (jwt_model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.Status != jwt_model.RefreshTokenActive {
+		return jwt_model.RefreshToken{}, mongo.ErrNoDocuments
+	}
+
+	token.Status = jwt_model.RefreshTokenRotated
+	token.ReplacedBy = replacedBy
+	token.UpdatedAt = time.Now().Unix()
+	r.tokens[tokenHash] = token
+	return token, nil
+}
+
+func (r *MemoryRepository) RevokeRefreshTokenFamily(ctx context.Context, family string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked int64
+	now := time.Now().Unix()
+	for id, token := range r.tokens {
+		if token.Family != family || token.Status != jwt_model.RefreshTokenActive {
+			continue
+		}
+		token.Status = jwt_model.RefreshTokenRevoked
+		token.UpdatedAt = now
+		r.tokens[id] = token
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (r *MemoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
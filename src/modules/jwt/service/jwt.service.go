@@ -0,0 +1,185 @@
+package jwt_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_functions "github.com/root9464/Go_GamlerDefi/src/modules/jwt/functions"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	jwt_model "github.com/root9464/Go_GamlerDefi/src/modules/jwt/model"
+	jwt_repository "github.com/root9464/Go_GamlerDefi/src/modules/jwt/repository"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type IJwtService interface {
+	IssueTokens(ctx context.Context, userData jwt_dto.UserData) (jwt_dto.TokenPairResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (jwt_dto.TokenPairResponse, error)
+	RevokeTokens(ctx context.Context, refreshToken string) error
+	PublicKeys() jwt_dto.JWKSet
+}
+
+type JwtService struct {
+	logger *logger.Logger
+
+	keys *jwt_keys.KeyRing
+
+	jwt_funcs      jwt_functions.IJwtFuncs
+	jwt_repository jwt_repository.IJwtRepository
+}
+
+func NewJwtService(
+	logger *logger.Logger, keys *jwt_keys.KeyRing,
+	jwt_funcs jwt_functions.IJwtFuncs, jwt_repository jwt_repository.IJwtRepository,
+) IJwtService {
+	return &JwtService{
+		logger:         logger,
+		keys:           keys,
+		jwt_funcs:      jwt_funcs,
+		jwt_repository: jwt_repository,
+	}
+}
+
+// IssueTokens signs a new pair for the user and starts a refresh token family.
+func (s *JwtService) IssueTokens(ctx context.Context, userData jwt_dto.UserData) (jwt_dto.TokenPairResponse, error) {
+	s.logger.Infof("issuing tokens for user %d with wallet %q", userData.ID, userData.Wallet)
+
+	accessToken, refreshToken, err := s.jwt_funcs.GenerateKeyPair(userData)
+	if err != nil {
+		s.logger.Errorf("failed to generate key pair: %v", err)
+		return jwt_dto.TokenPairResponse{}, mapFuncsError(err)
+	}
+
+	if _, err := s.storeRefreshToken(ctx, *refreshToken, userData, bson.NewObjectID().Hex()); err != nil {
+		return jwt_dto.TokenPairResponse{}, err
+	}
+
+	return jwt_dto.TokenPairResponse{AccessToken: *accessToken, RefreshToken: *refreshToken}, nil
+}
+
+// RefreshTokens exchanges an active refresh token for a new pair, the presented
+// token can not be used again. A token presented after it was rotated or
+// revoked revokes every token of its family.
+func (s *JwtService) RefreshTokens(ctx context.Context, refreshToken string) (jwt_dto.TokenPairResponse, error) {
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return jwt_dto.TokenPairResponse{}, err
+	}
+
+	if stored.Status != jwt_model.RefreshTokenActive {
+		return jwt_dto.TokenPairResponse{}, s.reuseDetected(ctx, stored)
+	}
+
+	accessToken, err := s.jwt_funcs.RefreshAccessToken(refreshToken, s.keys)
+	if err != nil {
+		s.logger.Warnf("refresh token of user %d rejected: %v", stored.UserID, err)
+		return jwt_dto.TokenPairResponse{}, errors.NewError(401, "invalid refresh token")
+	}
+
+	// only the refresh token of the new pair is kept, the access token comes
+	// from RefreshAccessToken
+	userData := jwt_dto.UserData{ID: stored.UserID, Wallet: stored.Wallet, Roles: stored.Roles, Scopes: stored.Scopes}
+	_, nextRefreshToken, err := s.jwt_funcs.GenerateKeyPair(userData)
+	if err != nil {
+		s.logger.Errorf("failed to generate refresh token: %v", err)
+		return jwt_dto.TokenPairResponse{}, mapFuncsError(err)
+	}
+
+	if _, err := s.jwt_repository.RotateRefreshToken(ctx, stored.ID, hashToken(*nextRefreshToken)); err != nil {
+		if err == mongo.ErrNoDocuments {
+			// another request rotated the token in the meantime
+			return jwt_dto.TokenPairResponse{}, s.reuseDetected(ctx, stored)
+		}
+		return jwt_dto.TokenPairResponse{}, errors.NewError(500, "failed to rotate refresh token")
+	}
+
+	if _, err := s.storeRefreshToken(ctx, *nextRefreshToken, userData, stored.Family); err != nil {
+		return jwt_dto.TokenPairResponse{}, err
+	}
+
+	s.logger.Infof("refresh token of user %d rotated in family %s", stored.UserID, stored.Family)
+	return jwt_dto.TokenPairResponse{AccessToken: *accessToken, RefreshToken: *nextRefreshToken}, nil
+}
+
+// RevokeTokens ends the session of the refresh token by revoking its family.
+// Revoking an already revoked session succeeds.
+func (s *JwtService) RevokeTokens(ctx context.Context, refreshToken string) error {
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.jwt_repository.RevokeRefreshTokenFamily(ctx, stored.Family)
+	if err != nil {
+		return errors.NewError(500, "failed to revoke refresh token")
+	}
+
+	s.logger.Infof("revoked %d refresh tokens of user %d", revoked, stored.UserID)
+	return nil
+}
+
+func (s *JwtService) lookupRefreshToken(ctx context.Context, refreshToken string) (jwt_model.RefreshToken, error) {
+	stored, err := s.jwt_repository.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err == mongo.ErrNoDocuments {
+		s.logger.Warn("unknown refresh token presented")
+		return jwt_model.RefreshToken{}, errors.NewError(401, "invalid refresh token")
+	}
+	if err != nil {
+		return jwt_model.RefreshToken{}, errors.NewError(500, "failed to get refresh token")
+	}
+
+	if !stored.ExpiresAt.After(time.Now()) {
+		return jwt_model.RefreshToken{}, errors.NewError(401, "refresh token has expired")
+	}
+	return stored, nil
+}
+
+func (s *JwtService) reuseDetected(ctx context.Context, stored jwt_model.RefreshToken) error {
+	s.logger.Warnf("reuse of %s refresh token detected for user %d, revoking family %s", stored.Status, stored.UserID, stored.Family)
+
+	if _, err := s.jwt_repository.RevokeRefreshTokenFamily(ctx, stored.Family); err != nil {
+		return errors.NewError(500, "failed to revoke refresh token")
+	}
+	return errors.NewError(401, "refresh token reuse detected")
+}
+
+// PublicKeys lists the keys our tokens are verified with.
+func (s *JwtService) PublicKeys() jwt_dto.JWKSet {
+	return s.keys.JWKS()
+}
+
+func (s *JwtService) storeRefreshToken(ctx context.Context, refreshToken string, userData jwt_dto.UserData, family string) (jwt_model.RefreshToken, error) {
+	stored, err := s.jwt_repository.CreateRefreshToken(ctx, jwt_model.RefreshToken{
+		ID:        hashToken(refreshToken),
+		UserID:    userData.ID,
+		Family:    family,
+		Wallet:    userData.Wallet,
+		Roles:     userData.Roles,
+		Scopes:    userData.Scopes,
+		Status:    jwt_model.RefreshTokenActive,
+		ExpiresAt: time.Now().Add(jwt_functions.RefreshTokenExpiry),
+	})
+	if err != nil {
+		return jwt_model.RefreshToken{}, errors.NewError(500, "failed to store refresh token")
+	}
+	return stored, nil
+}
+
+// mapFuncsError keeps the status of the fiber errors returned by JwtFuncs.
+func mapFuncsError(err error) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return errors.NewError(fiberErr.Code, fiberErr.Message)
+	}
+	return errors.NewError(500, "failed to sign tokens")
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,6 +3,7 @@ package jwt_utils
 import (
 	"crypto/ecdsa"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 )
@@ -18,20 +19,60 @@ func HexToKeys(privateKeyHex, publicKeyHex string) (*ecdsa.PrivateKey, *ecdsa.Pu
 		return nil, nil, fmt.Errorf("error decoding public key hex: %w", err)
 	}
 
+	return parseKeys(privKeyBytes, pubKeyBytes)
+}
+
+// Base64ToKeys decodes the keys the way PRIVATE_KEY and PUBLIC_KEY are
+// configured, the signing key of the key ring is loaded with it.
+func Base64ToKeys(privateKeyBase64, publicKeyBase64 string) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
+	privKeyBytes, err := base64.StdEncoding.DecodeString(privateKeyBase64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding private key base64: %w", err)
+	}
+
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding public key base64: %w", err)
+	}
+
+	return parseKeys(privKeyBytes, pubKeyBytes)
+}
+
+func parseKeys(privKeyBytes, pubKeyBytes []byte) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
 	privateKey, err := x509.ParseECPrivateKey(privKeyBytes)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error parsing ECDSA private key: %w", err)
 	}
 
+	publicKey, err := parsePublicKey(pubKeyBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privateKey, publicKey, nil
+}
+
+// Base64ToPublicKey decodes a public key configured without its private key,
+// like the retired keys of JWT_VERIFY_KEYS.
+func Base64ToPublicKey(publicKeyBase64 string) (*ecdsa.PublicKey, error) {
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding public key base64: %w", err)
+	}
+
+	return parsePublicKey(pubKeyBytes)
+}
+
+func parsePublicKey(pubKeyBytes []byte) (*ecdsa.PublicKey, error) {
 	pubKeyInterface, err := x509.ParsePKIXPublicKey(pubKeyBytes)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing ECDSA public key: %w", err)
+		return nil, fmt.Errorf("error parsing ECDSA public key: %w", err)
 	}
 
 	publicKey, ok := pubKeyInterface.(*ecdsa.PublicKey)
 	if !ok {
-		return nil, nil, fmt.Errorf("parsed public key is not ECDSA")
+		return nil, fmt.Errorf("parsed public key is not ECDSA")
 	}
 
-	return privateKey, publicKey, nil
+	return publicKey, nil
 }
@@ -36,14 +36,18 @@ func CreatePaymentOrderFromDTO(req referral_dto.PaymentOrder) (referral_model.Pa
 	}
 
 	paymentOrder := referral_model.PaymentOrder{
-		LeaderID:    req.LeaderID,
-		ReferrerID:  req.ReferrerID,
-		ReferralID:  req.ReferralID,
-		TotalAmount: totalAmount,
-		TicketCount: req.TicketCount,
-		CreatedAt:   req.CreatedAt,
-		Levels:      levels,
-		TrHash:      req.TrHash,
+		LeaderID:        req.LeaderID,
+		ReferrerID:      req.ReferrerID,
+		ReferralID:      req.ReferralID,
+		TotalAmount:     totalAmount,
+		TicketCount:     req.TicketCount,
+		CreatedAt:       req.CreatedAt,
+		Levels:          levels,
+		TrHash:          req.TrHash,
+		ScheduleVersion: req.ScheduleVersion,
+		ScheduleSource:  req.ScheduleSource,
+		ProgramVersion:  req.ProgramVersion,
+		Status:          referral_model.PaymentOrderStatus(req.Status),
 	}
 
 	return paymentOrder, nil
@@ -76,15 +80,29 @@ func CreatePaymentOrderFromModel(dbData referral_model.PaymentOrder) (referral_d
 	}
 
 	paymentOrderDTO := referral_dto.PaymentOrder{
-		ID:          dbData.ID.Hex(),
-		LeaderID:    dbData.LeaderID,
-		ReferrerID:  dbData.ReferrerID,
-		ReferralID:  dbData.ReferralID,
-		TotalAmount: totalAmount,
-		TicketCount: dbData.TicketCount,
-		CreatedAt:   dbData.CreatedAt,
-		Levels:      levels,
-		TrHash:      dbData.TrHash,
+		ID:              dbData.ID.Hex(),
+		LeaderID:        dbData.LeaderID,
+		ReferrerID:      dbData.ReferrerID,
+		ReferralID:      dbData.ReferralID,
+		TotalAmount:     totalAmount,
+		TicketCount:     dbData.TicketCount,
+		CreatedAt:       dbData.CreatedAt,
+		Levels:          levels,
+		TrHash:          dbData.TrHash,
+		ScheduleVersion: dbData.ScheduleVersion,
+		ScheduleSource:  dbData.ScheduleSource,
+		ProgramVersion:  dbData.ProgramVersion,
+		Status:          referral_dto.PaymentOrderStatus(dbData.CurrentStatus()),
+		StatusHistory:   make([]referral_dto.StatusTransition, len(dbData.StatusHistory)),
+		BundleIDs:       dbData.BundleIDs,
+	}
+
+	for i, transition := range dbData.StatusHistory {
+		paymentOrderDTO.StatusHistory[i] = referral_dto.StatusTransition{
+			From: referral_dto.PaymentOrderStatus(transition.From),
+			To:   referral_dto.PaymentOrderStatus(transition.To),
+			At:   transition.At,
+		}
 	}
 
 	return paymentOrderDTO, nil
@@ -102,3 +120,213 @@ func CreatePaymentOrderFromModelList(req []referral_model.PaymentOrder) ([]refer
 
 	return paymentOrders, nil
 }
+
+func CreateBonusScheduleFromDTO(req referral_dto.BonusScheduleRequest) (referral_model.BonusSchedule, error) {
+	levels := make([]referral_model.ScheduleLevel, len(req.Levels))
+	for i, level := range req.Levels {
+		rate, err := bson.ParseDecimal128(level.Rate.String())
+		if err != nil {
+			return referral_model.BonusSchedule{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		levels[i] = referral_model.ScheduleLevel{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+		}
+	}
+
+	return referral_model.BonusSchedule{
+		Levels:        levels,
+		EffectiveFrom: req.EffectiveFrom,
+	}, nil
+}
+
+func CreateBonusScheduleFromModel(dbData referral_model.BonusSchedule) (referral_dto.BonusSchedule, error) {
+	levels := make([]referral_dto.ScheduleLevel, len(dbData.Levels))
+	for i, level := range dbData.Levels {
+		rate, err := decimal.NewFromString(level.Rate.String())
+		if err != nil {
+			return referral_dto.BonusSchedule{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		levels[i] = referral_dto.ScheduleLevel{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+		}
+	}
+
+	return referral_dto.BonusSchedule{
+		ID:            dbData.ID.Hex(),
+		Version:       dbData.Version,
+		Levels:        levels,
+		EffectiveFrom: dbData.EffectiveFrom,
+		CreatedAt:     dbData.CreatedAt,
+		UpdatedAt:     dbData.UpdatedAt,
+	}, nil
+}
+
+func CreateBonusScheduleFromModelList(req []referral_model.BonusSchedule) ([]referral_dto.BonusSchedule, error) {
+	schedules := make([]referral_dto.BonusSchedule, len(req))
+	for i, schedule := range req {
+		scheduleDTO, err := CreateBonusScheduleFromModel(schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bonus schedule from model: %w", err)
+		}
+		schedules[i] = scheduleDTO
+	}
+
+	return schedules, nil
+}
+
+func CreateLeaderProgramFromDTO(leaderID int, req referral_dto.LeaderProgramRequest) (referral_model.LeaderProgram, error) {
+	levels := make([]referral_model.ScheduleLevel, len(req.Levels))
+	for i, level := range req.Levels {
+		rate, err := bson.ParseDecimal128(level.Rate.String())
+		if err != nil {
+			return referral_model.LeaderProgram{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		levels[i] = referral_model.ScheduleLevel{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+		}
+	}
+
+	program := referral_model.LeaderProgram{
+		LeaderID: leaderID,
+		Depth:    req.Depth,
+		Levels:   levels,
+	}
+
+	if req.MaxDebt != nil {
+		maxDebt, err := bson.ParseDecimal128(req.MaxDebt.String())
+		if err != nil {
+			return referral_model.LeaderProgram{}, fmt.Errorf("failed to convert max debt: %w", err)
+		}
+		program.MaxDebt = &maxDebt
+	}
+
+	return program, nil
+}
+
+func CreateLeaderProgramFromModel(dbData referral_model.LeaderProgram) (referral_dto.LeaderProgram, error) {
+	levels := make([]referral_dto.ScheduleLevel, len(dbData.Levels))
+	for i, level := range dbData.Levels {
+		rate, err := decimal.NewFromString(level.Rate.String())
+		if err != nil {
+			return referral_dto.LeaderProgram{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		levels[i] = referral_dto.ScheduleLevel{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+		}
+	}
+
+	program := referral_dto.LeaderProgram{
+		ID:        dbData.ID.Hex(),
+		LeaderID:  dbData.LeaderID,
+		Version:   dbData.Version,
+		Depth:     dbData.Depth,
+		Levels:    levels,
+		Deleted:   dbData.Deleted,
+		CreatedAt: dbData.CreatedAt,
+		UpdatedAt: dbData.UpdatedAt,
+	}
+
+	if dbData.MaxDebt != nil {
+		maxDebt, err := decimal.NewFromString(dbData.MaxDebt.String())
+		if err != nil {
+			return referral_dto.LeaderProgram{}, fmt.Errorf("failed to convert max debt: %w", err)
+		}
+		program.MaxDebt = &maxDebt
+	}
+
+	return program, nil
+}
+
+func CreatePayoutFromDTO(req referral_dto.Payout) (referral_model.Payout, error) {
+	levels := make([]referral_model.Level, len(req.Levels))
+	for i, level := range req.Levels {
+		rate, err := bson.ParseDecimal128(level.Rate.String())
+		if err != nil {
+			return referral_model.Payout{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		amount, err := bson.ParseDecimal128(level.Amount.String())
+		if err != nil {
+			return referral_model.Payout{}, fmt.Errorf("failed to convert amount: %w", err)
+		}
+
+		levels[i] = referral_model.Level{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+			Amount:      amount,
+			Address:     level.Address,
+		}
+	}
+
+	totalAmount, err := bson.ParseDecimal128(req.TotalAmount.String())
+	if err != nil {
+		return referral_model.Payout{}, fmt.Errorf("failed to convert total amount: %w", err)
+	}
+
+	payout := referral_model.Payout{
+		ReferrerID:      req.ReferrerID,
+		ReferralID:      req.ReferralID,
+		TicketCount:     req.TicketCount,
+		TotalAmount:     totalAmount,
+		Levels:          levels,
+		ScheduleVersion: req.ScheduleVersion,
+		Status:          referral_model.PayoutStatus(req.Status),
+		CreatedAt:       req.CreatedAt,
+	}
+
+	return payout, nil
+}
+
+func CreatePayoutFromModel(dbData referral_model.Payout) (referral_dto.Payout, error) {
+	levels := make([]referral_dto.LevelRequest, len(dbData.Levels))
+	for i, level := range dbData.Levels {
+		rate, err := decimal.NewFromString(level.Rate.String())
+		if err != nil {
+			return referral_dto.Payout{}, fmt.Errorf("failed to convert rate: %w", err)
+		}
+
+		amount, err := decimal.NewFromString(level.Amount.String())
+		if err != nil {
+			return referral_dto.Payout{}, fmt.Errorf("failed to convert amount: %w", err)
+		}
+
+		levels[i] = referral_dto.LevelRequest{
+			LevelNumber: level.LevelNumber,
+			Rate:        rate,
+			Amount:      amount,
+			Address:     level.Address,
+		}
+	}
+
+	totalAmount, err := decimal.NewFromString(dbData.TotalAmount.String())
+	if err != nil {
+		return referral_dto.Payout{}, fmt.Errorf("failed to convert total amount: %w", err)
+	}
+
+	payout := referral_dto.Payout{
+		ID:              dbData.ID.Hex(),
+		ReferrerID:      dbData.ReferrerID,
+		ReferralID:      dbData.ReferralID,
+		TicketCount:     dbData.TicketCount,
+		TotalAmount:     totalAmount,
+		Levels:          levels,
+		ScheduleVersion: dbData.ScheduleVersion,
+		Status:          referral_dto.PayoutStatus(dbData.Status),
+		TxHash:          dbData.TxHash,
+		BatchID:         dbData.BatchID,
+		Error:           dbData.Error,
+		Attempts:        dbData.Attempts,
+		CreatedAt:       dbData.CreatedAt,
+		UpdatedAt:       dbData.UpdatedAt,
+	}
+
+	return payout, nil
+}
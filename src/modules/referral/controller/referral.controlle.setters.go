@@ -1,14 +1,13 @@
 package referral_controller
 
 import (
-	"strconv"
-
 	"github.com/gofiber/fiber/v2"
 	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
-	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 // ReferralProcessPlatform handles referral bonus calculation
 // @Summary Process of awarding referral bonuses to the user
 // @Description Calculate and distribute referral bonuses between users
@@ -16,8 +15,11 @@ import (
 // @Accept json
 // @Produce json
 // @Param request body referral_dto.ReferralProcessRequest true "Referral processing data"
-// @Success 200 {object} fiber.Map "Success response"
-// @Failure 400 {object} errors.MapError "Validation error"
+// @Param Idempotency-Key header string true "Key of the purchase, retries of the same purchase reuse it"
+// @Success 201 {object} referral_dto.ReferralProcessResult "Success response, platform accruals return the queued payout ID"
+// @Failure 400 {object} errors.MapError "Validation error or missing idempotency key"
+// @Failure 409 {object} errors.MapError "Request with the same key is still processing"
+// @Failure 422 {object} errors.MapError "Key was used with a different request"
 // @Failure 500 {object} errors.MapError "Internal server error"
 // @Router /api/referrals/process [post]
 func (c *ReferralController) ReferralProcessPlatform(ctx *fiber.Ctx) error {
@@ -33,97 +35,114 @@ func (c *ReferralController) ReferralProcessPlatform(ctx *fiber.Ctx) error {
 
 	c.logger.Infof("processing referral for referrer ID: %d", dto.ReferrerID)
 
-	err := c.referral_service.ReferralProcess(ctx.Context(), dto)
+	result, err := c.referral_service.ReferralProcess(ctx.Context(), dto, ctx.Get(idempotencyKeyHeader))
 	if err != nil {
 		c.logger.Errorf("error calculating referral bonuses: %v", err)
-		return errors.NewError(500, err.Error())
+		return err
 	}
 
-	return ctx.Status(201).JSON(fiber.Map{
-		"message": "Referral process completed successfully",
-	})
+	return ctx.Status(201).JSON(result)
 }
 
-// @Summary Delete payment order
-// @Description Delete a payment order by ID
+// @Summary Cancel payment order
+// @Description Cancel an unpaid payment order by ID, the order is kept with the cancelled status
 // @Tags Referrals
 // @Accept json
 // @Produce json
-// @Param order_id path string true "Order ID"
+// @Param Authorization header string true "Bearer access token"
+// @Param order_id query string true "Order ID"
 // @Success 200 {object} fiber.Map "Success response"
 // @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Order of another author"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Order is already paid, cancelled or expired"
 // @Failure 500 {object} errors.MapError "Internal server error"
-// @Router /api/referrals/delete/{order_id} [delete]
+// @Router /api/referral/payment-orders [delete]
 func (c *ReferralController) DeletePaymentOrder(ctx *fiber.Ctx) error {
 	paramOrderID := ctx.Query("order_id")
 	c.logger.Infof("order ID: %s", paramOrderID)
 
-	orderID, err := bson.ObjectIDFromHex(paramOrderID)
-	if err != nil {
-		c.logger.Fatalf("Invalid ObjectID string: %v", err)
+	if err := c.authorizePaymentOrder(ctx, paramOrderID); err != nil {
+		return err
 	}
 
-	err = c.referral_repository.DeletePaymentOrder(ctx.Context(), orderID)
-	if err != nil {
-		c.logger.Errorf("error deleting payment order: %v", err)
-		return errors.NewError(500, err.Error())
+	if err := c.referral_service.CancelPaymentOrder(ctx.Context(), paramOrderID); err != nil {
+		c.logger.Errorf("error cancelling payment order: %v", err)
+		return err
 	}
 
 	return ctx.Status(200).JSON(fiber.Map{
-		"message": "Payment order deleted successfully",
+		"message": "Payment order cancelled successfully",
 	})
 }
 
-// @Summary Delete all payment orders
-// @Description Delete all payment orders
+// @Summary Cancel all payment orders
+// @Description Cancel all unpaid payment orders of the author
 // @Tags Referrals
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param author_id query int false "Author ID, defaults to the token subject, only admins may name another author"
 // @Success 200 {object} fiber.Map "Success response"
-// @Failure 500 {object} fiber.Map "Internal server error"
-// @Router /api/referrals/delete/all [delete]
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Orders of another author"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/payment-orders/all [delete]
 func (c *ReferralController) DeleteAllPaymentOrders(ctx *fiber.Ctx) error {
 	paramAuthorID := ctx.Query("author_id")
 	c.logger.Infof("author ID: %s", paramAuthorID)
-	if paramAuthorID == "" {
-		return ctx.Status(400).JSON(fiber.Map{
-			"message": "Author ID is required",
-		})
-	}
 
-	authorID, err := strconv.Atoi(paramAuthorID)
+	authorID, err := c.callerAuthorID(ctx, paramAuthorID)
 	if err != nil {
-		c.logger.Errorf("error converting author ID to int: %v", err)
-		return errors.NewError(400, err.Error())
+		return err
 	}
 
-	err = c.referral_repository.DeleteAllPaymentOrders(ctx.Context(), authorID)
+	err = c.referral_service.CancelAllPaymentOrders(ctx.Context(), authorID)
 	if err != nil {
-		c.logger.Errorf("error deleting all payment orders: %v", err)
-		return errors.NewError(500, err.Error())
+		c.logger.Errorf("error cancelling all payment orders: %v", err)
+		return err
 	}
 
 	return ctx.Status(200).JSON(fiber.Map{
-		"message": "All payment orders deleted successfully",
+		"message": "All payment orders cancelled successfully",
 	})
 }
 
+// @Summary Add transaction hash to payment order
+// @Description Store the hash of the transaction paying the order, an open order moves to awaiting payment
+// @Tags Referrals
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param request body referral_dto.AddTrHashToPaymentOrderRequest true "Order ID and transaction hash"
+// @Success 200 {object} fiber.Map "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Order of another author"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Order is already paid, cancelled or expired"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/payment-orders/add-hash [post]
 func (c *ReferralController) AddTrHashToPaymentOrder(ctx *fiber.Ctx) error {
 	var dto referral_dto.AddTrHashToPaymentOrderRequest
 	if err := ctx.BodyParser(&dto); err != nil {
 		c.logger.Errorf("error parsing request body: %v", err)
 		return errors.NewError(400, err.Error())
 	}
+	if err := c.validator.Struct(dto); err != nil {
+		c.logger.Errorf("validation error: %s", err.Error())
+		return errors.NewError(400, err.Error())
+	}
 
-	orderID, err := bson.ObjectIDFromHex(dto.OrderID)
-	if err != nil {
-		c.logger.Fatalf("Invalid ObjectID string: %v", err)
+	if err := c.authorizePaymentOrder(ctx, dto.OrderID); err != nil {
+		return err
 	}
 
-	err = c.referral_repository.AddTrHashToPaymentOrder(ctx.Context(), orderID, dto.TrHash)
-	if err != nil {
+	if err := c.referral_service.AddTrHashToPaymentOrder(ctx.Context(), dto.OrderID, dto.TrHash); err != nil {
 		c.logger.Errorf("error adding tr hash to payment order: %v", err)
-		return errors.NewError(500, err.Error())
+		return err
 	}
 
 	return ctx.Status(200).JSON(fiber.Map{
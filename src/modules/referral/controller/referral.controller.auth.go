@@ -0,0 +1,116 @@
+package referral_controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+	"github.com/xssnick/tonutils-go/address"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// callerAuthorID resolves the author the request acts on. Users are bound to
+// the subject of their token, an admin may name any author.
+func (c *ReferralController) callerAuthorID(ctx *fiber.Ctx, paramAuthorID string) (int, error) {
+	user, ok := admin_middleware.UserFromContext(ctx)
+	if !ok {
+		return 0, errors.NewError(401, "authentication required")
+	}
+
+	if paramAuthorID == "" {
+		return int(user.Sub), nil
+	}
+
+	authorID, err := strconv.Atoi(paramAuthorID)
+	if err != nil {
+		c.logger.Errorf("error converting author ID: %v", err)
+		return 0, errors.NewError(400, err.Error())
+	}
+
+	if authorID != int(user.Sub) && !admin_middleware.IsAdmin(ctx) {
+		c.logger.Warnf("user %d is not allowed to act on author %d", user.Sub, authorID)
+		return 0, errors.NewError(403, "payment orders of another author are not accessible")
+	}
+	return authorID, nil
+}
+
+// authorizePaymentOrder checks that the caller owns the payment order, admins
+// are allowed to act on any order.
+func (c *ReferralController) authorizePaymentOrder(ctx *fiber.Ctx, paramOrderID string) error {
+	user, ok := admin_middleware.UserFromContext(ctx)
+	if !ok {
+		return errors.NewError(401, "authentication required")
+	}
+	if admin_middleware.IsAdmin(ctx) {
+		return nil
+	}
+
+	orderID, err := bson.ObjectIDFromHex(paramOrderID)
+	if err != nil {
+		c.logger.Errorf("error converting order ID: %v", err)
+		return errors.NewError(400, "invalid payment order ID")
+	}
+
+	order, err := c.referral_repository.GetPaymentOrderByID(ctx.Context(), orderID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "payment order not found")
+	}
+	if err != nil {
+		c.logger.Errorf("error getting payment order: %v", err)
+		return errors.NewError(500, "failed to get payment order")
+	}
+
+	if order.LeaderID != int(user.Sub) {
+		c.logger.Warnf("user %d is not allowed to act on payment order %s", user.Sub, paramOrderID)
+		return errors.NewError(403, "payment order of another author is not accessible")
+	}
+	return nil
+}
+
+// callerWallet returns the wallet the payment is made from. Users pay from the
+// wallet verified with ton_proof, the Wallet-Address header may only repeat it.
+// Admins have no verified wallet and name it with the header.
+func (c *ReferralController) callerWallet(ctx *fiber.Ctx) (string, error) {
+	user, ok := admin_middleware.UserFromContext(ctx)
+	if !ok {
+		return "", errors.NewError(401, "authentication required")
+	}
+
+	walletAddress := ctx.Get("Wallet-Address")
+	if user.Wallet != "" {
+		if walletAddress != "" && !sameAddress(walletAddress, user.Wallet) {
+			c.logger.Warnf("user %d sent wallet %s, verified wallet is %s", user.Sub, walletAddress, user.Wallet)
+			return "", errors.NewError(403, "wallet address does not match the verified wallet")
+		}
+		return user.Wallet, nil
+	}
+
+	if !admin_middleware.IsAdmin(ctx) {
+		return "", errors.NewError(403, "wallet is not verified, connect it with ton_proof")
+	}
+	if walletAddress == "" {
+		return "", errors.NewError(400, "Wallet address is required")
+	}
+	return walletAddress, nil
+}
+
+func sameAddress(a, b string) bool {
+	first, err := parseAddress(a)
+	if err != nil {
+		return false
+	}
+	second, err := parseAddress(b)
+	if err != nil {
+		return false
+	}
+	return first.Equals(second)
+}
+
+func parseAddress(addr string) (*address.Address, error) {
+	if parsed, err := address.ParseAddr(addr); err == nil {
+		return parsed, nil
+	}
+	return address.ParseRawAddr(addr)
+}
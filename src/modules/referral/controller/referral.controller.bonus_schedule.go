@@ -0,0 +1,162 @@
+package referral_controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// @Summary Create bonus schedule
+// @Description Create a new version of referral bonus rates
+// @Tags Referrals
+// @Accept json
+// @Produce json
+// @Param request body referral_dto.BonusScheduleRequest true "Bonus schedule data"
+// @Success 201 {object} referral_dto.BonusSchedule "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/bonus-schedules [post]
+func (c *ReferralController) CreateBonusSchedule(ctx *fiber.Ctx) error {
+	var dto referral_dto.BonusScheduleRequest
+	if err := ctx.BodyParser(&dto); err != nil {
+		c.logger.Errorf("error parsing request body: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+	if err := c.validator.Struct(dto); err != nil {
+		c.logger.Errorf("validation error: %s", err.Error())
+		return errors.NewError(400, err.Error())
+	}
+
+	schedule, err := c.referral_service.CreateBonusSchedule(ctx.Context(), dto)
+	if err != nil {
+		c.logger.Errorf("error creating bonus schedule: %v", err)
+		return err
+	}
+
+	return ctx.Status(201).JSON(schedule)
+}
+
+// @Summary Get bonus schedules
+// @Description Get all versions of referral bonus rates
+// @Tags Referrals
+// @Produce json
+// @Success 200 {array} referral_dto.BonusSchedule "Success response"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/bonus-schedules [get]
+func (c *ReferralController) GetBonusSchedules(ctx *fiber.Ctx) error {
+	schedules, err := c.referral_repository.GetBonusSchedules(ctx.Context())
+	if err != nil {
+		c.logger.Errorf("error getting bonus schedules: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	schedulesDTO, err := referral_adapters.CreateBonusScheduleFromModelList(schedules)
+	if err != nil {
+		c.logger.Errorf("error converting bonus schedules to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(schedulesDTO)
+}
+
+// @Summary Get bonus schedule
+// @Description Get a version of referral bonus rates by ID
+// @Tags Referrals
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} referral_dto.BonusSchedule "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/bonus-schedules/{schedule_id} [get]
+func (c *ReferralController) GetBonusSchedule(ctx *fiber.Ctx) error {
+	paramScheduleID := ctx.Params("schedule_id")
+	c.logger.Infof("schedule ID: %s", paramScheduleID)
+
+	scheduleID, err := bson.ObjectIDFromHex(paramScheduleID)
+	if err != nil {
+		c.logger.Errorf("invalid schedule ID: %v", err)
+		return errors.NewError(400, "invalid schedule ID")
+	}
+
+	schedule, err := c.referral_repository.GetBonusScheduleByID(ctx.Context(), scheduleID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "bonus schedule not found")
+	}
+	if err != nil {
+		c.logger.Errorf("error getting bonus schedule: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	scheduleDTO, err := referral_adapters.CreateBonusScheduleFromModel(schedule)
+	if err != nil {
+		c.logger.Errorf("error converting bonus schedule to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(scheduleDTO)
+}
+
+// @Summary Update bonus schedule
+// @Description Update a bonus schedule that is not in force yet
+// @Tags Referrals
+// @Accept json
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Param request body referral_dto.BonusScheduleRequest true "Bonus schedule data"
+// @Success 200 {object} referral_dto.BonusSchedule "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Schedule already in force"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/bonus-schedules/{schedule_id} [put]
+func (c *ReferralController) UpdateBonusSchedule(ctx *fiber.Ctx) error {
+	paramScheduleID := ctx.Params("schedule_id")
+	c.logger.Infof("schedule ID: %s", paramScheduleID)
+
+	var dto referral_dto.BonusScheduleRequest
+	if err := ctx.BodyParser(&dto); err != nil {
+		c.logger.Errorf("error parsing request body: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+	if err := c.validator.Struct(dto); err != nil {
+		c.logger.Errorf("validation error: %s", err.Error())
+		return errors.NewError(400, err.Error())
+	}
+
+	schedule, err := c.referral_service.UpdateBonusSchedule(ctx.Context(), paramScheduleID, dto)
+	if err != nil {
+		c.logger.Errorf("error updating bonus schedule: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(schedule)
+}
+
+// @Summary Delete bonus schedule
+// @Description Delete a bonus schedule that is not in force yet
+// @Tags Referrals
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} fiber.Map "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Schedule already in force"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/bonus-schedules/{schedule_id} [delete]
+func (c *ReferralController) DeleteBonusSchedule(ctx *fiber.Ctx) error {
+	paramScheduleID := ctx.Params("schedule_id")
+	c.logger.Infof("schedule ID: %s", paramScheduleID)
+
+	if err := c.referral_service.DeleteBonusSchedule(ctx.Context(), paramScheduleID); err != nil {
+		c.logger.Errorf("error deleting bonus schedule: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(fiber.Map{
+		"message": "Bonus schedule deleted successfully",
+	})
+}
@@ -1,7 +1,6 @@
 package referral_controller
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -9,11 +8,6 @@ import (
 	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
 	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
-	"github.com/root9464/Go_GamlerDefi/src/packages/utils"
-)
-
-const (
-	url = "https://serv.gamler.atma-dev.ru/referral"
 )
 
 // @Summary checking the referrer
@@ -39,8 +33,13 @@ func (c *ReferralController) PrecheckoutReferrer(ctx *fiber.Ctx) error {
 	userID := strings.TrimPrefix(paramUserID, "user_id=")
 	c.logger.Infof("сleaned User ID: %s", userID)
 
-	c.logger.Infof("get referral URL: %s", fmt.Sprintf("%s/referrer/%s", url, userID))
-	referral, err := utils.Get[referral_dto.ReferrerResponse](fmt.Sprintf("%s/referrer/%s", url, userID))
+	referrerID, err := strconv.Atoi(userID)
+	if err != nil {
+		c.logger.Errorf("error converting user ID: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	referral, err := c.referral_directory.GetReferrer(ctx.Context(), referrerID)
 	if err != nil {
 		c.logger.Errorf("error fetching referrer data: %v", err)
 		return errors.NewError(404, err.Error())
@@ -58,8 +57,12 @@ func (c *ReferralController) PrecheckoutReferrer(ctx *fiber.Ctx) error {
 // @Tags Referrals
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param author_id path int true "Author ID, must match the token unless it belongs to an admin"
 // @Success 200 {object} referral_dto.PaymentOrder "Success response"
 // @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Orders of another author"
 // @Failure 404 {object} errors.MapError "Not found"
 // @Failure 500 {object} errors.MapError "Internal server error"
 // @Router /api/referral/debt/{author_id}/{referrer_id} [get]
@@ -73,10 +76,9 @@ func (c *ReferralController) GetDebtAuthor(ctx *fiber.Ctx) error {
 		})
 	}
 
-	authorID, err := strconv.Atoi(paramAuthorID)
+	authorID, err := c.callerAuthorID(ctx, paramAuthorID)
 	if err != nil {
-		c.logger.Errorf("error converting author ID: %v", err)
-		return errors.NewError(400, err.Error())
+		return err
 	}
 
 	c.logger.Infof("author ID to int: %d", authorID)
@@ -101,27 +103,42 @@ func (c *ReferralController) GetDebtAuthor(ctx *fiber.Ctx) error {
 // @Tags Referrals
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer access token, users need the wallet verified with ton_proof"
+// @Param Wallet-Address header string false "Wallet paying the order, required for admins only"
 // @Param order_id path string true "Order ID"
 // @Success 200 {object} referral_dto.CellResponse "Success response"
 // @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 402 {object} errors.MapError "Insufficient funds"
+// @Failure 403 {object} errors.MapError "Order of another author or wallet not verified"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Order is already awaiting payment, paid, cancelled or expired"
 // @Failure 500 {object} errors.MapError "Internal server error"
 // @Router /api/referral/payment-orders/pay [get]
 func (c *ReferralController) PayDebtAuthor(ctx *fiber.Ctx) error {
 	paramOrderID := ctx.Query("order_id")
-	walletAddress := ctx.Get("Wallet-Address")
 	c.logger.Infof("order ID: %s", paramOrderID)
-	c.logger.Infof("wallet address: %s", walletAddress)
 
-	if paramOrderID == "" || walletAddress == "" {
+	if paramOrderID == "" {
 		return ctx.Status(400).JSON(fiber.Map{
-			"message": "Wallet address is required",
+			"message": "Order ID is required",
 		})
 	}
 
+	walletAddress, err := c.callerWallet(ctx)
+	if err != nil {
+		return err
+	}
+	c.logger.Infof("wallet address: %s", walletAddress)
+
+	if err := c.authorizePaymentOrder(ctx, paramOrderID); err != nil {
+		return err
+	}
+
 	cell, err := c.referral_service.PayPaymentOrder(ctx.Context(), paramOrderID, walletAddress)
 	if err != nil {
 		c.logger.Errorf("error paying payment order: %v", err)
-		return errors.NewError(500, err.Error())
+		return err
 	}
 
 	return ctx.Status(200).JSON(referral_dto.CellResponse{
@@ -130,44 +147,47 @@ func (c *ReferralController) PayDebtAuthor(ctx *fiber.Ctx) error {
 }
 
 // @Summary Pay all debt from author to referrer
-// @Description Paying all debt from author to referrer
+// @Description Paying all debt from author to referrer, the returned bundle ID settles every included order once validated
 // @Tags Referrals
 // @Accept json
 // @Produce json
-// @Param author_id query int true "Author ID"
+// @Param Authorization header string true "Bearer access token, users need the wallet verified with ton_proof"
+// @Param Wallet-Address header string false "Wallet paying the orders, required for admins only"
+// @Param author_id query int false "Author ID, defaults to the token subject, only admins may name another author"
 // @Success 200 {object} referral_dto.CellResponse
 // @Failure 400 {object} errors.MapError
+// @Failure 401 {object} errors.MapError
+// @Failure 402 {object} errors.MapError
+// @Failure 403 {object} errors.MapError
 // @Failure 404 {object} errors.MapError
+// @Failure 409 {object} errors.MapError
 // @Failure 500 {object} errors.MapError
 // @Router /api/referral/payment-orders/pay-all [get]
 func (c *ReferralController) PayAllDebtAuthor(ctx *fiber.Ctx) error {
 	paramAuthorID := ctx.Query("author_id")
-	walletAddress := ctx.Get("Wallet-Address")
-
 	c.logger.Infof("author ID: %s", paramAuthorID)
-	c.logger.Infof("wallet address: %s", walletAddress)
 
-	if paramAuthorID == "" || walletAddress == "" {
-		return ctx.Status(400).JSON(fiber.Map{
-			"message": "Author ID or wallet address is required",
-		})
+	walletAddress, err := c.callerWallet(ctx)
+	if err != nil {
+		return err
 	}
+	c.logger.Infof("wallet address: %s", walletAddress)
 
-	authorID, err := strconv.Atoi(paramAuthorID)
+	authorID, err := c.callerAuthorID(ctx, paramAuthorID)
 	if err != nil {
-		c.logger.Errorf("error converting author ID: %v", err)
-		return errors.NewError(400, err.Error())
+		return err
 	}
 
 	c.logger.Infof("author ID to int: %d", authorID)
-	cell, err := c.referral_service.PayAllPaymentOrders(ctx.Context(), authorID, walletAddress)
+	cell, bundleID, err := c.referral_service.PayAllPaymentOrders(ctx.Context(), authorID, walletAddress)
 	if err != nil {
 		c.logger.Errorf("error paying all payment orders: %v", err)
-		return errors.NewError(500, err.Error())
+		return err
 	}
 
 	return ctx.Status(200).JSON(referral_dto.CellResponse{
-		Cell: cell,
+		Cell:     cell,
+		BundleID: bundleID,
 	})
 }
 
@@ -210,20 +230,26 @@ func (c *ReferralController) ValidateInvitationConditions(ctx *fiber.Ctx) error
 	})
 }
 
+// @Summary Calculate author debt
+// @Description Sum the unpaid payment orders of the author
+// @Tags Referrals
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param author_id query int false "Author ID, defaults to the token subject, only admins may name another author"
+// @Success 200 {number} number "Debt of the author"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 403 {object} errors.MapError "Debt of another author"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/payment-orders/calculate-debt [get]
 func (c *ReferralController) GetCalculateAuthorDebt(ctx *fiber.Ctx) error {
 	paramAuthorID := ctx.Query("author_id")
 	c.logger.Infof("author ID: %s", paramAuthorID)
 
-	if paramAuthorID == "" {
-		return ctx.Status(400).JSON(fiber.Map{
-			"message": "Author ID is required",
-		})
-	}
-
-	authorID, err := strconv.Atoi(paramAuthorID)
+	authorID, err := c.callerAuthorID(ctx, paramAuthorID)
 	if err != nil {
-		c.logger.Errorf("error converting author ID: %v", err)
-		return errors.NewError(400, err.Error())
+		return err
 	}
 
 	debt, err := c.referral_service.CalculateAuthorDebt(ctx.Context(), authorID)
@@ -3,6 +3,7 @@ package referral_controller
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
 	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
 	referral_service "github.com/root9464/Go_GamlerDefi/src/modules/referral/service"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
@@ -19,6 +20,19 @@ type IReferralController interface {
 	ValidateInvitationConditions(c *fiber.Ctx) error
 	AddTrHashToPaymentOrder(c *fiber.Ctx) error
 	GetCalculateAuthorDebt(c *fiber.Ctx) error
+	GetPayout(c *fiber.Ctx) error
+	ResendPayout(c *fiber.Ctx) error
+
+	CreateBonusSchedule(c *fiber.Ctx) error
+	GetBonusSchedules(c *fiber.Ctx) error
+	GetBonusSchedule(c *fiber.Ctx) error
+	UpdateBonusSchedule(c *fiber.Ctx) error
+	DeleteBonusSchedule(c *fiber.Ctx) error
+
+	GetLeaderProgram(c *fiber.Ctx) error
+	GetLeaderProgramVersion(c *fiber.Ctx) error
+	UpsertLeaderProgram(c *fiber.Ctx) error
+	DeleteLeaderProgram(c *fiber.Ctx) error
 }
 
 type ReferralController struct {
@@ -27,16 +41,19 @@ type ReferralController struct {
 
 	referral_service    referral_service.IReferralService
 	referral_repository referral_repository.IReferralRepository
+	referral_directory  referral_directory.IReferrerDirectory
 }
 
 func NewReferralController(
 	logger *logger.Logger, validator *validator.Validate,
 	referral_service referral_service.IReferralService, referral_repository referral_repository.IReferralRepository,
+	referral_directory referral_directory.IReferrerDirectory,
 ) IReferralController {
 	return &ReferralController{
 		logger:              logger,
 		validator:           validator,
 		referral_service:    referral_service,
 		referral_repository: referral_repository,
+		referral_directory:  referral_directory,
 	}
 }
@@ -0,0 +1,165 @@
+package referral_controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// @Summary Get leader program
+// @Description Get the referral program override of a leader
+// @Tags Referrals
+// @Produce json
+// @Param leader_id path int true "Leader ID"
+// @Success 200 {object} referral_dto.LeaderProgram "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/leader-programs/{leader_id} [get]
+func (c *ReferralController) GetLeaderProgram(ctx *fiber.Ctx) error {
+	paramLeaderID := ctx.Params("leader_id")
+	c.logger.Infof("leader ID: %s", paramLeaderID)
+
+	leaderID, err := strconv.Atoi(paramLeaderID)
+	if err != nil {
+		c.logger.Errorf("error converting leader ID: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	program, err := c.referral_repository.GetLeaderProgram(ctx.Context(), leaderID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "leader program not found")
+	}
+	if err != nil {
+		c.logger.Errorf("error getting leader program: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	programDTO, err := referral_adapters.CreateLeaderProgramFromModel(program)
+	if err != nil {
+		c.logger.Errorf("error converting leader program to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(programDTO)
+}
+
+// @Summary Get leader program version
+// @Description Get a stored version of the leader program, orders reference it by program_version
+// @Tags Referrals
+// @Produce json
+// @Param leader_id path int true "Leader ID"
+// @Param version path int true "Program version"
+// @Success 200 {object} referral_dto.LeaderProgram "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/leader-programs/{leader_id}/versions/{version} [get]
+func (c *ReferralController) GetLeaderProgramVersion(ctx *fiber.Ctx) error {
+	paramLeaderID := ctx.Params("leader_id")
+	paramVersion := ctx.Params("version")
+	c.logger.Infof("leader ID: %s, version: %s", paramLeaderID, paramVersion)
+
+	leaderID, err := strconv.Atoi(paramLeaderID)
+	if err != nil {
+		c.logger.Errorf("error converting leader ID: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	version, err := strconv.Atoi(paramVersion)
+	if err != nil {
+		c.logger.Errorf("error converting version: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	program, err := c.referral_repository.GetLeaderProgramVersion(ctx.Context(), leaderID, version)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "leader program version not found")
+	}
+	if err != nil {
+		c.logger.Errorf("error getting leader program version: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	programDTO, err := referral_adapters.CreateLeaderProgramFromModel(program)
+	if err != nil {
+		c.logger.Errorf("error converting leader program to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(programDTO)
+}
+
+// @Summary Create or update leader program
+// @Description Set custom rates, depth or debt limit of a leader, every change is stored as a new version
+// @Tags Referrals
+// @Accept json
+// @Produce json
+// @Param leader_id path int true "Leader ID"
+// @Param request body referral_dto.LeaderProgramRequest true "Leader program data"
+// @Success 200 {object} referral_dto.LeaderProgram "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/leader-programs/{leader_id} [put]
+func (c *ReferralController) UpsertLeaderProgram(ctx *fiber.Ctx) error {
+	paramLeaderID := ctx.Params("leader_id")
+	c.logger.Infof("leader ID: %s", paramLeaderID)
+
+	leaderID, err := strconv.Atoi(paramLeaderID)
+	if err != nil {
+		c.logger.Errorf("error converting leader ID: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	var dto referral_dto.LeaderProgramRequest
+	if err := ctx.BodyParser(&dto); err != nil {
+		c.logger.Errorf("error parsing request body: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+	if err := c.validator.Struct(dto); err != nil {
+		c.logger.Errorf("validation error: %s", err.Error())
+		return errors.NewError(400, err.Error())
+	}
+
+	program, err := c.referral_service.UpsertLeaderProgram(ctx.Context(), leaderID, dto)
+	if err != nil {
+		c.logger.Errorf("error upserting leader program: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(program)
+}
+
+// @Summary Delete leader program
+// @Description Reset a leader to the platform referral program, earlier versions are kept
+// @Tags Referrals
+// @Produce json
+// @Param leader_id path int true "Leader ID"
+// @Success 200 {object} fiber.Map "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/leader-programs/{leader_id} [delete]
+func (c *ReferralController) DeleteLeaderProgram(ctx *fiber.Ctx) error {
+	paramLeaderID := ctx.Params("leader_id")
+	c.logger.Infof("leader ID: %s", paramLeaderID)
+
+	leaderID, err := strconv.Atoi(paramLeaderID)
+	if err != nil {
+		c.logger.Errorf("error converting leader ID: %v", err)
+		return errors.NewError(400, err.Error())
+	}
+
+	if err := c.referral_service.DeleteLeaderProgram(ctx.Context(), leaderID); err != nil {
+		c.logger.Errorf("error deleting leader program: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(fiber.Map{
+		"message": "Leader program deleted successfully",
+	})
+}
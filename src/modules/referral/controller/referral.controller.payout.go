@@ -0,0 +1,93 @@
+package referral_controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// @Summary Get payout
+// @Description Get the status of a queued platform accrual
+// @Tags Referrals
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param payout_id path string true "Payout ID"
+// @Success 200 {object} referral_dto.Payout "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Unauthorized"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/payouts/{payout_id} [get]
+func (c *ReferralController) GetPayout(ctx *fiber.Ctx) error {
+	paramPayoutID := ctx.Params("payout_id")
+	c.logger.Infof("payout ID: %s", paramPayoutID)
+
+	payoutID, err := bson.ObjectIDFromHex(paramPayoutID)
+	if err != nil {
+		c.logger.Errorf("invalid payout ID: %v", err)
+		return errors.NewError(400, "invalid payout ID")
+	}
+
+	payout, err := c.referral_repository.GetPayoutByID(ctx.Context(), payoutID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "payout not found")
+	}
+	if err != nil {
+		c.logger.Errorf("error getting payout: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	payoutDTO, err := referral_adapters.CreatePayoutFromModel(payout)
+	if err != nil {
+		c.logger.Errorf("error converting payout to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(payoutDTO)
+}
+
+// @Summary Resend payout
+// @Description Return a failed platform accrual to the queue. Resend only after checking that the failed transfer did not leave the wallet
+// @Tags Referrals
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param payout_id path string true "Payout ID"
+// @Success 200 {object} referral_dto.Payout "Success response"
+// @Failure 400 {object} errors.MapError "Validation error"
+// @Failure 401 {object} errors.MapError "Unauthorized"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 409 {object} errors.MapError "Payout is not failed"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/referral/payouts/{payout_id}/resend [post]
+func (c *ReferralController) ResendPayout(ctx *fiber.Ctx) error {
+	paramPayoutID := ctx.Params("payout_id")
+	c.logger.Infof("payout ID: %s", paramPayoutID)
+
+	payoutID, err := bson.ObjectIDFromHex(paramPayoutID)
+	if err != nil {
+		c.logger.Errorf("invalid payout ID: %v", err)
+		return errors.NewError(400, "invalid payout ID")
+	}
+
+	payout, err := c.referral_repository.RequeuePayout(ctx.Context(), payoutID)
+	if err == mongo.ErrNoDocuments {
+		if _, err := c.referral_repository.GetPayoutByID(ctx.Context(), payoutID); err == mongo.ErrNoDocuments {
+			return errors.NewError(404, "payout not found")
+		}
+		return errors.NewError(409, "only failed payouts can be resent")
+	}
+	if err != nil {
+		c.logger.Errorf("error requeueing payout: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	payoutDTO, err := referral_adapters.CreatePayoutFromModel(payout)
+	if err != nil {
+		c.logger.Errorf("error converting payout to DTO: %v", err)
+		return errors.NewError(500, err.Error())
+	}
+
+	return ctx.Status(200).JSON(payoutDTO)
+}
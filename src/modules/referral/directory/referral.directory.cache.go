@@ -0,0 +1,70 @@
+package referral_directory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+)
+
+type cacheEntry struct {
+	referrer  referral_dto.ReferrerResponse
+	expiresAt time.Time
+}
+
+// CachedDirectory keeps successful lookups of the wrapped directory for `ttl`.
+// Errors are never cached so a flaky upstream does not poison the cache.
+type CachedDirectory struct {
+	directory IReferrerDirectory
+	ttl       time.Duration
+
+	mu        sync.RWMutex
+	entries   map[int]cacheEntry
+	lastPurge time.Time
+}
+
+func NewCachedDirectory(directory IReferrerDirectory, ttl time.Duration) IReferrerDirectory {
+	return &CachedDirectory{
+		directory: directory,
+		ttl:       ttl,
+		entries:   make(map[int]cacheEntry),
+		lastPurge: time.Now(),
+	}
+}
+
+func (d *CachedDirectory) GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error) {
+	d.mu.RLock()
+	entry, ok := d.entries[userID]
+	d.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		referrer := entry.referrer
+		return &referrer, nil
+	}
+
+	referrer, err := d.directory.GetReferrer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.set(userID, *referrer)
+	return referrer, nil
+}
+
+func (d *CachedDirectory) set(userID int, referrer referral_dto.ReferrerResponse) {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastPurge) > d.ttl {
+		for id, entry := range d.entries {
+			if now.After(entry.expiresAt) {
+				delete(d.entries, id)
+			}
+		}
+		d.lastPurge = now
+	}
+
+	d.entries[userID] = cacheEntry{referrer: referrer, expiresAt: now.Add(d.ttl)}
+}
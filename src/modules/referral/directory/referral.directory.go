@@ -0,0 +1,16 @@
+package referral_directory
+
+import (
+	"context"
+	"errors"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+)
+
+var ErrReferrerNotFound = errors.New("referrer not found")
+
+// IReferrerDirectory is the source of referral relations between users.
+// Implementations return ErrReferrerNotFound for unknown users.
+type IReferrerDirectory interface {
+	GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error)
+}
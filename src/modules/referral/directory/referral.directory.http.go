@@ -0,0 +1,64 @@
+package referral_directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/root9464/Go_GamlerDefi/src/packages/utils"
+)
+
+const retryDelay = 200 * time.Millisecond
+
+type HttpDirectory struct {
+	logger  *logger.Logger
+	baseURL string
+	timeout time.Duration
+	retries int
+}
+
+func NewHttpDirectory(logger *logger.Logger, baseURL string, timeout time.Duration, retries int) IReferrerDirectory {
+	return &HttpDirectory{
+		logger:  logger,
+		baseURL: baseURL,
+		timeout: timeout,
+		retries: retries,
+	}
+}
+
+// GetReferrer retries network failures and 5xx answers, client errors are returned at once.
+func (d *HttpDirectory) GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error) {
+	url := fmt.Sprintf("%s/referrer/%d", d.baseURL, userID)
+
+	var lastErr error
+	for attempt := 0; attempt <= d.retries; attempt++ {
+		if attempt > 0 {
+			d.logger.Warnf("retrying referrer request for user %d, attempt %d: %v", userID, attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retryDelay * time.Duration(attempt)):
+			}
+		}
+
+		resp, err := utils.GetWithTimeout[referral_dto.ReferrerResponse](url, d.timeout)
+		if err == nil {
+			return &resp, nil
+		}
+
+		var apiErr *utils.APIError
+		if errors.As(err, &apiErr) && apiErr.Status == 404 {
+			return nil, ErrReferrerNotFound
+		}
+		if errors.As(err, &apiErr) && apiErr.Status < 500 {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	d.logger.Errorf("failed to fetch referrer for user %d: %v", userID, lastErr)
+	return nil, lastErr
+}
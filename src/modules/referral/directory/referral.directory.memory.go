@@ -0,0 +1,46 @@
+package referral_directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+)
+
+type MemoryDirectory struct {
+	referrers map[int]referral_dto.ReferrerResponse
+}
+
+func NewMemoryDirectory(referrers []referral_dto.ReferrerResponse) IReferrerDirectory {
+	directory := &MemoryDirectory{referrers: make(map[int]referral_dto.ReferrerResponse, len(referrers))}
+	for _, referrer := range referrers {
+		directory.referrers[referrer.UserID] = referrer
+	}
+	return directory
+}
+
+// NewFixtureDirectory loads a JSON array of referrers in the format of the
+// production directory, used to run the bonus engine without it.
+func NewFixtureDirectory(path string) (IReferrerDirectory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referrer fixture: %w", err)
+	}
+
+	var referrers []referral_dto.ReferrerResponse
+	if err := json.Unmarshal(data, &referrers); err != nil {
+		return nil, fmt.Errorf("failed to decode referrer fixture: %w", err)
+	}
+
+	return NewMemoryDirectory(referrers), nil
+}
+
+func (d *MemoryDirectory) GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error) {
+	referrer, ok := d.referrers[userID]
+	if !ok {
+		return nil, ErrReferrerNotFound
+	}
+	return &referrer, nil
+}
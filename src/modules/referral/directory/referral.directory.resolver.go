@@ -0,0 +1,53 @@
+package referral_directory
+
+import (
+	"context"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+)
+
+type resolved struct {
+	referrer *referral_dto.ReferrerResponse
+	err      error
+}
+
+// Resolver memoizes lookups for the lifetime of a single request, so every
+// user of the chain is fetched at most once. Not safe for concurrent use.
+type Resolver struct {
+	directory IReferrerDirectory
+	memo      map[int]resolved
+}
+
+func NewResolver(directory IReferrerDirectory) *Resolver {
+	return &Resolver{directory: directory, memo: make(map[int]resolved)}
+}
+
+func (r *Resolver) GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error) {
+	if result, ok := r.memo[userID]; ok {
+		return result.referrer, result.err
+	}
+
+	referrer, err := r.directory.GetReferrer(ctx, userID)
+	r.memo[userID] = resolved{referrer: referrer, err: err}
+	return referrer, err
+}
+
+// ResolveChain returns `userID` followed by its ancestors, at most `depth` users.
+// The chain ends early at a user without a referrer. On error the already
+// resolved prefix is returned together with the error.
+func (r *Resolver) ResolveChain(ctx context.Context, userID int, depth int) ([]referral_dto.ReferrerResponse, error) {
+	chain := make([]referral_dto.ReferrerResponse, 0, depth)
+
+	currentID := userID
+	for len(chain) < depth && currentID != 0 {
+		referrer, err := r.GetReferrer(ctx, currentID)
+		if err != nil {
+			return chain, err
+		}
+
+		chain = append(chain, *referrer)
+		currentID = referrer.ReferrerID
+	}
+
+	return chain, nil
+}
@@ -45,6 +45,31 @@ type ReferralProcessRequest struct {
 	PaymentType PaymentType `json:"payment_type" validate:"required,oneof=accrual_platform leader_accrual"`
 }
 
+// ReferralProcessResult represents the outcome of a referral processing request
+// @swagger:model ReferralProcessResult
+type ReferralProcessResult struct {
+	// Result message
+	// example: Referral bonuses sent successfully
+	Message string `json:"message"`
+
+	// ID of the payout queued for platform accruals
+	// required: false
+	// example: 6823dc5bcb80d8ea88f9b32b
+	PayoutID string `json:"payout_id,omitempty"`
+}
+
+// PaymentOrderStatus defines the lifecycle status of a payment order
+// @swagger:enum PaymentOrderStatus
+type PaymentOrderStatus string
+
+const (
+	PaymentOrderStatusOpen            PaymentOrderStatus = "open"
+	PaymentOrderStatusAwaitingPayment PaymentOrderStatus = "awaiting_payment"
+	PaymentOrderStatusPaid            PaymentOrderStatus = "paid"
+	PaymentOrderStatusCancelled       PaymentOrderStatus = "cancelled"
+	PaymentOrderStatusExpired         PaymentOrderStatus = "expired"
+)
+
 // PaymentOrder represents a payment order
 // @swagger:model PaymentOrder
 type PaymentOrder struct {
@@ -98,6 +123,53 @@ type PaymentOrder struct {
 	// required: false
 	// example: 1e95861ef87af4c75811a0e3aaebd0ef9044bbc84e31425619405b8158d2795c
 	TrHash string `json:"tr_hash,omitempty"`
+
+	// Version of the platform bonus schedule in force when the order was calculated
+	// required: false
+	// example: 1
+	ScheduleVersion int `json:"schedule_version"`
+
+	// Source of the rates used to calculate the order
+	// required: false
+	// enum: platform,leader
+	// example: platform
+	ScheduleSource string `json:"schedule_source,omitempty"`
+
+	// Version of the leader program applied to the order, empty without one
+	// required: false
+	// example: 2
+	ProgramVersion int `json:"program_version,omitempty"`
+
+	// Lifecycle status of the order
+	// required: false
+	// enum: open,awaiting_payment,paid,cancelled,expired
+	// example: open
+	Status PaymentOrderStatus `json:"status"`
+
+	// Status changes of the order, oldest first
+	// required: false
+	StatusHistory []StatusTransition `json:"status_history,omitempty"`
+
+	// Pay-all bundles the order was included in
+	// required: false
+	// example: ["6823dc5bcb80d8ea88f9b32b"]
+	BundleIDs []string `json:"bundle_ids,omitempty"`
+}
+
+// StatusTransition represents a single status change of a payment order
+// @swagger:model StatusTransition
+type StatusTransition struct {
+	// Previous status, empty for the creation of the order
+	// example: open
+	From PaymentOrderStatus `json:"from,omitempty"`
+
+	// New status
+	// example: awaiting_payment
+	To PaymentOrderStatus `json:"to"`
+
+	// Time of the change
+	// example: 1715731200
+	At int64 `json:"at"`
 }
 
 // LevelRequest represents a level request
@@ -134,6 +206,11 @@ type CellResponse struct {
 	// required: true
 	// example: 0QC3PUCoxBdLfOmO8xFQ84TGFPQUatxvvRsSAODKEvjbb4OS
 	Cell string `json:"cell"`
+
+	// ID of the bundle of orders paid by the cell, pass it as payment_bundle_id to the validation
+	// required: false
+	// example: 6823dc5bcb80d8ea88f9b32b
+	BundleID string `json:"bundle_id,omitempty"`
 }
 
 // ValidateInvitationConditionsResponse represents a validate invitation conditions response
@@ -150,11 +227,202 @@ type ValidateInvitationConditionsResponse struct {
 type AddTrHashToPaymentOrderRequest struct {
 	// ID of the payment order
 	// required: true
-	// example: 12345
-	OrderID string `json:"order_id"`
+	// example: 6823dc5bcb80d8ea88f9b32b
+	OrderID string `json:"order_id" validate:"required"`
 
 	// Transaction hash
 	// required: true
 	// example: 1e95861ef87af4c75811a0e3aaebd0ef9044bbc84e31425619405b8158d2795c
-	TrHash string `json:"tr_hash"`
+	TrHash string `json:"tr_hash" validate:"required"`
+}
+
+// BonusScheduleRequest represents a create or update bonus schedule request
+// @swagger:model BonusScheduleRequest
+type BonusScheduleRequest struct {
+	// Rates per referral level, level numbers must start at 0 and go without gaps
+	// required: true
+	// example: [{"level_number": 0, "rate": 0.2}, {"level_number": 1, "rate": 0.02}]
+	Levels []ScheduleLevel `json:"levels" validate:"required,min=1,dive"`
+
+	// Unix time from which the schedule is in force
+	// required: true
+	// example: 1715731200
+	EffectiveFrom int64 `json:"effective_from" validate:"required"`
+}
+
+// BonusSchedule represents a versioned bonus schedule
+// @swagger:model BonusSchedule
+type BonusSchedule struct {
+	// ID of the bonus schedule
+	// example: 6826ac79ff2f0eb00db5fa1d
+	ID string `json:"id"`
+
+	// Version of the bonus schedule
+	// example: 1
+	Version int `json:"version"`
+
+	// Rates per referral level
+	// example: [{"level_number": 0, "rate": 0.2}, {"level_number": 1, "rate": 0.02}]
+	Levels []ScheduleLevel `json:"levels"`
+
+	// Unix time from which the schedule is in force
+	// example: 1715731200
+	EffectiveFrom int64 `json:"effective_from"`
+
+	// Date of creation
+	// example: 1715731200
+	CreatedAt int64 `json:"created_at"`
+
+	// Date of the last update
+	// example: 1715731200
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// ScheduleLevel represents a rate of a single referral level
+// @swagger:model ScheduleLevel
+type ScheduleLevel struct {
+	// Level number
+	// required: true
+	// minimum: 0
+	// example: 0
+	LevelNumber int `json:"level_number" validate:"min=0"`
+
+	// Rate of the level
+	// required: true
+	// example: 0.2
+	Rate decimal.Decimal `json:"rate"`
+}
+
+// LeaderProgramRequest represents a create or update leader program request
+// @swagger:model LeaderProgramRequest
+type LeaderProgramRequest struct {
+	// Number of referral levels paid by the leader, 0 uses every level of the rates
+	// required: false
+	// minimum: 0
+	// example: 1
+	Depth int `json:"depth" validate:"min=0"`
+
+	// Custom rates per referral level, empty uses the platform schedule
+	// required: false
+	// example: [{"level_number": 0, "rate": 0.25}]
+	Levels []ScheduleLevel `json:"levels,omitempty" validate:"omitempty,dive"`
+
+	// Custom debt limit of the leader, empty uses the platform limit
+	// required: false
+	// example: 25
+	MaxDebt *decimal.Decimal `json:"max_debt,omitempty"`
+}
+
+// LeaderProgram represents a leader-level override of the referral program
+// @swagger:model LeaderProgram
+type LeaderProgram struct {
+	// ID of the leader program
+	// example: 6826ac79ff2f0eb00db5fa1d
+	ID string `json:"id"`
+
+	// ID of the leader
+	// example: 12345
+	LeaderID int `json:"leader_id"`
+
+	// Version of the leader program, increased on every change
+	// example: 1
+	Version int `json:"version"`
+
+	// Number of referral levels paid by the leader
+	// example: 1
+	Depth int `json:"depth"`
+
+	// Custom rates per referral level
+	// example: [{"level_number": 0, "rate": 0.25}]
+	Levels []ScheduleLevel `json:"levels,omitempty"`
+
+	// Custom debt limit of the leader
+	// example: 25
+	MaxDebt *decimal.Decimal `json:"max_debt,omitempty"`
+
+	// Set on the version that deleted the program
+	// example: false
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Date of creation
+	// example: 1715731200
+	CreatedAt int64 `json:"created_at"`
+
+	// Date of the last update
+	// example: 1715731200
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// PayoutStatus defines states of a platform payout
+// @swagger:enum PayoutStatus
+type PayoutStatus string
+
+const (
+	PayoutPending PayoutStatus = "pending"
+	PayoutSending PayoutStatus = "sending"
+	PayoutSent    PayoutStatus = "sent"
+	PayoutFailed  PayoutStatus = "failed"
+)
+
+// Payout represents a queued platform accrual
+// @swagger:model Payout
+type Payout struct {
+	// ID of the payout
+	// example: 6823dc5bcb80d8ea88f9b32b
+	ID string `json:"id"`
+
+	// ID of the referring user
+	// example: 12345
+	ReferrerID int `json:"referrer_id"`
+
+	// ID of the referred user
+	// example: 67890
+	ReferralID int `json:"referral_id"`
+
+	// Number of tickets of the accrual
+	// example: 5
+	TicketCount int `json:"ticket_count"`
+
+	// Total amount of the payout
+	// example: 150
+	TotalAmount decimal.Decimal `json:"total_amount"`
+
+	// Accruals per level
+	Levels []LevelRequest `json:"levels"`
+
+	// Version of the bonus schedule used for the payout
+	// example: 1
+	ScheduleVersion int `json:"schedule_version"`
+
+	// Status of the payout
+	// enum: pending,sending,sent,failed
+	// example: sent
+	Status PayoutStatus `json:"status"`
+
+	// Hash of the jetton transfer
+	// required: false
+	// example: te6ccgEBAQEAAgAAAA==
+	TxHash string `json:"tx_hash,omitempty"`
+
+	// ID of the batch the payout was sent with
+	// required: false
+	// example: 6823dc5bcb80d8ea88f9b32c
+	BatchID string `json:"batch_id,omitempty"`
+
+	// Reason of the failure
+	// required: false
+	// example: transaction execution failed
+	Error string `json:"error,omitempty"`
+
+	// Number of dispatch attempts
+	// example: 1
+	Attempts int `json:"attempts"`
+
+	// Creation timestamp
+	// example: 1672531200
+	CreatedAt int64 `json:"created_at"`
+
+	// Last update timestamp
+	// example: 1672531200
+	UpdatedAt int64 `json:"updated_at"`
 }
@@ -0,0 +1,120 @@
+package referral_helper
+
+import (
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// MergeJettonEntries sums the amounts sent to the same address. The jettons
+// dictionary is keyed by address, so duplicates would overwrite each other.
+// The order of first appearance is kept.
+func MergeJettonEntries(entries []JettonEntry) []JettonEntry {
+	merged := make([]JettonEntry, 0, len(entries))
+	index := make(map[string]int, len(entries))
+
+	for _, entry := range entries {
+		key := entry.Address.StringRaw()
+		if i, ok := index[key]; ok {
+			merged[i].Amount = merged[i].Amount.Add(entry.Amount)
+			continue
+		}
+
+		index[key] = len(merged)
+		merged = append(merged, entry)
+	}
+
+	return merged
+}
+
+// CellLimits bounds the cell tree of a message body: the number of distinct
+// cells, the data bits of all of them and the depth of the root.
+type CellLimits struct {
+	Cells int
+	Bits  int
+	Depth int
+}
+
+// Fits reports whether the cell tree of root stays within the limits.
+func (l CellLimits) Fits(root *cell.Cell) bool {
+	if int(root.Depth()) > l.Depth {
+		return false
+	}
+
+	cells, bits := 0, 0
+	seen := map[*cell.Cell]bool{}
+	stack := []*cell.Cell{root}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		cells++
+		bits += int(c.BitsSize())
+		if cells > l.Cells || bits > l.Bits {
+			return false
+		}
+		for i := 0; i < int(c.RefsNum()); i++ {
+			stack = append(stack, c.MustPeekRef(i))
+		}
+	}
+
+	return true
+}
+
+// PackJettonEntries cuts entries into chunks, one chunk per transfer message.
+// Each chunk takes as many entries as its built jettons dictionary fits into
+// limits. An entry whose dictionary does not fit alone is an error.
+func PackJettonEntries(entries []JettonEntry, limits CellLimits) ([][]JettonEntry, error) {
+	fits := func(chunk []JettonEntry) (bool, error) {
+		dict, err := createJettonsDictionary(chunk)
+		if err != nil {
+			return false, err
+		}
+		return limits.Fits(dict.AsCell()), nil
+	}
+
+	chunks := [][]JettonEntry{}
+	for start := 0; start < len(entries); {
+		ok, err := fits(entries[start : start+1])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("transfer to %s does not fit into a message", entries[start].Address)
+		}
+
+		// the chunk of `size` entries fits, the one of `limit` does not or runs past the end
+		size, limit := 1, 2
+		for start+limit <= len(entries) {
+			if ok, err = fits(entries[start : start+limit]); err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			size, limit = limit, limit*2
+		}
+		limit = min(limit, len(entries)-start+1)
+
+		for limit-size > 1 {
+			middle := (size + limit) / 2
+			if ok, err = fits(entries[start : start+middle]); err != nil {
+				return nil, err
+			}
+			if ok {
+				size = middle
+			} else {
+				limit = middle
+			}
+		}
+
+		chunks = append(chunks, entries[start:start+size])
+		start += size
+	}
+
+	return chunks, nil
+}
@@ -14,7 +14,7 @@ type JettonEntry struct {
 	Amount  decimal.Decimal
 }
 
-func (h *ReferralHelper) createJettonsDictionary(entries []JettonEntry) (*cell.Dictionary, error) {
+func createJettonsDictionary(entries []JettonEntry) (*cell.Dictionary, error) {
 	dict := cell.NewDict(267)
 	for _, entry := range entries {
 		coins := tlb.MustFromDecimal(entry.Amount.String(), 9).Nano().Uint64()
@@ -31,7 +31,7 @@ func (h *ReferralHelper) CellTransferJettonsFromLeader(dict []JettonEntry, amoun
 	h.logger.Infof("create cell transfer jettons from leader")
 	h.logger.Infof("create jettons dictionary: %v", dict)
 
-	dictionary, err := h.createJettonsDictionary(dict)
+	dictionary, err := createJettonsDictionary(dict)
 	if err != nil {
 		h.logger.Errorf("create jettons dictionary error: %s", err)
 		return cell.BeginCell().EndCell(), err
@@ -57,7 +57,7 @@ func (h *ReferralHelper) CellTransferJettonsFromPlatform(dict []JettonEntry) (*c
 	h.logger.Infof("create cell transfer jettons from platform")
 	h.logger.Infof("create jettons dictionary: %v", dict)
 
-	dictionary, err := h.createJettonsDictionary(dict)
+	dictionary, err := createJettonsDictionary(dict)
 	if err != nil {
 		h.logger.Errorf("create jettons dictionary error: %s", err)
 		return cell.BeginCell().EndCell(), err
@@ -1,19 +1,81 @@
 package referral_model
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+type PaymentOrderStatus string
+
+const (
+	PaymentOrderStatusOpen            PaymentOrderStatus = "open"
+	PaymentOrderStatusAwaitingPayment PaymentOrderStatus = "awaiting_payment"
+	PaymentOrderStatusPaid            PaymentOrderStatus = "paid"
+	PaymentOrderStatusCancelled       PaymentOrderStatus = "cancelled"
+	PaymentOrderStatusExpired         PaymentOrderStatus = "expired"
+)
+
+// PaymentOrderTransitions lists the statuses a payment order may move to from
+// each status. Paid, cancelled and expired orders are final. Orders awaiting
+// payment do not expire, their transaction may still be validated, they return
+// to open when it fails.
+var PaymentOrderTransitions = map[PaymentOrderStatus][]PaymentOrderStatus{
+	PaymentOrderStatusOpen: {
+		PaymentOrderStatusAwaitingPayment,
+		PaymentOrderStatusPaid,
+		PaymentOrderStatusCancelled,
+		PaymentOrderStatusExpired,
+	},
+	PaymentOrderStatusAwaitingPayment: {
+		PaymentOrderStatusPaid,
+		PaymentOrderStatusOpen,
+		PaymentOrderStatusCancelled,
+	},
+}
+
+// UnpaidPaymentOrderStatuses are the statuses that still count as debt of the leader.
+var UnpaidPaymentOrderStatuses = []PaymentOrderStatus{
+	PaymentOrderStatusOpen,
+	PaymentOrderStatusAwaitingPayment,
+}
+
+// PaymentOrder is the referral debt of a leader. Orders stored before statuses
+// were introduced have no status and are treated as open.
 type PaymentOrder struct {
-	ID          bson.ObjectID   `bson:"_id"`
-	LeaderID    int             `bson:"leader_id"`
-	ReferrerID  int             `bson:"referrer_id"`
-	ReferralID  int             `bson:"referral_id"`
-	TotalAmount bson.Decimal128 `bson:"total_amount"`
-	TicketCount int             `bson:"ticket_count"`
-	CreatedAt   int64           `bson:"created_at"`
-	TrHash      string          `bson:"tr_hash,omitempty"`
-	Levels      []Level         `bson:"levels"`
+	ID              bson.ObjectID      `bson:"_id"`
+	LeaderID        int                `bson:"leader_id"`
+	ReferrerID      int                `bson:"referrer_id"`
+	ReferralID      int                `bson:"referral_id"`
+	TotalAmount     bson.Decimal128    `bson:"total_amount"`
+	TicketCount     int                `bson:"ticket_count"`
+	CreatedAt       int64              `bson:"created_at"`
+	TrHash          string             `bson:"tr_hash,omitempty"`
+	ScheduleVersion int                `bson:"schedule_version"`
+	ScheduleSource  string             `bson:"schedule_source,omitempty"`
+	ProgramVersion  int                `bson:"program_version,omitempty"`
+	Levels          []Level            `bson:"levels"`
+	Status          PaymentOrderStatus `bson:"status,omitempty"`
+	StatusHistory   []StatusTransition `bson:"status_history,omitempty"`
+	// BundleIDs lists the pay-all transactions the order was included in.
+	BundleIDs []string `bson:"bundle_ids,omitempty"`
+	// IdempotencyKeys lists the referral requests accrued into the order.
+	IdempotencyKeys []string `bson:"idempotency_keys,omitempty"`
+}
+
+// StatusTransition records a single status change of a payment order.
+type StatusTransition struct {
+	From PaymentOrderStatus `bson:"from,omitempty"`
+	To   PaymentOrderStatus `bson:"to"`
+	At   int64              `bson:"at"`
+}
+
+// CurrentStatus returns the status of the order, treating orders without one as open.
+func (o PaymentOrder) CurrentStatus() PaymentOrderStatus {
+	if o.Status == "" {
+		return PaymentOrderStatusOpen
+	}
+	return o.Status
 }
 
 type Level struct {
@@ -22,3 +84,100 @@ type Level struct {
 	Amount      bson.Decimal128 `bson:"amount"`
 	Address     string          `bson:"address"`
 }
+
+// BonusSchedule is a versioned set of per-level referral rates.
+// The schedule with the latest EffectiveFrom not after the order time is in force.
+type BonusSchedule struct {
+	ID            bson.ObjectID   `bson:"_id"`
+	Version       int             `bson:"version"`
+	Levels        []ScheduleLevel `bson:"levels"`
+	EffectiveFrom int64           `bson:"effective_from"`
+	CreatedAt     int64           `bson:"created_at"`
+	UpdatedAt     int64           `bson:"updated_at"`
+}
+
+type ScheduleLevel struct {
+	LevelNumber int             `bson:"level_number"`
+	Rate        bson.Decimal128 `bson:"rate"`
+}
+
+// LeaderProgram overrides the platform referral rules for a single leader.
+// Empty Levels fall back to the platform schedule, zero Depth uses every level
+// of the rates and nil MaxDebt falls back to the platform debt limit.
+// Every change is stored as a new version and never modified, so orders stay
+// auditable. A delete is stored as a Deleted version, the latest version of the
+// leader is in force.
+type LeaderProgram struct {
+	ID        bson.ObjectID    `bson:"_id"`
+	LeaderID  int              `bson:"leader_id"`
+	Version   int              `bson:"version"`
+	Depth     int              `bson:"depth"`
+	Levels    []ScheduleLevel  `bson:"levels,omitempty"`
+	MaxDebt   *bson.Decimal128 `bson:"max_debt,omitempty"`
+	Deleted   bool             `bson:"deleted,omitempty"`
+	CreatedAt int64            `bson:"created_at"`
+	UpdatedAt int64            `bson:"updated_at"`
+}
+
+type IdempotencyStatus string
+
+const (
+	IdempotencyProcessing IdempotencyStatus = "processing"
+	IdempotencyCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord stores the outcome of a referral processing request under
+// its idempotency key, so a retried request is answered without re-processing.
+type IdempotencyRecord struct {
+	Key         string            `bson:"_id"`
+	Fingerprint string            `bson:"fingerprint"`
+	Status      IdempotencyStatus `bson:"status"`
+	Result      IdempotencyResult `bson:"result"`
+	CreatedAt   int64             `bson:"created_at"`
+	UpdatedAt   int64             `bson:"updated_at"`
+	// LeaseUntil is when a processing claim is considered stale and may be
+	// taken over by a retry with the same payload.
+	LeaseUntil int64 `bson:"lease_until"`
+	// Attempts counts the claims of the key, a claim taken over from a stale
+	// one may find the payout or order its predecessor already stored.
+	Attempts int `bson:"attempts"`
+	// ExpiresAt is a date so the TTL index can drop expired keys.
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type IdempotencyResult struct {
+	StatusCode int    `bson:"status_code"`
+	Message    string `bson:"message"`
+	PayoutID   string `bson:"payout_id,omitempty"`
+}
+
+type PayoutStatus string
+
+const (
+	PayoutStatusPending PayoutStatus = "pending"
+	PayoutStatusSending PayoutStatus = "sending"
+	PayoutStatusSent    PayoutStatus = "sent"
+	PayoutStatusFailed  PayoutStatus = "failed"
+)
+
+// Payout is an outbox entry of a platform accrual. The dispatcher claims
+// pending payouts with a lease, sends the jetton transfer and stores the result.
+type Payout struct {
+	ID              bson.ObjectID   `bson:"_id"`
+	ReferrerID      int             `bson:"referrer_id"`
+	ReferralID      int             `bson:"referral_id"`
+	TicketCount     int             `bson:"ticket_count"`
+	TotalAmount     bson.Decimal128 `bson:"total_amount"`
+	Levels          []Level         `bson:"levels"`
+	ScheduleVersion int             `bson:"schedule_version"`
+	Status          PayoutStatus    `bson:"status"`
+	TxHash          string          `bson:"tx_hash,omitempty"`
+	BatchID         string          `bson:"batch_id,omitempty"`
+	Error           string          `bson:"error,omitempty"`
+	Attempts        int             `bson:"attempts"`
+	LeaseUntil      int64           `bson:"lease_until"`
+	CreatedAt       int64           `bson:"created_at"`
+	UpdatedAt       int64           `bson:"updated_at"`
+	// IdempotencyKey is the key of the referral request queueing the payout.
+	IdempotencyKey string `bson:"idempotency_key,omitempty"`
+}
@@ -1,19 +1,35 @@
 package referral_module
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/root9464/Go_GamlerDefi/src/config"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
 	referral_controller "github.com/root9464/Go_GamlerDefi/src/modules/referral/controller"
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
 	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
 	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
 	referral_service "github.com/root9464/Go_GamlerDefi/src/modules/referral/service"
+	referral_worker "github.com/root9464/Go_GamlerDefi/src/modules/referral/worker"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/root9464/Go_GamlerDefi/src/packages/middleware"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
 	"github.com/tonkeeper/tonapi-go"
 	"github.com/xssnick/tonutils-go/ton"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+const (
+	defaultDirectoryURL     = "https://serv.gamler.online/referral"
+	defaultDirectoryTimeout = 10 * time.Second
+	defaultDirectoryTTL     = time.Minute
+	defaultPayoutInterval   = 5 * time.Second
+	defaultBatchInterval    = time.Minute
+)
+
 type ReferralModule struct {
 	config    *config.Config
 	logger    *logger.Logger
@@ -27,32 +43,38 @@ type ReferralModule struct {
 	referral_service    referral_service.IReferralService
 	refferal_helper     referral_helper.IReferralHelper
 	referral_repository referral_repository.IReferralRepository
+	referral_directory  referral_directory.IReferrerDirectory
+	payout_dispatcher   *referral_worker.PayoutDispatcher
+	order_expirer       *referral_worker.PaymentOrderExpirer
+	admin_middleware    *admin_middleware.Middleware
 }
 
 func NewReferralModule(
 	config *config.Config, logger *logger.Logger, validator *validator.Validate, db *mongo.Database,
 	ton_client *ton.APIClient, ton_api *tonapi.Client,
+	admin_middleware *admin_middleware.Middleware,
 ) *ReferralModule {
 	return &ReferralModule{
-		config:     config,
-		logger:     logger,
-		validator:  validator,
-		db:         db,
-		ton_client: ton_client,
-		ton_api:    ton_api,
+		config:           config,
+		logger:           logger,
+		validator:        validator,
+		db:               db,
+		ton_client:       ton_client,
+		ton_api:          ton_api,
+		admin_middleware: admin_middleware,
 	}
 }
 
 func (m *ReferralModule) Controller() referral_controller.IReferralController {
 	if m.referral_controller == nil {
-		m.referral_controller = referral_controller.NewReferralController(m.logger, m.validator, m.Service(), m.Repository())
+		m.referral_controller = referral_controller.NewReferralController(m.logger, m.validator, m.Service(), m.Repository(), m.Directory())
 	}
 	return m.referral_controller
 }
 
 func (m *ReferralModule) Service() referral_service.IReferralService {
 	if m.referral_service == nil {
-		m.referral_service = referral_service.NewReferralService(m.logger, m.ton_client, m.ton_api, m.config, m.Helper(), m.Repository())
+		m.referral_service = referral_service.NewReferralService(m.logger, m.ton_client, m.ton_api, m.config, m.Helper(), m.Repository(), m.Directory())
 	}
 	return m.referral_service
 }
@@ -71,17 +93,99 @@ func (m *ReferralModule) Repository() referral_repository.IReferralRepository {
 	return m.referral_repository
 }
 
+func (m *ReferralModule) Directory() referral_directory.IReferrerDirectory {
+	if m.referral_directory == nil {
+		if m.config.ReferralDirectoryFixture != "" {
+			directory, err := referral_directory.NewFixtureDirectory(m.config.ReferralDirectoryFixture)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to load referrer directory fixture: %v", err))
+			}
+			m.logger.Warnf("referrer directory is served from fixture: %s", m.config.ReferralDirectoryFixture)
+			m.referral_directory = directory
+			return m.referral_directory
+		}
+
+		baseURL := m.config.ReferralDirectoryUrl
+		if baseURL == "" {
+			baseURL = defaultDirectoryURL
+		}
+		timeout := m.config.ReferralDirectoryTimeout
+		if timeout == 0 {
+			timeout = defaultDirectoryTimeout
+		}
+		ttl := m.config.ReferralDirectoryTTL
+		if ttl == 0 {
+			ttl = defaultDirectoryTTL
+		}
+		m.referral_directory = referral_directory.NewCachedDirectory(
+			referral_directory.NewHttpDirectory(m.logger, baseURL, timeout, m.config.ReferralDirectoryRetries),
+			ttl,
+		)
+	}
+	return m.referral_directory
+}
+
+func (m *ReferralModule) Dispatcher() *referral_worker.PayoutDispatcher {
+	if m.payout_dispatcher == nil {
+		interval := m.config.PayoutDispatchInterval
+		if interval == 0 {
+			interval = defaultPayoutInterval
+		}
+
+		batch := referral_worker.BatchOptions{Enabled: m.config.PayoutBatching}
+		if batch.Enabled {
+			interval = m.config.PayoutBatchInterval
+			if interval == 0 {
+				interval = defaultBatchInterval
+			}
+		}
+
+		m.payout_dispatcher = referral_worker.NewPayoutDispatcher(m.logger, m.config, interval, batch, m.ton_client, m.Helper(), m.Repository())
+	}
+	return m.payout_dispatcher
+}
+
+func (m *ReferralModule) Expirer() *referral_worker.PaymentOrderExpirer {
+	if m.order_expirer == nil {
+		m.order_expirer = referral_worker.NewPaymentOrderExpirer(m.logger, m.config.PaymentOrderTTL, m.Repository())
+	}
+	return m.order_expirer
+}
+
+func (m *ReferralModule) Middleware() *admin_middleware.Middleware {
+	return m.admin_middleware
+}
+
 func (m *ReferralModule) RegisterRoutes(app fiber.Router) {
 	referral := app.Group("/referral")
 	referral.Post("/from-platform", m.Controller().ReferralProcessPlatform) // потом поменять
 	referral.Get("/precheckout/:user_id", m.Controller().PrecheckoutReferrer)
 
-	referral.Get("/:author_id/payment-orders", m.Controller().GetDebtAuthor)
-	referral.Delete("/payment-orders", m.Controller().DeletePaymentOrder) // /payment-orders?order_id=<id>
-	referral.Delete("/payment-orders/all", m.Controller().DeleteAllPaymentOrders)
-	referral.Get("/payment-orders/pay", m.Controller().PayDebtAuthor)             // /payment-orders/pay?order_id=<id>
-	referral.Get("/payment-orders/pay-all", m.Controller().PayAllDebtAuthor)      // /payment-orders/pay-all?author_id=<id>
-	referral.Get("/validate-invite", m.Controller().ValidateInvitationConditions) // /validate-invite?author_id=<id>
-	referral.Post("/payment-orders/add-hash", m.Controller().AddTrHashToPaymentOrder)
-	referral.Get("/payment-orders/calculate-debt", m.Controller().GetCalculateAuthorDebt) // /payment-orders/calculate-debt?author_id=<id>
+	authenticated := m.Middleware().Authenticated()
+	canRead := middleware.RequireScopes(jwt_dto.ScopeOrdersRead)
+	canDelete := middleware.RequireScopes(jwt_dto.ScopeOrdersDelete)
+	canPay := middleware.RequireScopes(jwt_dto.ScopeReferralPay)
+	referral.Get("/:author_id/payment-orders", authenticated, canRead, m.Controller().GetDebtAuthor)
+	referral.Delete("/payment-orders", authenticated, canDelete, m.Controller().DeletePaymentOrder) // /payment-orders?order_id=<id>
+	referral.Delete("/payment-orders/all", authenticated, canDelete, m.Controller().DeleteAllPaymentOrders)
+	referral.Get("/payment-orders/pay", authenticated, canPay, m.Controller().PayDebtAuthor)        // /payment-orders/pay?order_id=<id>
+	referral.Get("/payment-orders/pay-all", authenticated, canPay, m.Controller().PayAllDebtAuthor) // /payment-orders/pay-all?author_id=<id>
+	referral.Get("/validate-invite", m.Controller().ValidateInvitationConditions)                   // /validate-invite?author_id=<id>
+	referral.Post("/payment-orders/add-hash", authenticated, canPay, m.Controller().AddTrHashToPaymentOrder)
+	referral.Get("/payment-orders/calculate-debt", authenticated, canRead, m.Controller().GetCalculateAuthorDebt) // /payment-orders/calculate-debt?author_id=<id>
+	referral.Get("/payouts/:payout_id", m.Middleware().AdminOnly(), m.Controller().GetPayout)
+	referral.Post("/payouts/:payout_id/resend", m.Middleware().AdminOnly(), m.Controller().ResendPayout)
+
+	schedules := referral.Group("/bonus-schedules", m.Middleware().AdminOnly())
+	schedules.Post("/", m.Controller().CreateBonusSchedule)
+	schedules.Get("/", m.Controller().GetBonusSchedules)
+	schedules.Get("/:schedule_id", m.Controller().GetBonusSchedule)
+	schedules.Put("/:schedule_id", m.Controller().UpdateBonusSchedule)
+	schedules.Delete("/:schedule_id", m.Controller().DeleteBonusSchedule)
+
+	leaderPrograms := referral.Group("/leader-programs", m.Middleware().AdminOnly())
+	leaderPrograms.Get("/:leader_id", m.Controller().GetLeaderProgram)
+	leaderPrograms.Get("/:leader_id/versions/:version", m.Controller().GetLeaderProgramVersion)
+	leaderPrograms.Put("/:leader_id", m.Controller().UpsertLeaderProgram)
+	leaderPrograms.Delete("/:leader_id", m.Controller().DeleteLeaderProgram)
 }
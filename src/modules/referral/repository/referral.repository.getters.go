@@ -5,15 +5,21 @@ import (
 
 	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// GetPaymentOrdersByAuthorID returns the unpaid payment orders of the author.
 func (r *ReferralRepository) GetPaymentOrdersByAuthorID(ctx context.Context, authorID int) ([]referral_model.PaymentOrder, error) {
 	r.logger.Info("getting payment orders by author ID")
 	r.logger.Infof("author ID: %d", authorID)
 
 	collection := r.db.Collection(payment_orders_collection)
 
-	filter := bson.D{{Key: "leader_id", Value: authorID}}
+	filter := bson.D{
+		{Key: "leader_id", Value: authorID},
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.UnpaidPaymentOrderStatuses...)},
+	}
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		r.logger.Errorf("failed to find payment orders: %v", err)
@@ -58,6 +64,7 @@ func (r *ReferralRepository) GetDebtFromAuthorToReferrer(ctx context.Context, au
 	filter := bson.D{
 		{Key: "author_id", Value: authorID},
 		{Key: "referrer_id", Value: referrerID},
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.UnpaidPaymentOrderStatuses...)},
 	}
 
 	cursor, err := collection.Find(ctx, filter)
@@ -77,6 +84,30 @@ func (r *ReferralRepository) GetDebtFromAuthorToReferrer(ctx context.Context, au
 	return orders, nil
 }
 
+// GetPaymentOrdersByBundleID returns every order included in the pay-all bundle, whatever its status.
+func (r *ReferralRepository) GetPaymentOrdersByBundleID(ctx context.Context, bundleID string) ([]referral_model.PaymentOrder, error) {
+	r.logger.Infof("getting payment orders by bundle ID: %s", bundleID)
+
+	collection := r.db.Collection(payment_orders_collection)
+
+	filter := bson.D{{Key: "bundle_ids", Value: bundleID}}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		r.logger.Errorf("failed to find payment orders: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []referral_model.PaymentOrder{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		r.logger.Errorf("failed to decode payment orders: %v", err)
+		return nil, err
+	}
+
+	r.logger.Infof("found %d payment orders in bundle %s", len(orders), bundleID)
+	return orders, nil
+}
+
 func (r *ReferralRepository) GetPaymentOrderByID(ctx context.Context, orderID bson.ObjectID) (referral_model.PaymentOrder, error) {
 	r.logger.Info("getting payment order by ID")
 	r.logger.Infof("order ID: %s", orderID)
@@ -99,3 +130,126 @@ func (r *ReferralRepository) GetPaymentOrderByID(ctx context.Context, orderID bs
 	return order, nil
 
 }
+
+// GetPaymentOrderByIdempotencyKey finds the order the referral request with
+// the key was accrued into.
+func (r *ReferralRepository) GetPaymentOrderByIdempotencyKey(ctx context.Context, key string) (referral_model.PaymentOrder, error) {
+	r.logger.Infof("getting payment order by idempotency key: %s", key)
+
+	collection := r.db.Collection(payment_orders_collection)
+
+	var order referral_model.PaymentOrder
+	if err := collection.FindOne(ctx, bson.D{{Key: "idempotency_keys", Value: key}}).Decode(&order); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to find payment order: %v", err)
+		}
+		return referral_model.PaymentOrder{}, err
+	}
+
+	return order, nil
+}
+
+func (r *ReferralRepository) GetBonusSchedules(ctx context.Context) ([]referral_model.BonusSchedule, error) {
+	r.logger.Info("getting all bonus schedules")
+
+	collection := r.db.Collection(bonus_schedules_collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		r.logger.Errorf("failed to find bonus schedules: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	schedules := []referral_model.BonusSchedule{}
+	if err := cursor.All(ctx, &schedules); err != nil {
+		r.logger.Errorf("failed to decode bonus schedules: %v", err)
+		return nil, err
+	}
+
+	r.logger.Infof("found %d bonus schedules", len(schedules))
+	return schedules, nil
+}
+
+func (r *ReferralRepository) GetBonusScheduleByID(ctx context.Context, scheduleID bson.ObjectID) (referral_model.BonusSchedule, error) {
+	r.logger.Infof("getting bonus schedule by ID: %s", scheduleID)
+
+	collection := r.db.Collection(bonus_schedules_collection)
+	filter := bson.D{{Key: "_id", Value: scheduleID}}
+
+	var schedule referral_model.BonusSchedule
+	if err := collection.FindOne(ctx, filter).Decode(&schedule); err != nil {
+		r.logger.Errorf("failed to find bonus schedule: %v", err)
+		return referral_model.BonusSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// GetBonusScheduleAt returns the schedule in force at the given unix time.
+// mongo.ErrNoDocuments is returned when no schedule was effective yet.
+func (r *ReferralRepository) GetBonusScheduleAt(ctx context.Context, at int64) (referral_model.BonusSchedule, error) {
+	r.logger.Infof("getting bonus schedule in force at: %d", at)
+
+	collection := r.db.Collection(bonus_schedules_collection)
+
+	filter := bson.D{{Key: "effective_from", Value: bson.D{{Key: "$lte", Value: at}}}}
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "effective_from", Value: -1},
+		{Key: "version", Value: -1},
+	})
+
+	var schedule referral_model.BonusSchedule
+	if err := collection.FindOne(ctx, filter, opts).Decode(&schedule); err != nil {
+		r.logger.Warnf("failed to find bonus schedule in force: %v", err)
+		return referral_model.BonusSchedule{}, err
+	}
+
+	r.logger.Infof("bonus schedule in force: version %d", schedule.Version)
+	return schedule, nil
+}
+
+// GetLeaderProgram returns the latest version of the leader program.
+// mongo.ErrNoDocuments is returned when the leader has none or it was deleted.
+func (r *ReferralRepository) GetLeaderProgram(ctx context.Context, leaderID int) (referral_model.LeaderProgram, error) {
+	r.logger.Infof("getting leader program for leader ID: %d", leaderID)
+
+	collection := r.db.Collection(leader_programs_collection)
+	filter := bson.D{{Key: "leader_id", Value: leaderID}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	var program referral_model.LeaderProgram
+	if err := collection.FindOne(ctx, filter, opts).Decode(&program); err != nil {
+		r.logger.Warnf("failed to find leader program: %v", err)
+		return referral_model.LeaderProgram{}, err
+	}
+
+	if program.Deleted {
+		r.logger.Infof("leader program was deleted in version %d", program.Version)
+		return referral_model.LeaderProgram{}, mongo.ErrNoDocuments
+	}
+
+	r.logger.Infof("leader program found: version %d", program.Version)
+	return program, nil
+}
+
+// GetLeaderProgramVersion returns a stored version of the leader program, the
+// version an order references stays readable after later changes.
+func (r *ReferralRepository) GetLeaderProgramVersion(ctx context.Context, leaderID int, version int) (referral_model.LeaderProgram, error) {
+	r.logger.Infof("getting leader program version %d for leader ID: %d", version, leaderID)
+
+	collection := r.db.Collection(leader_programs_collection)
+	filter := bson.D{
+		{Key: "leader_id", Value: leaderID},
+		{Key: "version", Value: version},
+	}
+
+	var program referral_model.LeaderProgram
+	if err := collection.FindOne(ctx, filter).Decode(&program); err != nil {
+		r.logger.Warnf("failed to find leader program version: %v", err)
+		return referral_model.LeaderProgram{}, err
+	}
+
+	return program, nil
+}
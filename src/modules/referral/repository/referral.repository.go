@@ -13,12 +13,46 @@ type IReferralRepository interface {
 	CreatePaymentOrder(ctx context.Context, order referral_model.PaymentOrder) error
 	GetPaymentOrderByID(ctx context.Context, orderID bson.ObjectID) (referral_model.PaymentOrder, error)
 	GetPaymentOrdersByAuthorID(ctx context.Context, authorID int) ([]referral_model.PaymentOrder, error)
+	GetPaymentOrdersByBundleID(ctx context.Context, bundleID string) ([]referral_model.PaymentOrder, error)
+	GetPaymentOrderByIdempotencyKey(ctx context.Context, key string) (referral_model.PaymentOrder, error)
 	GetAllPaymentOrders(ctx context.Context) ([]referral_model.PaymentOrder, error)
-	DeleteAllPaymentOrders(ctx context.Context, authorID int) error
-	DeletePaymentOrder(ctx context.Context, orderID bson.ObjectID) error
 	GetDebtFromAuthorToReferrer(ctx context.Context, authorID int, referrerID int) ([]referral_model.PaymentOrder, error)
 	UpdatePaymentOrder(ctx context.Context, order referral_model.PaymentOrder) error
 	AddTrHashToPaymentOrder(ctx context.Context, orderID bson.ObjectID, trHash string) error
+	UpdatePaymentOrderStatus(ctx context.Context, orderID bson.ObjectID, status referral_model.PaymentOrderStatus, trHash string) (referral_model.PaymentOrder, error)
+	CancelAllPaymentOrders(ctx context.Context, authorID int) (int64, error)
+	ExpirePaymentOrders(ctx context.Context, createdBefore int64) (int64, error)
+	BundlePaymentOrders(ctx context.Context, orderIDs []bson.ObjectID, bundleID string) (int64, error)
+	UpdatePaymentBundleStatus(ctx context.Context, bundleID string, status referral_model.PaymentOrderStatus, trHash string) (int64, error)
+
+	CreateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error)
+	GetBonusSchedules(ctx context.Context) ([]referral_model.BonusSchedule, error)
+	GetBonusScheduleByID(ctx context.Context, scheduleID bson.ObjectID) (referral_model.BonusSchedule, error)
+	GetBonusScheduleAt(ctx context.Context, at int64) (referral_model.BonusSchedule, error)
+	UpdateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error)
+	DeleteBonusSchedule(ctx context.Context, scheduleID bson.ObjectID) error
+
+	GetLeaderProgram(ctx context.Context, leaderID int) (referral_model.LeaderProgram, error)
+	GetLeaderProgramVersion(ctx context.Context, leaderID int, version int) (referral_model.LeaderProgram, error)
+	CreateLeaderProgram(ctx context.Context, program referral_model.LeaderProgram) (referral_model.LeaderProgram, error)
+	DeleteLeaderProgram(ctx context.Context, leaderID int) error
+
+	ClaimIdempotencyKey(ctx context.Context, record referral_model.IdempotencyRecord) (referral_model.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, status referral_model.IdempotencyStatus, result referral_model.IdempotencyResult) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	CreatePayout(ctx context.Context, payout referral_model.Payout) (referral_model.Payout, error)
+	GetPayoutByID(ctx context.Context, payoutID bson.ObjectID) (referral_model.Payout, error)
+	GetPayoutByIdempotencyKey(ctx context.Context, key string) (referral_model.Payout, error)
+	ClaimPayout(ctx context.Context, leaseUntil int64) (referral_model.Payout, error)
+	UpdatePayoutStatus(ctx context.Context, payoutID bson.ObjectID, status referral_model.PayoutStatus, txHash string, reason string) error
+	FailStalePayouts(ctx context.Context, reason string) (int64, error)
+	GetPendingPayouts(ctx context.Context, limit int64) ([]referral_model.Payout, error)
+	ClaimPayoutByID(ctx context.Context, payoutID bson.ObjectID, leaseUntil int64) (referral_model.Payout, error)
+	RequeuePayout(ctx context.Context, payoutID bson.ObjectID) (referral_model.Payout, error)
+	CompletePayoutBatch(ctx context.Context, payoutIDs []bson.ObjectID, batchID string, status referral_model.PayoutStatus, txHash string, reason string) error
+
+	EnsureIndexes(ctx context.Context) error
 }
 
 type ReferralRepository struct {
@@ -27,8 +61,12 @@ type ReferralRepository struct {
 }
 
 const (
-	database_name             = "referral"
-	payment_orders_collection = "payment_orders"
+	database_name              = "referral"
+	payment_orders_collection  = "payment_orders"
+	bonus_schedules_collection = "bonus_schedules"
+	leader_programs_collection = "leader_programs"
+	idempotency_collection     = "idempotency_keys"
+	payouts_collection         = "payouts"
 )
 
 func NewReferralRepository(logger *logger.Logger, db *mongo.Database) IReferralRepository {
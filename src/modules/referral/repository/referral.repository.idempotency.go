@@ -0,0 +1,116 @@
+package referral_repository
+
+import (
+	"context"
+	"time"
+
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ClaimIdempotencyKey stores the record if its key is free, expired or held by a
+// stale processing claim of the same payload, and reports whether the caller
+// owns the key. A stale claim is taken over with its attempts bumped, so the
+// caller can tell it may finish the work of a previous attempt. Otherwise the
+// stored record is returned.
+func (r *ReferralRepository) ClaimIdempotencyKey(ctx context.Context, record referral_model.IdempotencyRecord) (referral_model.IdempotencyRecord, bool, error) {
+	r.logger.Infof("claiming idempotency key: %s", record.Key)
+
+	collection := r.db.Collection(idempotency_collection)
+
+	record.Attempts = 1
+	_, err := collection.InsertOne(ctx, record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		r.logger.Errorf("failed to insert idempotency key: %v", err)
+		return referral_model.IdempotencyRecord{}, false, err
+	}
+
+	staleFilter := bson.D{
+		{Key: "_id", Value: record.Key},
+		{Key: "status", Value: referral_model.IdempotencyProcessing},
+		{Key: "fingerprint", Value: record.Fingerprint},
+		{Key: "lease_until", Value: bson.D{{Key: "$lte", Value: record.CreatedAt}}},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Unix(record.CreatedAt, 0)}}},
+	}
+	staleUpdate := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "lease_until", Value: record.LeaseUntil},
+			{Key: "updated_at", Value: record.UpdatedAt},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+	var taken referral_model.IdempotencyRecord
+	err = collection.FindOneAndUpdate(ctx, staleFilter, staleUpdate, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&taken)
+	if err == nil {
+		r.logger.Infof("stale idempotency key %s taken over, attempt %d", record.Key, taken.Attempts)
+		return taken, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		r.logger.Errorf("failed to take over stale idempotency key: %v", err)
+		return referral_model.IdempotencyRecord{}, false, err
+	}
+
+	expiredFilter := bson.D{
+		{Key: "_id", Value: record.Key},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: time.Unix(record.CreatedAt, 0)}}},
+	}
+	result, err := collection.ReplaceOne(ctx, expiredFilter, record)
+	if err != nil {
+		r.logger.Errorf("failed to take over expired idempotency key: %v", err)
+		return referral_model.IdempotencyRecord{}, false, err
+	}
+	if result.MatchedCount == 1 {
+		r.logger.Infof("expired idempotency key %s taken over", record.Key)
+		return record, true, nil
+	}
+
+	var existing referral_model.IdempotencyRecord
+	if err := collection.FindOne(ctx, bson.D{{Key: "_id", Value: record.Key}}).Decode(&existing); err != nil {
+		r.logger.Errorf("failed to get idempotency key: %v", err)
+		return referral_model.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (r *ReferralRepository) CompleteIdempotencyKey(ctx context.Context, key string, status referral_model.IdempotencyStatus, result referral_model.IdempotencyResult) error {
+	r.logger.Infof("completing idempotency key %s with status %s", key, status)
+
+	collection := r.db.Collection(idempotency_collection)
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: status},
+		{Key: "result", Value: result},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	updateResult, err := collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: key}}, update)
+	if err != nil {
+		r.logger.Errorf("failed to complete idempotency key: %v", err)
+		return err
+	}
+	if updateResult.MatchedCount == 0 {
+		r.logger.Warnf("no idempotency key found: %s", key)
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *ReferralRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	r.logger.Infof("releasing idempotency key: %s", key)
+
+	collection := r.db.Collection(idempotency_collection)
+
+	if _, err := collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: key}}); err != nil {
+		r.logger.Errorf("failed to release idempotency key: %v", err)
+		return err
+	}
+
+	return nil
+}
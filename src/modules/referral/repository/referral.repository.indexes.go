@@ -0,0 +1,82 @@
+package referral_repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// EnsureIndexes creates the indexes of the collections. The unique version
+// indexes of bonus schedules and leader programs keep two concurrent inserts
+// from taking the same version, the TTL index drops idempotency keys once they
+// expire.
+func (r *ReferralRepository) EnsureIndexes(ctx context.Context) error {
+	collections := []collectionIndexes{
+		{
+			collection: leader_programs_collection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "leader_id", Value: 1}, {Key: "version", Value: 1}},
+					Options: options.Index().SetName("leader_id_version_unique").SetUnique(true),
+				},
+			},
+		},
+		{
+			collection: bonus_schedules_collection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "version", Value: 1}},
+					Options: options.Index().SetName("version_unique").SetUnique(true),
+				},
+			},
+		},
+		{
+			collection: idempotency_collection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "expires_at", Value: 1}},
+					Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+				},
+			},
+		},
+		{
+			collection: payouts_collection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "idempotency_key", Value: 1}},
+					Options: options.Index().SetName("idempotency_key").SetSparse(true),
+				},
+			},
+		},
+		{
+			collection: payment_orders_collection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "idempotency_keys", Value: 1}},
+					Options: options.Index().SetName("idempotency_keys"),
+				},
+			},
+		},
+	}
+
+	for _, c := range collections {
+		r.logger.Infof("ensuring indexes of %s", c.collection)
+
+		names, err := r.db.Collection(c.collection).Indexes().CreateMany(ctx, c.indexes)
+		if err != nil {
+			r.logger.Errorf("failed to create indexes of %s: %v", c.collection, err)
+			return err
+		}
+
+		r.logger.Infof("indexes of %s are ready: %v", c.collection, names)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package referral_repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrPaymentOrderTransition is returned when the payment order exists but its
+// current status does not allow the requested transition.
+var ErrPaymentOrderTransition = errors.New("payment order status transition is not allowed")
+
+// paymentOrderStatusFilter matches orders in any of the given statuses.
+// Orders without a status are matched as open.
+func paymentOrderStatusFilter(statuses ...referral_model.PaymentOrderStatus) bson.D {
+	values := bson.A{}
+	for _, status := range statuses {
+		values = append(values, status)
+		if status == referral_model.PaymentOrderStatusOpen {
+			values = append(values, nil)
+		}
+	}
+	return bson.D{{Key: "$in", Value: values}}
+}
+
+// paymentOrderSourceStatuses returns the statuses the order may move to status from.
+func paymentOrderSourceStatuses(status referral_model.PaymentOrderStatus) []referral_model.PaymentOrderStatus {
+	sources := []referral_model.PaymentOrderStatus{}
+	for from, targets := range referral_model.PaymentOrderTransitions {
+		for _, to := range targets {
+			if to == status {
+				sources = append(sources, from)
+			}
+		}
+	}
+	return sources
+}
+
+// paymentOrderStatusUpdate sets the new status and appends the transition to
+// the history, taking the previous status from the document itself.
+func paymentOrderStatusUpdate(status referral_model.PaymentOrderStatus, trHash string) mongo.Pipeline {
+	now := time.Now().Unix()
+
+	transition := bson.D{
+		{Key: "from", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$status", referral_model.PaymentOrderStatusOpen}}}},
+		{Key: "to", Value: status},
+		{Key: "at", Value: now},
+	}
+
+	set := bson.D{
+		{Key: "status", Value: status},
+		{Key: "status_history", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$status_history", bson.A{}}}},
+			bson.A{transition},
+		}}}},
+		{Key: "updated_at", Value: now},
+	}
+	if trHash != "" {
+		set = append(set, bson.E{Key: "tr_hash", Value: trHash})
+	}
+
+	return mongo.Pipeline{{{Key: "$set", Value: set}}}
+}
+
+// UpdatePaymentOrderStatus moves the order to the given status if the transition
+// is allowed from its current one. trHash is stored along when not empty.
+func (r *ReferralRepository) UpdatePaymentOrderStatus(ctx context.Context, orderID bson.ObjectID, status referral_model.PaymentOrderStatus, trHash string) (referral_model.PaymentOrder, error) {
+	r.logger.Infof("updating status of payment order %v to %s", orderID, status)
+
+	collection := r.db.Collection(payment_orders_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: orderID},
+		{Key: "status", Value: paymentOrderStatusFilter(paymentOrderSourceStatuses(status)...)},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated referral_model.PaymentOrder
+	err := collection.FindOneAndUpdate(ctx, filter, paymentOrderStatusUpdate(status, trHash), opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		current, err := r.GetPaymentOrderByID(ctx, orderID)
+		if err != nil {
+			return referral_model.PaymentOrder{}, err
+		}
+		r.logger.Warnf("payment order %v can not move from %s to %s", orderID, current.CurrentStatus(), status)
+		return referral_model.PaymentOrder{}, ErrPaymentOrderTransition
+	}
+	if err != nil {
+		r.logger.Errorf("failed to update payment order status: %v", err)
+		return referral_model.PaymentOrder{}, err
+	}
+
+	r.logger.Infof("payment order %v moved to %s", orderID, updated.Status)
+	return updated, nil
+}
+
+// CancelAllPaymentOrders cancels every unpaid payment order of the leader.
+func (r *ReferralRepository) CancelAllPaymentOrders(ctx context.Context, authorID int) (int64, error) {
+	r.logger.Infof("cancelling all unpaid payment orders of author ID: %d", authorID)
+
+	filter := bson.D{
+		{Key: "leader_id", Value: authorID},
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.UnpaidPaymentOrderStatuses...)},
+	}
+
+	return r.updatePaymentOrdersStatus(ctx, filter, referral_model.PaymentOrderStatusCancelled, "")
+}
+
+// ExpirePaymentOrders expires open payment orders created before the given unix
+// time. Orders awaiting payment are left to the validation of their transaction.
+func (r *ReferralRepository) ExpirePaymentOrders(ctx context.Context, createdBefore int64) (int64, error) {
+	r.logger.Infof("expiring open payment orders created before: %d", createdBefore)
+
+	filter := bson.D{
+		{Key: "created_at", Value: bson.D{{Key: "$lt", Value: createdBefore}}},
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.PaymentOrderStatusOpen)},
+	}
+
+	return r.updatePaymentOrdersStatus(ctx, filter, referral_model.PaymentOrderStatusExpired, "")
+}
+
+// BundlePaymentOrders adds the orders to a pay-all bundle. Open orders move to
+// awaiting payment, orders already awaiting payment keep their status.
+func (r *ReferralRepository) BundlePaymentOrders(ctx context.Context, orderIDs []bson.ObjectID, bundleID string) (int64, error) {
+	r.logger.Infof("bundling %d payment orders into bundle: %s", len(orderIDs), bundleID)
+
+	collection := r.db.Collection(payment_orders_collection)
+
+	opened := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: orderIDs}}},
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.PaymentOrderStatusOpen)},
+	}
+	if _, err := r.updatePaymentOrdersStatus(ctx, opened, referral_model.PaymentOrderStatusAwaitingPayment, ""); err != nil {
+		return 0, err
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: orderIDs}}},
+		{Key: "status", Value: referral_model.PaymentOrderStatusAwaitingPayment},
+	}
+	update := bson.D{{Key: "$addToSet", Value: bson.D{{Key: "bundle_ids", Value: bundleID}}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to bundle payment orders: %v", err)
+		return 0, err
+	}
+
+	r.logger.Infof("%d payment orders bundled into %s", result.MatchedCount, bundleID)
+	return result.MatchedCount, nil
+}
+
+// UpdatePaymentBundleStatus moves every order of the bundle that allows the
+// transition to the given status. trHash is stored along when not empty.
+func (r *ReferralRepository) UpdatePaymentBundleStatus(ctx context.Context, bundleID string, status referral_model.PaymentOrderStatus, trHash string) (int64, error) {
+	r.logger.Infof("updating status of payment bundle %s to %s", bundleID, status)
+
+	filter := bson.D{
+		{Key: "bundle_ids", Value: bundleID},
+		{Key: "status", Value: paymentOrderStatusFilter(paymentOrderSourceStatuses(status)...)},
+	}
+
+	return r.updatePaymentOrdersStatus(ctx, filter, status, trHash)
+}
+
+func (r *ReferralRepository) updatePaymentOrdersStatus(ctx context.Context, filter bson.D, status referral_model.PaymentOrderStatus, trHash string) (int64, error) {
+	collection := r.db.Collection(payment_orders_collection)
+
+	result, err := collection.UpdateMany(ctx, filter, paymentOrderStatusUpdate(status, trHash))
+	if err != nil {
+		r.logger.Errorf("failed to move payment orders to %s: %v", status, err)
+		return 0, err
+	}
+
+	r.logger.Infof("%d payment orders moved to %s", result.ModifiedCount, status)
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,265 @@
+package referral_repository
+
+import (
+	"context"
+	"time"
+
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (r *ReferralRepository) CreatePayout(ctx context.Context, payout referral_model.Payout) (referral_model.Payout, error) {
+	r.logger.Infof("creating payout for referrer %d", payout.ReferrerID)
+
+	collection := r.db.Collection(payouts_collection)
+
+	now := time.Now().Unix()
+	payout.ID = bson.NewObjectID()
+	payout.Status = referral_model.PayoutStatusPending
+	if payout.CreatedAt == 0 {
+		payout.CreatedAt = now
+	}
+	payout.UpdatedAt = now
+
+	if _, err := collection.InsertOne(ctx, payout); err != nil {
+		r.logger.Errorf("failed to create payout: %v", err)
+		return referral_model.Payout{}, err
+	}
+
+	r.logger.Infof("payout created: %s", payout.ID.Hex())
+	return payout, nil
+}
+
+func (r *ReferralRepository) GetPayoutByID(ctx context.Context, payoutID bson.ObjectID) (referral_model.Payout, error) {
+	r.logger.Infof("getting payout by ID: %s", payoutID.Hex())
+
+	collection := r.db.Collection(payouts_collection)
+
+	var payout referral_model.Payout
+	if err := collection.FindOne(ctx, bson.D{{Key: "_id", Value: payoutID}}).Decode(&payout); err != nil {
+		r.logger.Errorf("failed to get payout: %v", err)
+		return referral_model.Payout{}, err
+	}
+
+	return payout, nil
+}
+
+// GetPayoutByIdempotencyKey finds the payout queued by the referral request
+// with the key.
+func (r *ReferralRepository) GetPayoutByIdempotencyKey(ctx context.Context, key string) (referral_model.Payout, error) {
+	r.logger.Infof("getting payout by idempotency key: %s", key)
+
+	collection := r.db.Collection(payouts_collection)
+
+	var payout referral_model.Payout
+	if err := collection.FindOne(ctx, bson.D{{Key: "idempotency_key", Value: key}}).Decode(&payout); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to get payout: %v", err)
+		}
+		return referral_model.Payout{}, err
+	}
+
+	return payout, nil
+}
+
+// ClaimPayout moves the oldest pending payout to sending and leases it until
+// `leaseUntil`. Returns mongo.ErrNoDocuments when the outbox is empty.
+func (r *ReferralRepository) ClaimPayout(ctx context.Context, leaseUntil int64) (referral_model.Payout, error) {
+	collection := r.db.Collection(payouts_collection)
+
+	filter := bson.D{{Key: "status", Value: referral_model.PayoutStatusPending}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: referral_model.PayoutStatusSending},
+			{Key: "lease_until", Value: leaseUntil},
+			{Key: "updated_at", Value: time.Now().Unix()},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var payout referral_model.Payout
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&payout); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to claim payout: %v", err)
+		}
+		return referral_model.Payout{}, err
+	}
+
+	r.logger.Infof("payout %s claimed, attempt %d", payout.ID.Hex(), payout.Attempts)
+	return payout, nil
+}
+
+// UpdatePayoutStatus releases the lease of a sending payout with its outcome.
+func (r *ReferralRepository) UpdatePayoutStatus(ctx context.Context, payoutID bson.ObjectID, status referral_model.PayoutStatus, txHash string, reason string) error {
+	r.logger.Infof("updating payout %s status to %s", payoutID.Hex(), status)
+
+	collection := r.db.Collection(payouts_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: payoutID},
+		{Key: "status", Value: referral_model.PayoutStatusSending},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: status},
+		{Key: "tx_hash", Value: txHash},
+		{Key: "error", Value: reason},
+		{Key: "lease_until", Value: int64(0)},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to update payout status: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		r.logger.Warnf("no sending payout found: %s", payoutID.Hex())
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// FailStalePayouts marks payouts whose lease expired while sending as failed.
+// The transfer may have left the wallet, so they are never sent again automatically.
+func (r *ReferralRepository) FailStalePayouts(ctx context.Context, reason string) (int64, error) {
+	collection := r.db.Collection(payouts_collection)
+
+	now := time.Now().Unix()
+	filter := bson.D{
+		{Key: "status", Value: referral_model.PayoutStatusSending},
+		{Key: "lease_until", Value: bson.D{{Key: "$lt", Value: now}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: referral_model.PayoutStatusFailed},
+		{Key: "error", Value: reason},
+		{Key: "lease_until", Value: int64(0)},
+		{Key: "updated_at", Value: now},
+	}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to fail stale payouts: %v", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *ReferralRepository) GetPendingPayouts(ctx context.Context, limit int64) ([]referral_model.Payout, error) {
+	collection := r.db.Collection(payouts_collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.D{{Key: "status", Value: referral_model.PayoutStatusPending}}, opts)
+	if err != nil {
+		r.logger.Errorf("failed to get pending payouts: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var payouts []referral_model.Payout
+	if err := cursor.All(ctx, &payouts); err != nil {
+		r.logger.Errorf("failed to decode pending payouts: %v", err)
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// ClaimPayoutByID leases a specific pending payout. Returns mongo.ErrNoDocuments
+// when the payout was claimed by someone else in the meantime.
+func (r *ReferralRepository) ClaimPayoutByID(ctx context.Context, payoutID bson.ObjectID, leaseUntil int64) (referral_model.Payout, error) {
+	collection := r.db.Collection(payouts_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: payoutID},
+		{Key: "status", Value: referral_model.PayoutStatusPending},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: referral_model.PayoutStatusSending},
+			{Key: "lease_until", Value: leaseUntil},
+			{Key: "updated_at", Value: time.Now().Unix()},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var payout referral_model.Payout
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&payout); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to claim payout %s: %v", payoutID.Hex(), err)
+		}
+		return referral_model.Payout{}, err
+	}
+
+	return payout, nil
+}
+
+// RequeuePayout returns a failed payout to the queue with a fresh attempt budget.
+// Failed payouts are only requeued on request, after the transfer was checked
+// not to have left the wallet.
+func (r *ReferralRepository) RequeuePayout(ctx context.Context, payoutID bson.ObjectID) (referral_model.Payout, error) {
+	r.logger.Infof("requeueing payout %s", payoutID.Hex())
+
+	collection := r.db.Collection(payouts_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: payoutID},
+		{Key: "status", Value: referral_model.PayoutStatusFailed},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: referral_model.PayoutStatusPending},
+		{Key: "error", Value: ""},
+		{Key: "attempts", Value: 0},
+		{Key: "lease_until", Value: int64(0)},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var payout referral_model.Payout
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&payout); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to requeue payout %s: %v", payoutID.Hex(), err)
+		}
+		return referral_model.Payout{}, err
+	}
+
+	return payout, nil
+}
+
+// CompletePayoutBatch releases the lease of every sending payout of a batch with the shared outcome.
+func (r *ReferralRepository) CompletePayoutBatch(ctx context.Context, payoutIDs []bson.ObjectID, batchID string, status referral_model.PayoutStatus, txHash string, reason string) error {
+	r.logger.Infof("updating %d payouts of batch %s to %s", len(payoutIDs), batchID, status)
+
+	collection := r.db.Collection(payouts_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: payoutIDs}}},
+		{Key: "status", Value: referral_model.PayoutStatusSending},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: status},
+		{Key: "tx_hash", Value: txHash},
+		{Key: "batch_id", Value: batchID},
+		{Key: "error", Value: reason},
+		{Key: "lease_until", Value: int64(0)},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to update payout batch: %v", err)
+		return err
+	}
+	if result.MatchedCount != int64(len(payoutIDs)) {
+		r.logger.Warnf("payout batch %s: %d of %d payouts updated", batchID, result.MatchedCount, len(payoutIDs))
+	}
+
+	return nil
+}
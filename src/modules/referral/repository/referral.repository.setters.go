@@ -2,7 +2,7 @@ package referral_repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
@@ -26,6 +26,13 @@ func (r *ReferralRepository) CreatePaymentOrder(ctx context.Context, order refer
 		order.ID = bson.NewObjectID()
 	}
 
+	if order.Status == "" {
+		order.Status = referral_model.PaymentOrderStatusOpen
+		order.StatusHistory = []referral_model.StatusTransition{
+			{To: referral_model.PaymentOrderStatusOpen, At: order.CreatedAt},
+		}
+	}
+
 	collection := r.db.Collection(payment_orders_collection)
 
 	result, err := collection.InsertOne(ctx, order)
@@ -40,43 +47,13 @@ func (r *ReferralRepository) CreatePaymentOrder(ctx context.Context, order refer
 	return nil
 }
 
-func (r *ReferralRepository) DeletePaymentOrder(ctx context.Context, orderID bson.ObjectID) error {
-	r.logger.Info("deleting payment order from database")
-	r.logger.Infof("order ID: %v", orderID)
-
-	collection := r.db.Collection(payment_orders_collection)
-
-	filter := bson.D{{Key: "_id", Value: orderID}}
-	result, err := collection.DeleteOne(ctx, filter)
-	if err != nil {
-		r.logger.Errorf("failed to delete payment order: %v", err)
-		return err
-	}
-
-	if result.DeletedCount == 0 {
-		r.logger.Warnf("no payment order found with ID: %v", orderID)
-		return fmt.Errorf("payment order not found")
+// programVersionFilter matches orders of the leader program version, orders
+// calculated without a program have no program_version stored.
+func programVersionFilter(version int) any {
+	if version == 0 {
+		return bson.D{{Key: "$in", Value: bson.A{0, nil}}}
 	}
-
-	r.logger.Infof("payment order with ID %v deleted successfully", orderID)
-	return nil
-}
-
-func (r *ReferralRepository) DeleteAllPaymentOrders(ctx context.Context, authorID int) error {
-	r.logger.Info("deleting all payment orders from database")
-	r.logger.Infof("author ID: %d", authorID)
-
-	collection := r.db.Collection(payment_orders_collection)
-
-	filter := bson.D{{Key: "leader_id", Value: authorID}}
-	result, err := collection.DeleteMany(ctx, filter)
-	if err != nil {
-		r.logger.Errorf("failed to delete all payment orders: %v", err)
-		return err
-	}
-
-	r.logger.Infof("deleted %v payment orders", result.DeletedCount)
-	return nil
+	return version
 }
 
 type levelKey struct {
@@ -94,6 +71,11 @@ func (r *ReferralRepository) UpdatePaymentOrder(ctx context.Context, order refer
 		{Key: "leader_id", Value: order.LeaderID},
 		{Key: "referrer_id", Value: order.ReferrerID},
 		{Key: "referral_id", Value: order.ReferralID},
+		{Key: "schedule_version", Value: order.ScheduleVersion},
+		{Key: "schedule_source", Value: order.ScheduleSource},
+		{Key: "program_version", Value: programVersionFilter(order.ProgramVersion)},
+		// accruals are merged only into orders nobody started to pay yet
+		{Key: "status", Value: paymentOrderStatusFilter(referral_model.PaymentOrderStatusOpen)},
 	}
 
 	var existing referral_model.PaymentOrder
@@ -134,6 +116,11 @@ func (r *ReferralRepository) UpdatePaymentOrder(ctx context.Context, order refer
 			{Key: "updated_at", Value: time.Now().Unix()},
 		}},
 	}
+	if len(order.IdempotencyKeys) > 0 {
+		update = append(update, bson.E{Key: "$addToSet", Value: bson.D{
+			{Key: "idempotency_keys", Value: bson.D{{Key: "$each", Value: order.IdempotencyKeys}}},
+		}})
+	}
 
 	var updatedDoc referral_model.PaymentOrder
 	err = collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updatedDoc)
@@ -154,15 +141,176 @@ func (r *ReferralRepository) AddTrHashToPaymentOrder(ctx context.Context, orderI
 
 	collection := r.db.Collection(payment_orders_collection)
 
-	filter := bson.D{{Key: "_id", Value: orderID}}
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "tr_hash", Value: trHash}}}}
+	filter := bson.D{
+		{Key: "_id", Value: orderID},
+		{Key: "status", Value: referral_model.PaymentOrderStatusAwaitingPayment},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "tr_hash", Value: trHash},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
 
-	_, err := collection.UpdateOne(ctx, filter, update)
+	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		r.logger.Errorf("failed to add tr hash to payment order: %v", err)
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		r.logger.Warnf("no payment order awaiting payment found with ID: %v", orderID)
+		return mongo.ErrNoDocuments
+	}
+
 	r.logger.Infof("tr hash added to payment order with ID: %v", orderID)
 	return nil
 }
+
+// ErrBonusScheduleInForce is returned when the schedule exists but has already
+// come into force, such schedules are immutable.
+var ErrBonusScheduleInForce = errors.New("bonus schedule is already in force")
+
+// CreateBonusSchedule stores a new schedule with the next free version number.
+func (r *ReferralRepository) CreateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error) {
+	r.logger.Info("create bonus schedule in database")
+
+	collection := r.db.Collection(bonus_schedules_collection)
+
+	now := time.Now().Unix()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	err := r.insertNextVersion(ctx, collection, bson.D{}, func(version int) any {
+		schedule.ID = bson.NewObjectID()
+		schedule.Version = version
+		return schedule
+	})
+	if err != nil {
+		r.logger.Errorf("failed to create bonus schedule: %v", err)
+		return referral_model.BonusSchedule{}, err
+	}
+
+	r.logger.Infof("bonus schedule created: version %d", schedule.Version)
+	return schedule, nil
+}
+
+// plannedBonusScheduleFilter matches the schedule only while it has not come
+// into force yet.
+func plannedBonusScheduleFilter(scheduleID bson.ObjectID) bson.D {
+	return bson.D{
+		{Key: "_id", Value: scheduleID},
+		{Key: "effective_from", Value: bson.D{{Key: "$gt", Value: time.Now().Unix()}}},
+	}
+}
+
+// bonusScheduleMissError tells a schedule in force from a missing one once the
+// planned schedule filter matched nothing.
+func (r *ReferralRepository) bonusScheduleMissError(ctx context.Context, scheduleID bson.ObjectID) error {
+	if _, err := r.GetBonusScheduleByID(ctx, scheduleID); err != nil {
+		return err
+	}
+	r.logger.Warnf("bonus schedule %v is already in force", scheduleID)
+	return ErrBonusScheduleInForce
+}
+
+func (r *ReferralRepository) UpdateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error) {
+	r.logger.Infof("updating bonus schedule: %s", schedule.ID)
+
+	collection := r.db.Collection(bonus_schedules_collection)
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "levels", Value: schedule.Levels},
+		{Key: "effective_from", Value: schedule.EffectiveFrom},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	var updated referral_model.BonusSchedule
+	err := collection.FindOneAndUpdate(ctx, plannedBonusScheduleFilter(schedule.ID), update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return referral_model.BonusSchedule{}, r.bonusScheduleMissError(ctx, schedule.ID)
+	}
+	if err != nil {
+		r.logger.Errorf("failed to update bonus schedule: %v", err)
+		return referral_model.BonusSchedule{}, err
+	}
+
+	r.logger.Infof("bonus schedule updated: version %d", updated.Version)
+	return updated, nil
+}
+
+func (r *ReferralRepository) DeleteBonusSchedule(ctx context.Context, scheduleID bson.ObjectID) error {
+	r.logger.Infof("deleting bonus schedule: %s", scheduleID)
+
+	collection := r.db.Collection(bonus_schedules_collection)
+
+	result, err := collection.DeleteOne(ctx, plannedBonusScheduleFilter(scheduleID))
+	if err != nil {
+		r.logger.Errorf("failed to delete bonus schedule: %v", err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return r.bonusScheduleMissError(ctx, scheduleID)
+	}
+
+	r.logger.Infof("bonus schedule with ID %v deleted successfully", scheduleID)
+	return nil
+}
+
+// CreateLeaderProgram stores the program as the next version of the leader,
+// earlier versions are kept unchanged.
+func (r *ReferralRepository) CreateLeaderProgram(ctx context.Context, program referral_model.LeaderProgram) (referral_model.LeaderProgram, error) {
+	r.logger.Infof("creating leader program for leader ID: %d", program.LeaderID)
+
+	collection := r.db.Collection(leader_programs_collection)
+
+	now := time.Now().Unix()
+	program.Deleted = false
+	program.CreatedAt = now
+	program.UpdatedAt = now
+
+	filter := bson.D{{Key: "leader_id", Value: program.LeaderID}}
+	err := r.insertNextVersion(ctx, collection, filter, func(version int) any {
+		program.ID = bson.NewObjectID()
+		program.Version = version
+		return program
+	})
+	if err != nil {
+		r.logger.Errorf("failed to create leader program: %v", err)
+		return referral_model.LeaderProgram{}, err
+	}
+
+	r.logger.Infof("leader program created: version %d", program.Version)
+	return program, nil
+}
+
+// DeleteLeaderProgram stores a deleted version of the leader program, so the
+// leader falls back to the platform program and the history is kept.
+func (r *ReferralRepository) DeleteLeaderProgram(ctx context.Context, leaderID int) error {
+	r.logger.Infof("deleting leader program for leader ID: %d", leaderID)
+
+	if _, err := r.GetLeaderProgram(ctx, leaderID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			r.logger.Warnf("no leader program found for leader ID: %d", leaderID)
+		}
+		return err
+	}
+
+	collection := r.db.Collection(leader_programs_collection)
+
+	now := time.Now().Unix()
+	deleted := referral_model.LeaderProgram{LeaderID: leaderID, Deleted: true, CreatedAt: now, UpdatedAt: now}
+
+	filter := bson.D{{Key: "leader_id", Value: leaderID}}
+	err := r.insertNextVersion(ctx, collection, filter, func(version int) any {
+		deleted.ID = bson.NewObjectID()
+		deleted.Version = version
+		return deleted
+	})
+	if err != nil {
+		r.logger.Errorf("failed to delete leader program: %v", err)
+		return err
+	}
+
+	r.logger.Infof("leader program for leader ID %d deleted in version %d", leaderID, deleted.Version)
+	return nil
+}
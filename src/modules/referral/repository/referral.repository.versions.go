@@ -0,0 +1,50 @@
+package referral_repository
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// versionInsertAttempts bounds the retries of an insert racing other writers
+// for the same version number.
+const versionInsertAttempts = 5
+
+// ErrVersionConflict is returned when every attempt to insert the next version
+// lost the race to a concurrent insert.
+var ErrVersionConflict = errors.New("next version is taken by concurrent inserts")
+
+// insertNextVersion inserts the document built for the version following the
+// latest one matching the filter. The unique version index of the collection
+// rejects a concurrent insert of the same version, it is retried with the next.
+func (r *ReferralRepository) insertNextVersion(ctx context.Context, collection *mongo.Collection, filter bson.D, build func(version int) any) error {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.D{{Key: "version", Value: 1}})
+
+	for range versionInsertAttempts {
+		var latest struct {
+			Version int `bson:"version"`
+		}
+		err := collection.FindOne(ctx, filter, opts).Decode(&latest)
+		if err != nil && err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to find latest version in %s: %v", collection.Name(), err)
+			return err
+		}
+
+		_, err = collection.InsertOne(ctx, build(latest.Version+1))
+		if mongo.IsDuplicateKeyError(err) {
+			r.logger.Warnf("version %d in %s is taken by a concurrent insert, retrying", latest.Version+1, collection.Name())
+			continue
+		}
+		if err != nil {
+			r.logger.Errorf("failed to insert version %d in %s: %v", latest.Version+1, collection.Name(), err)
+		}
+		return err
+	}
+
+	return ErrVersionConflict
+}
@@ -0,0 +1,230 @@
+package referral_service
+
+import (
+	"context"
+	"time"
+
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// defaultScheduleVersion marks orders calculated with the built-in rates,
+// used while no schedule has been stored yet.
+const defaultScheduleVersion = 0
+
+type bonusSchedule struct {
+	Version  int
+	Rates    map[int]decimal.Decimal
+	MaxLevel int
+}
+
+func defaultBonusSchedule() bonusSchedule {
+	return bonusSchedule{
+		Version: defaultScheduleVersion,
+		Rates: map[int]decimal.Decimal{
+			0: decimal.NewFromFloat(0.20), // Уровень 1: 20%
+			1: decimal.NewFromFloat(0.02), // Уровень 2: 2%
+		},
+		MaxLevel: maxLevel,
+	}
+}
+
+func bonusScheduleFromLevels(version int, levels []referral_model.ScheduleLevel) (bonusSchedule, error) {
+	rates := make(map[int]decimal.Decimal, len(levels))
+	maxLevel := 0
+	for _, level := range levels {
+		rate, err := decimal.NewFromString(level.Rate.String())
+		if err != nil {
+			return bonusSchedule{}, err
+		}
+		rates[level.LevelNumber] = rate
+		maxLevel = max(maxLevel, level.LevelNumber)
+	}
+
+	return bonusSchedule{Version: version, Rates: rates, MaxLevel: maxLevel}, nil
+}
+
+func (s *ReferralService) resolveBonusSchedule(ctx context.Context, at int64) (bonusSchedule, error) {
+	s.logger.Infof("resolving bonus schedule in force at: %d", at)
+
+	schedule, err := s.referral_repository.GetBonusScheduleAt(ctx, at)
+	if err == mongo.ErrNoDocuments {
+		s.logger.Infof("no bonus schedule in force, using default rates")
+		return defaultBonusSchedule(), nil
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get bonus schedule: %v", err)
+		return bonusSchedule{}, errors.NewError(500, "failed to get bonus schedule")
+	}
+
+	resolved, err := bonusScheduleFromLevels(schedule.Version, schedule.Levels)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule: %v", err)
+		return bonusSchedule{}, errors.NewError(500, "failed to convert bonus schedule")
+	}
+
+	s.logger.Infof("bonus schedule resolved: version %d, rates %+v", resolved.Version, resolved.Rates)
+	return resolved, nil
+}
+
+func validateScheduleLevels(levels []referral_dto.ScheduleLevel) error {
+	seen := make(map[int]bool, len(levels))
+	for _, level := range levels {
+		if level.Rate.LessThanOrEqual(decimal.Zero) || level.Rate.GreaterThan(decimal.NewFromInt(1)) {
+			return errors.NewError(400, "rate must be greater than 0 and not greater than 1")
+		}
+		if seen[level.LevelNumber] {
+			return errors.NewError(400, "level numbers must be unique")
+		}
+		seen[level.LevelNumber] = true
+	}
+
+	for i := range levels {
+		if !seen[i] {
+			return errors.NewError(400, "level numbers must start at 0 and go without gaps")
+		}
+	}
+	return nil
+}
+
+// validateScheduleStart rejects a schedule coming into force before now, the
+// orders accrued since then were calculated with other rates.
+func validateScheduleStart(effectiveFrom int64) error {
+	if effectiveFrom <= time.Now().Unix() {
+		return errors.NewError(400, "effective_from must be in the future")
+	}
+	return nil
+}
+
+func (s *ReferralService) CreateBonusSchedule(ctx context.Context, req referral_dto.BonusScheduleRequest) (referral_dto.BonusSchedule, error) {
+	s.logger.Infof("creating bonus schedule: %+v", req)
+
+	if err := validateScheduleLevels(req.Levels); err != nil {
+		s.logger.Warnf("invalid bonus schedule levels: %v", err)
+		return referral_dto.BonusSchedule{}, err
+	}
+	if err := validateScheduleStart(req.EffectiveFrom); err != nil {
+		s.logger.Warnf("invalid bonus schedule start: %v", err)
+		return referral_dto.BonusSchedule{}, err
+	}
+
+	schedule, err := referral_adapters.CreateBonusScheduleFromDTO(req)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule to model: %v", err)
+		return referral_dto.BonusSchedule{}, errors.NewError(500, "failed to convert bonus schedule to model")
+	}
+
+	schedule, err = s.referral_repository.CreateBonusSchedule(ctx, schedule)
+	if err != nil {
+		s.logger.Errorf("failed to create bonus schedule: %v", err)
+		return referral_dto.BonusSchedule{}, errors.NewError(500, "failed to create bonus schedule")
+	}
+
+	scheduleDTO, err := referral_adapters.CreateBonusScheduleFromModel(schedule)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule to DTO: %v", err)
+		return referral_dto.BonusSchedule{}, errors.NewError(500, "failed to convert bonus schedule to DTO")
+	}
+
+	s.logger.Infof("bonus schedule created: version %d", scheduleDTO.Version)
+	return scheduleDTO, nil
+}
+
+// getEditableBonusSchedule loads a schedule that has not come into force yet.
+// Schedules already in force are immutable, orders reference them by version.
+func (s *ReferralService) getEditableBonusSchedule(ctx context.Context, scheduleID string) (referral_model.BonusSchedule, error) {
+	id, err := bson.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule ID to ObjectID: %v", err)
+		return referral_model.BonusSchedule{}, errors.NewError(400, "invalid bonus schedule ID")
+	}
+
+	schedule, err := s.referral_repository.GetBonusScheduleByID(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return referral_model.BonusSchedule{}, errors.NewError(404, "bonus schedule not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get bonus schedule: %v", err)
+		return referral_model.BonusSchedule{}, errors.NewError(500, "failed to get bonus schedule")
+	}
+
+	if schedule.EffectiveFrom <= time.Now().Unix() {
+		s.logger.Warnf("bonus schedule version %d is already in force", schedule.Version)
+		return referral_model.BonusSchedule{}, errors.NewError(409, "bonus schedule is already in force and can not be changed")
+	}
+
+	return schedule, nil
+}
+
+// bonusScheduleWriteError maps the error of a write to a schedule that was
+// planned when it was loaded, it may have come into force or been deleted since.
+func (s *ReferralService) bonusScheduleWriteError(action string, err error) error {
+	switch {
+	case err == mongo.ErrNoDocuments:
+		return errors.NewError(404, "bonus schedule not found")
+	case err == referral_repository.ErrBonusScheduleInForce:
+		return errors.NewError(409, "bonus schedule is already in force and can not be changed")
+	default:
+		s.logger.Errorf("failed to %s bonus schedule: %v", action, err)
+		return errors.NewError(500, "failed to "+action+" bonus schedule")
+	}
+}
+
+func (s *ReferralService) UpdateBonusSchedule(ctx context.Context, scheduleID string, req referral_dto.BonusScheduleRequest) (referral_dto.BonusSchedule, error) {
+	s.logger.Infof("updating bonus schedule %s: %+v", scheduleID, req)
+
+	if err := validateScheduleLevels(req.Levels); err != nil {
+		s.logger.Warnf("invalid bonus schedule levels: %v", err)
+		return referral_dto.BonusSchedule{}, err
+	}
+	if err := validateScheduleStart(req.EffectiveFrom); err != nil {
+		s.logger.Warnf("invalid bonus schedule start: %v", err)
+		return referral_dto.BonusSchedule{}, err
+	}
+
+	existing, err := s.getEditableBonusSchedule(ctx, scheduleID)
+	if err != nil {
+		return referral_dto.BonusSchedule{}, err
+	}
+
+	schedule, err := referral_adapters.CreateBonusScheduleFromDTO(req)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule to model: %v", err)
+		return referral_dto.BonusSchedule{}, errors.NewError(500, "failed to convert bonus schedule to model")
+	}
+	schedule.ID = existing.ID
+
+	schedule, err = s.referral_repository.UpdateBonusSchedule(ctx, schedule)
+	if err != nil {
+		return referral_dto.BonusSchedule{}, s.bonusScheduleWriteError("update", err)
+	}
+
+	scheduleDTO, err := referral_adapters.CreateBonusScheduleFromModel(schedule)
+	if err != nil {
+		s.logger.Errorf("failed to convert bonus schedule to DTO: %v", err)
+		return referral_dto.BonusSchedule{}, errors.NewError(500, "failed to convert bonus schedule to DTO")
+	}
+
+	return scheduleDTO, nil
+}
+
+func (s *ReferralService) DeleteBonusSchedule(ctx context.Context, scheduleID string) error {
+	s.logger.Infof("deleting bonus schedule %s", scheduleID)
+
+	schedule, err := s.getEditableBonusSchedule(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.referral_repository.DeleteBonusSchedule(ctx, schedule.ID); err != nil {
+		return s.bonusScheduleWriteError("delete", err)
+	}
+
+	return nil
+}
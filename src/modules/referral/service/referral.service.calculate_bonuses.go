@@ -2,55 +2,37 @@ package referral_service
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"iter"
 	"time"
 
 	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
 	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
 	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
-	"github.com/root9464/Go_GamlerDefi/src/packages/utils"
 
 	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	"github.com/tonkeeper/tonapi-go"
 	"github.com/xssnick/tonutils-go/address"
-	"github.com/xssnick/tonutils-go/tlb"
-	"github.com/xssnick/tonutils-go/ton/wallet"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 const (
-	url      = "https://serv.gamler.online/referral"
 	maxLevel = 2
 )
 
 var maxDebt = 10
 
-func (s *ReferralService) getReferrerChain(userID int) (*referral_dto.ReferrerResponse, error) {
-	s.logger.Infof("fetching referrer chain for user %d", userID)
-	resp, err := utils.Get[referral_dto.ReferrerResponse](
-		fmt.Sprintf("%s/referrer/%d", url, userID),
-	)
-	if err != nil {
-		s.logger.Errorf("failed to fetch referrer chain: %v", err)
-		return nil, err
-	}
-	return &resp, nil
-}
-
-func (s *ReferralService) getAuthorData(authorID int) (*referral_dto.ReferrerResponse, error) {
+func (s *ReferralService) getAuthorData(ctx context.Context, authorID int) (*referral_dto.ReferrerResponse, error) {
 	s.logger.Infof("fetching author data for user %d", authorID)
-	resp, err := utils.Get[referral_dto.ReferrerResponse](
-		fmt.Sprintf("%s/referrer/%d", url, authorID),
-	)
+	resp, err := s.referral_directory.GetReferrer(ctx, authorID)
 	if err != nil {
 		s.logger.Errorf("failed to fetch author data for user %d: %v", authorID, err)
 		return nil, err
 	}
-	return &resp, nil
+	return resp, nil
 }
 
 type ReferralLevel struct {
@@ -61,63 +43,51 @@ type ReferralLevel struct {
 	Err           error
 }
 
-func (s *ReferralService) referralChainIterator(req referral_dto.ReferralProcessRequest, bonusRates map[int]decimal.Decimal, maxLevel int) iter.Seq[ReferralLevel] {
+func (s *ReferralService) referralChainIterator(ctx context.Context, req referral_dto.ReferralProcessRequest, bonusRates map[int]decimal.Decimal, maxLevel int) iter.Seq[ReferralLevel] {
 	return func(yield func(ReferralLevel) bool) {
-		currentReferrerID := req.ReferrerID
-		referredID := req.ReferralID
-
-		s.logger.Infof("fetching first level referrer for user %d", currentReferrerID)
-		referrerL1, err := s.getReferrerChain(currentReferrerID)
-		if err != nil {
-			s.logger.Errorf("failed to fetch first level referrer for user %d: %v", currentReferrerID, err)
-			yield(ReferralLevel{Level: 0, Rate: decimal.NewFromFloat(0), ReferrerID: currentReferrerID, WalletAddress: "", Err: errors.NewError(500, "failed to fetch referrer chain")})
+		depth := 0
+		for depth <= maxLevel {
+			if _, ok := bonusRates[depth]; !ok {
+				break
+			}
+			depth++
+		}
+		if depth == 0 {
+			s.logger.Warnf("no bonus rate for level 0")
 			return
 		}
 
-		if !lo.ContainsBy(referrerL1.ReferredUsers, func(u referral_dto.ReferredUserResponse) bool { return u.UserID == referredID }) {
-			s.logger.Warnf("invalid first level referral: %+v", referredID)
-			yield(ReferralLevel{Level: 0, Rate: decimal.NewFromFloat(0), ReferrerID: currentReferrerID, WalletAddress: "", Err: errors.NewError(400, "invalid first level referral")})
+		s.logger.Infof("resolving referrer chain for user %d up to %d levels", req.ReferrerID, depth)
+		resolver := referral_directory.NewResolver(s.referral_directory)
+		chain, err := resolver.ResolveChain(ctx, req.ReferrerID, depth)
+		if err != nil && len(chain) == 0 {
+			s.logger.Errorf("failed to fetch first level referrer for user %d: %v", req.ReferrerID, err)
+			yield(ReferralLevel{Level: 0, Rate: decimal.NewFromFloat(0), ReferrerID: req.ReferrerID, WalletAddress: "", Err: errors.NewError(500, "failed to fetch referrer chain")})
 			return
 		}
 
-		for level := 0; level <= maxLevel; level++ {
-			rate, ok := bonusRates[level]
-			if !ok {
-				s.logger.Warnf("no bonus rate for level %d", level)
-				return
-			}
-
-			if currentReferrerID == 0 {
-				s.logger.Warnf("stopping referral chain at level %d", level+1)
-				return
-			}
-
-			referrerData, err := s.getReferrerChain(currentReferrerID)
-			if err != nil {
-				s.logger.Errorf("failed to fetch referrer data for user %d at level %d: %v", currentReferrerID, level, err)
-				yield(ReferralLevel{Level: level, Rate: rate, ReferrerID: currentReferrerID, WalletAddress: "", Err: err})
-				return
-			}
+		if !lo.ContainsBy(chain[0].ReferredUsers, func(u referral_dto.ReferredUserResponse) bool { return u.UserID == req.ReferralID }) {
+			s.logger.Warnf("invalid first level referral: %+v", req.ReferralID)
+			yield(ReferralLevel{Level: 0, Rate: decimal.NewFromFloat(0), ReferrerID: req.ReferrerID, WalletAddress: "", Err: errors.NewError(400, "invalid first level referral")})
+			return
+		}
 
-			if !yield(ReferralLevel{Level: level, Rate: rate, ReferrerID: currentReferrerID, WalletAddress: referrerData.WalletAddress, Err: nil}) {
+		for level, referrerData := range chain {
+			if !yield(ReferralLevel{Level: level, Rate: bonusRates[level], ReferrerID: referrerData.UserID, WalletAddress: referrerData.WalletAddress, Err: nil}) {
 				s.logger.Infof("stopping referral chain at level %d", level+1)
 				return
 			}
+		}
 
-			parentData, err := s.getReferrerChain(currentReferrerID)
-			if err != nil {
-				s.logger.Errorf("failed to fetch referrer chain for user %d at level %d: %v", currentReferrerID, level, err)
-				yield(ReferralLevel{Level: level, Rate: rate, ReferrerID: currentReferrerID, WalletAddress: "", Err: err})
-				return
-			}
-
-			if parentData.ReferrerID == 0 {
-				s.logger.Warnf("stopping referral chain at level %d", level+1)
-				return
-			}
-
-			currentReferrerID = parentData.ReferrerID
+		if err != nil {
+			level := len(chain)
+			referrerID := chain[level-1].ReferrerID
+			s.logger.Errorf("failed to fetch referrer data for user %d at level %d: %v", referrerID, level, err)
+			yield(ReferralLevel{Level: level, Rate: bonusRates[level], ReferrerID: referrerID, WalletAddress: "", Err: err})
+			return
 		}
+
+		s.logger.Infof("referral chain resolved: %d levels", len(chain))
 	}
 }
 
@@ -125,15 +95,16 @@ type ReferralBonusResult struct {
 	TotalBonusValue   decimal.Decimal
 	AccrualDictionary []referral_helper.JettonEntry
 	Levels            []referral_dto.LevelRequest
+	ScheduleVersion   int
 }
 
-func (s *ReferralService) calculateReferralBonuses(req referral_dto.ReferralProcessRequest, bonusRates map[int]decimal.Decimal, maxLevel int) (ReferralBonusResult, error) {
-	s.logger.Infof("calculating bonus for levels maxLevel: %d", maxLevel)
+func (s *ReferralService) calculateReferralBonuses(ctx context.Context, req referral_dto.ReferralProcessRequest, schedule bonusSchedule) (ReferralBonusResult, error) {
+	s.logger.Infof("calculating bonus for levels maxLevel: %d, schedule version: %d", schedule.MaxLevel, schedule.Version)
 	totalBonusValue := decimal.NewFromFloat(0)
 	accrualDictionary := []referral_helper.JettonEntry{}
 	levels := []referral_dto.LevelRequest{}
 
-	for referralLevel := range s.referralChainIterator(req, bonusRates, maxLevel) {
+	for referralLevel := range s.referralChainIterator(ctx, req, schedule.Rates, schedule.MaxLevel) {
 		if referralLevel.Err != nil {
 			s.logger.Errorf("error in referral chain at level %d: %v", referralLevel.Level, referralLevel.Err)
 			return ReferralBonusResult{
@@ -169,6 +140,7 @@ func (s *ReferralService) calculateReferralBonuses(req referral_dto.ReferralProc
 		TotalBonusValue:   totalBonusValue,
 		AccrualDictionary: accrualDictionary,
 		Levels:            levels,
+		ScheduleVersion:   schedule.Version,
 	}, nil
 }
 
@@ -226,11 +198,19 @@ func (s *ReferralService) getDebtFromAuthorToReferrer(ctx context.Context, autho
 	return debtDTO, nil
 }
 
-func (s *ReferralService) calculateDebtFromAuthor(ctx context.Context, authorID int) (decimal.Decimal, error) {
+// calculateDebtFromAuthor returns the current debt of the author together with
+// the debt limit of the author's referral program.
+func (s *ReferralService) calculateDebtFromAuthor(ctx context.Context, authorID int) (decimal.Decimal, decimal.Decimal, error) {
+	program, err := s.resolveReferralProgram(ctx, authorID, time.Now().Unix())
+	if err != nil {
+		s.logger.Errorf("failed to resolve referral program: %v", err)
+		return decimal.NewFromFloat(0), decimal.NewFromFloat(0), err
+	}
+
 	debt, err := s.getDebtFromAuthorToReferrer(ctx, authorID)
 	if err != nil {
 		s.logger.Errorf("failed to get debt from author to referrer: %v", err)
-		return decimal.NewFromFloat(0), errors.NewError(500, "failed to get debt from author to referrer")
+		return decimal.NewFromFloat(0), decimal.NewFromFloat(0), errors.NewError(500, "failed to get debt from author to referrer")
 	}
 
 	totalDebt := decimal.NewFromFloat(0)
@@ -238,16 +218,17 @@ func (s *ReferralService) calculateDebtFromAuthor(ctx context.Context, authorID
 		totalDebt = totalDebt.Add(order.TotalAmount)
 	}
 
-	return totalDebt, nil
+	return totalDebt, program.MaxDebt, nil
 }
 
-func (s *ReferralService) orderProcessing(ctx context.Context, orderDTO referral_dto.PaymentOrder) error {
+func (s *ReferralService) orderProcessing(ctx context.Context, orderDTO referral_dto.PaymentOrder, idempotencyKey string) error {
 	s.logger.Infof("starting order processing for: %+v", orderDTO)
 	order, err := referral_adapters.CreatePaymentOrderFromDTO(orderDTO)
 	if err != nil {
 		s.logger.Errorf("failed to convert order to DTO: %v", err)
 		return errors.NewError(500, "failed to convert order to DTO")
 	}
+	order.IdempotencyKeys = []string{idempotencyKey}
 
 	err = s.referral_repository.UpdatePaymentOrder(ctx, order)
 	if err == mongo.ErrNoDocuments {
@@ -267,134 +248,131 @@ func (s *ReferralService) orderProcessing(ctx context.Context, orderDTO referral
 	return nil
 }
 
-func (s *ReferralService) ReferralProcess(ctx context.Context, req referral_dto.ReferralProcessRequest) error {
+func (s *ReferralService) referralProcess(ctx context.Context, req referral_dto.ReferralProcessRequest, idempotencyKey string) (referral_dto.ReferralProcessResult, error) {
 	s.logger.Infof("starting referral bonus calculation for: %+v", req)
 
-	bonusRates := map[int]decimal.Decimal{
-		0: decimal.NewFromFloat(0.20), // Уровень 1: 20%
-		1: decimal.NewFromFloat(0.02), // Уровень 2: 2%
-	}
-
-	s.logger.Infof("bonusRates: %+v", bonusRates)
+	orderTime := time.Now().Unix()
 	s.logger.Infof("req.PaymentType: %+v | req.ReferrerID: %+v | req.ReferredID: %+v", req.PaymentType, req.ReferrerID, req.ReferralID)
 
 	switch req.PaymentType {
 	case referral_dto.PaymentPlatform:
 		s.logger.Infof("req.ReferredID: %+v | req.ReferrerID: %+v | req.TicketCount: %+v", req.ReferralID, req.ReferrerID, req.TicketCount)
 
-		bonusResult, err := s.calculateReferralBonuses(req, bonusRates, maxLevel)
+		schedule, err := s.resolveBonusSchedule(ctx, orderTime)
+		if err != nil {
+			s.logger.Errorf("failed to resolve bonus schedule: %v", err)
+			return referral_dto.ReferralProcessResult{}, err
+		}
+
+		bonusResult, err := s.calculateReferralBonuses(ctx, req, schedule)
 		if err != nil {
 			s.logger.Errorf("failed to calculate referral bonuses: %v", err)
-			return errors.NewError(500, "failed to calculate referral bonuses")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to calculate referral bonuses")
 		}
 		s.logger.Infof("bonus result: %+v", bonusResult)
 
 		jettonBalance, err := s.precheckoutBalance(s.config.PlatformSmartContract)
 		if err != nil {
 			s.logger.Errorf("failed to get jetton balance: %v", err)
-			return errors.NewError(500, "failed to get jetton balance")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to get jetton balance")
 		}
 		s.logger.Infof("jetton balance: %s", jettonBalance.String())
 
 		if jettonBalance.LessThan(bonusResult.TotalBonusValue) {
 			s.logger.Errorf("insufficient balance in smart contract for bonus: %s", bonusResult.TotalBonusValue.String())
-			return errors.NewError(400, "insufficient balance in smart contract")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(400, "insufficient balance in smart contract")
 		}
 
-		s.logger.Infof("creating a cell for a transaction with the values of referral bonus accruals")
-		cell, err := s.referral_helper.CellTransferJettonsFromPlatform(bonusResult.AccrualDictionary)
+		s.logger.Infof("queueing payout for referrer %d: %s", req.ReferrerID, bonusResult.TotalBonusValue.String())
+		payout, err := referral_adapters.CreatePayoutFromDTO(referral_dto.Payout{
+			ReferrerID:      req.ReferrerID,
+			ReferralID:      req.ReferralID,
+			TicketCount:     req.TicketCount,
+			TotalAmount:     bonusResult.TotalBonusValue,
+			Levels:          bonusResult.Levels,
+			ScheduleVersion: bonusResult.ScheduleVersion,
+			Status:          referral_dto.PayoutPending,
+			CreatedAt:       orderTime,
+		})
 		if err != nil {
-			s.logger.Errorf("failed to create cell: %v", err)
-			return errors.NewError(500, "failed to create cell")
+			s.logger.Errorf("failed to convert payout to model: %v", err)
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to convert payout to model")
 		}
+		payout.IdempotencyKey = idempotencyKey
 
-		s.logger.Infof("transaction cell was created successfully: %+v", cell)
-
-		s.logger.Infof("debiting balance for user %d: %s", req.ReferrerID, bonusResult.TotalBonusValue.String())
-		s.logger.Infof("wallet seed: %v", len(s.config.WalletSeed))
-		adminWallet, err := wallet.FromSeed(s.ton_client, s.config.WalletSeed, wallet.V4R2)
+		payout, err = s.referral_repository.CreatePayout(ctx, payout)
 		if err != nil {
-			s.logger.Errorf("failed to create wallet: %v", err)
-			return errors.NewError(500, "failed to create wallet")
+			s.logger.Errorf("failed to create payout: %v", err)
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to create payout")
 		}
 
-		s.logger.Infof("wallet created successfully: %+v", adminWallet.Address())
-
-		s.logger.Infof("sending a transaction to the smart contract")
-		tx, _, err := adminWallet.SendWaitTransaction(context.Background(), &wallet.Message{
-			Mode: wallet.PayGasSeparately,
-			InternalMessage: &tlb.InternalMessage{
-				Bounce:  true,
-				DstAddr: address.MustParseAddr(s.config.PlatformSmartContract),
-				Amount:  tlb.MustFromTON("0.1"),
-				Body:    cell,
-			},
-		})
-
-		if err != nil {
-			s.logger.Errorf("transaction execution failed with an error: %v", err)
-			return errors.NewError(500, "transaction execution failed")
-		}
-
-		s.logger.Info("transaction was completed successfully")
-		s.logger.Infof("the hash of the transaction: %s", base64.StdEncoding.EncodeToString(tx.Hash))
-		return nil
+		s.logger.Infof("payout %s queued successfully", payout.ID.Hex())
+		return referral_dto.ReferralProcessResult{Message: "Referral bonuses queued for payout", PayoutID: payout.ID.Hex()}, nil
 	case referral_dto.PaymentLeader:
 		s.logger.Infof("req.ReferredID: %+v | req.ReferrerID: %+v | req.TicketCount: %+v | req.Amount: %+v", req.ReferralID, req.ReferrerID, req.TicketCount, req.LeaderID)
 		if req.LeaderID == 0 {
 			s.logger.Warnf("leader ID is required for payment type %s", req.PaymentType)
-			return errors.NewError(400, "leader ID is required for payment type referred")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(400, "leader ID is required for payment type referred")
 		}
 
 		s.logger.Infof("fetching author data for user_id=%d", req.LeaderID)
-		authorData, err := s.getAuthorData(req.LeaderID)
+		authorData, err := s.getAuthorData(ctx, req.LeaderID)
 		if err != nil {
 			s.logger.Errorf("failed to fetch author data: %v", err)
-			return errors.NewError(500, "failed to fetch author data")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to fetch author data")
 		}
 
 		s.logger.Infof("author data fetched successfully: %+v", authorData)
 
-		bonusResult, err := s.calculateReferralBonuses(req, bonusRates, maxLevel)
+		program, err := s.resolveReferralProgram(ctx, req.LeaderID, orderTime)
+		if err != nil {
+			s.logger.Errorf("failed to resolve referral program: %v", err)
+			return referral_dto.ReferralProcessResult{}, err
+		}
+
+		bonusResult, err := s.calculateReferralBonuses(ctx, req, program.Schedule)
 		if err != nil {
 			s.logger.Errorf("failed to calculate referral bonuses: %v", err)
-			return errors.NewError(500, "failed to calculate referral bonuses")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to calculate referral bonuses")
 		}
 		s.logger.Infof("bonus result: %+v", bonusResult)
 
-		debt, err := s.calculateDebtFromAuthor(ctx, req.LeaderID)
+		debt, debtLimit, err := s.calculateDebtFromAuthor(ctx, req.LeaderID)
 		if err != nil {
 			s.logger.Errorf("failed to calculate debt from author: %v", err)
-			return errors.NewError(500, "failed to calculate debt from author")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to calculate debt from author")
 		}
 		s.logger.Infof("debt: %s", debt.String())
-		s.logger.Infof("maxDebt: %d", maxDebt)
-		if debt.GreaterThan(decimal.NewFromInt(int64(maxDebt))) {
+		s.logger.Infof("maxDebt: %s", debtLimit.String())
+		if debt.GreaterThan(debtLimit) {
 			s.logger.Warnf("the author: %d has too much debt: %s", req.LeaderID, debt.String())
-			return errors.NewError(400, fmt.Sprintf("the author: %d has too much debt", req.LeaderID))
+			return referral_dto.ReferralProcessResult{}, errors.NewError(400, fmt.Sprintf("the author: %d has too much debt", req.LeaderID))
 		}
 
 		s.logger.Info("precheckout order in database")
 		orderDTO := referral_dto.PaymentOrder{
-			LeaderID:    req.LeaderID,
-			ReferrerID:  req.ReferrerID,
-			ReferralID:  req.ReferralID,
-			TotalAmount: bonusResult.TotalBonusValue,
-			TicketCount: req.TicketCount,
-			Levels:      bonusResult.Levels,
-			CreatedAt:   time.Now().Unix(),
+			LeaderID:        req.LeaderID,
+			ReferrerID:      req.ReferrerID,
+			ReferralID:      req.ReferralID,
+			TotalAmount:     bonusResult.TotalBonusValue,
+			TicketCount:     req.TicketCount,
+			Levels:          bonusResult.Levels,
+			CreatedAt:       orderTime,
+			ScheduleVersion: bonusResult.ScheduleVersion,
+			ScheduleSource:  program.Source,
+			ProgramVersion:  program.ProgramVersion,
 		}
 
-		err = s.orderProcessing(ctx, orderDTO)
+		err = s.orderProcessing(ctx, orderDTO, idempotencyKey)
 		if err != nil {
 			s.logger.Errorf("failed to precheckout order: %v", err)
-			return errors.NewError(500, "failed to precheckout order")
+			return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to precheckout order")
 		}
 
 		s.logger.Info("order prechecked successfully")
-		return nil
+		return referral_dto.ReferralProcessResult{Message: "Payment order created successfully"}, nil
 	default:
 		s.logger.Errorf("invalid payment type: %s", req.PaymentType)
-		return errors.NewError(400, "invalid payment type")
+		return referral_dto.ReferralProcessResult{}, errors.NewError(400, "invalid payment type")
 	}
 }
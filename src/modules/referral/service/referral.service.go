@@ -4,9 +4,11 @@ import (
 	"context"
 
 	"github.com/root9464/Go_GamlerDefi/src/config"
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
 	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
 	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
 	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 	"github.com/shopspring/decimal"
 	"github.com/tonkeeper/tonapi-go"
@@ -14,11 +16,26 @@ import (
 )
 
 type IReferralService interface {
-	ReferralProcess(ctx context.Context, referrer referral_dto.ReferralProcessRequest) error
+	ReferralProcess(ctx context.Context, referrer referral_dto.ReferralProcessRequest, idempotencyKey string) (referral_dto.ReferralProcessResult, error)
 	PayPaymentOrder(ctx context.Context, paymentOrderID string, walletAddress string) (string, error)
-	PayAllPaymentOrders(ctx context.Context, authorID int, walletAddress string) (string, error)
+	PayAllPaymentOrders(ctx context.Context, authorID int, walletAddress string) (string, string, error)
+	CancelPaymentOrder(ctx context.Context, paymentOrderID string) error
+	CancelAllPaymentOrders(ctx context.Context, authorID int) error
+	AddTrHashToPaymentOrder(ctx context.Context, paymentOrderID string, trHash string) error
+	MarkPaymentOrderPaid(ctx context.Context, paymentOrderID string, trHash string) error
+	ReopenPaymentOrder(ctx context.Context, paymentOrderID string) error
+	MarkPaymentBundlePaid(ctx context.Context, bundleID string, trHash string) error
+	ReopenPaymentBundle(ctx context.Context, bundleID string) error
+	PaymentExpectation(ctx context.Context, paymentOrderID string, bundleID string) (validation_dto.PaymentExpectation, error)
 	AssessInvitationAbility(ctx context.Context, authorID int) (bool, error)
 	CalculateAuthorDebt(ctx context.Context, authorID int) (decimal.Decimal, error)
+
+	CreateBonusSchedule(ctx context.Context, req referral_dto.BonusScheduleRequest) (referral_dto.BonusSchedule, error)
+	UpdateBonusSchedule(ctx context.Context, scheduleID string, req referral_dto.BonusScheduleRequest) (referral_dto.BonusSchedule, error)
+	DeleteBonusSchedule(ctx context.Context, scheduleID string) error
+
+	UpsertLeaderProgram(ctx context.Context, leaderID int, req referral_dto.LeaderProgramRequest) (referral_dto.LeaderProgram, error)
+	DeleteLeaderProgram(ctx context.Context, leaderID int) error
 }
 
 type ReferralService struct {
@@ -29,6 +46,7 @@ type ReferralService struct {
 
 	referral_helper     referral_helper.IReferralHelper
 	referral_repository referral_repository.IReferralRepository
+	referral_directory  referral_directory.IReferrerDirectory
 }
 
 func NewReferralService(
@@ -39,6 +57,7 @@ func NewReferralService(
 
 	referral_helper referral_helper.IReferralHelper,
 	referral_repository referral_repository.IReferralRepository,
+	referral_directory referral_directory.IReferrerDirectory,
 ) IReferralService {
 	return &ReferralService{
 		logger:              logger,
@@ -47,5 +66,6 @@ func NewReferralService(
 		config:              config,
 		referral_helper:     referral_helper,
 		referral_repository: referral_repository,
+		referral_directory:  referral_directory,
 	}
 }
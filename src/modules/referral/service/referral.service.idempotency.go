@@ -0,0 +1,142 @@
+package referral_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLease bounds how long a request may hold its key while
+	// processing, a claim left behind by a crash is taken over after it.
+	idempotencyLease     = 5 * time.Minute
+	maxIdempotencyKeyLen = 255
+)
+
+// referralFingerprint identifies the payload of a referral request, the same
+// key sent with a different payload is rejected.
+func referralFingerprint(req referral_dto.ReferralProcessRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%d:%d",
+		req.PaymentType, req.LeaderID, req.ReferrerID, req.ReferralID, req.TicketCount)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReferralProcess runs the referral request at most once per idempotency key.
+// The key is required, two purchases of the same size by the same users are
+// told apart only by it.
+func (s *ReferralService) ReferralProcess(ctx context.Context, req referral_dto.ReferralProcessRequest, idempotencyKey string) (referral_dto.ReferralProcessResult, error) {
+	if idempotencyKey == "" {
+		return referral_dto.ReferralProcessResult{}, errors.NewError(400, "idempotency key is required")
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLen {
+		return referral_dto.ReferralProcessResult{}, errors.NewError(400, "idempotency key is too long")
+	}
+
+	fingerprint := referralFingerprint(req)
+
+	now := time.Now()
+	record, claimed, err := s.referral_repository.ClaimIdempotencyKey(ctx, referral_model.IdempotencyRecord{
+		Key:         idempotencyKey,
+		Fingerprint: fingerprint,
+		Status:      referral_model.IdempotencyProcessing,
+		CreatedAt:   now.Unix(),
+		UpdatedAt:   now.Unix(),
+		LeaseUntil:  now.Add(idempotencyLease).Unix(),
+		ExpiresAt:   now.Add(idempotencyTTL),
+	})
+	if err != nil {
+		s.logger.Errorf("failed to claim idempotency key: %v", err)
+		return referral_dto.ReferralProcessResult{}, errors.NewError(500, "failed to claim idempotency key")
+	}
+
+	if !claimed {
+		return s.replayReferralProcess(record, fingerprint)
+	}
+
+	if record.Attempts > 1 {
+		result, found, err := s.previousReferralProcess(ctx, idempotencyKey)
+		if err != nil {
+			return referral_dto.ReferralProcessResult{}, err
+		}
+		if found {
+			s.logger.Infof("idempotency key %s was already processed by a previous attempt", idempotencyKey)
+			if err := s.completeReferralProcess(ctx, idempotencyKey, result); err != nil {
+				return referral_dto.ReferralProcessResult{}, err
+			}
+			return result, nil
+		}
+	}
+
+	result, err := s.referralProcess(ctx, req, idempotencyKey)
+	if err != nil {
+		if releaseErr := s.referral_repository.ReleaseIdempotencyKey(ctx, idempotencyKey); releaseErr != nil {
+			s.logger.Errorf("failed to release idempotency key %s: %v", idempotencyKey, releaseErr)
+		}
+		return referral_dto.ReferralProcessResult{}, err
+	}
+
+	if err := s.completeReferralProcess(ctx, idempotencyKey, result); err != nil {
+		return referral_dto.ReferralProcessResult{}, err
+	}
+
+	return result, nil
+}
+
+// completeReferralProcess stores the outcome under the key. A failure is
+// returned, the retry takes the claim over once its lease runs out and picks
+// up the stored payout or order instead of accruing again.
+func (s *ReferralService) completeReferralProcess(ctx context.Context, idempotencyKey string, result referral_dto.ReferralProcessResult) error {
+	stored := referral_model.IdempotencyResult{StatusCode: 201, Message: result.Message, PayoutID: result.PayoutID}
+	if err := s.referral_repository.CompleteIdempotencyKey(ctx, idempotencyKey, referral_model.IdempotencyCompleted, stored); err != nil {
+		s.logger.Errorf("failed to store outcome for idempotency key %s: %v", idempotencyKey, err)
+		return errors.NewError(500, "failed to store outcome of the request")
+	}
+	return nil
+}
+
+// previousReferralProcess looks up the payout or payment order a previous
+// attempt with the key stored before it crashed or failed to complete the key.
+func (s *ReferralService) previousReferralProcess(ctx context.Context, idempotencyKey string) (referral_dto.ReferralProcessResult, bool, error) {
+	payout, err := s.referral_repository.GetPayoutByIdempotencyKey(ctx, idempotencyKey)
+	if err == nil {
+		return referral_dto.ReferralProcessResult{Message: "Referral bonuses queued for payout", PayoutID: payout.ID.Hex()}, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		s.logger.Errorf("failed to get payout by idempotency key: %v", err)
+		return referral_dto.ReferralProcessResult{}, false, errors.NewError(500, "failed to get payout")
+	}
+
+	_, err = s.referral_repository.GetPaymentOrderByIdempotencyKey(ctx, idempotencyKey)
+	if err == nil {
+		return referral_dto.ReferralProcessResult{Message: "Payment order created successfully"}, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		s.logger.Errorf("failed to get payment order by idempotency key: %v", err)
+		return referral_dto.ReferralProcessResult{}, false, errors.NewError(500, "failed to get payment order")
+	}
+
+	return referral_dto.ReferralProcessResult{}, false, nil
+}
+
+func (s *ReferralService) replayReferralProcess(record referral_model.IdempotencyRecord, fingerprint string) (referral_dto.ReferralProcessResult, error) {
+	if record.Fingerprint != fingerprint {
+		s.logger.Warnf("idempotency key %s reused with a different payload", record.Key)
+		return referral_dto.ReferralProcessResult{}, errors.NewError(422, "idempotency key was used with a different request")
+	}
+
+	s.logger.Infof("replaying idempotency key %s with status %s", record.Key, record.Status)
+	switch record.Status {
+	case referral_model.IdempotencyCompleted:
+		return referral_dto.ReferralProcessResult{Message: record.Result.Message, PayoutID: record.Result.PayoutID}, nil
+	default:
+		return referral_dto.ReferralProcessResult{}, errors.NewError(409, "request with this idempotency key is still processing")
+	}
+}
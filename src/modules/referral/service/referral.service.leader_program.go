@@ -0,0 +1,134 @@
+package referral_service
+
+import (
+	"context"
+
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	scheduleSourcePlatform = "platform"
+	scheduleSourceLeader   = "leader"
+)
+
+// referralProgram is the program an order is calculated with. Schedule.Version
+// is always the platform schedule in force, ProgramVersion the leader program
+// applied on top of it, 0 without one.
+type referralProgram struct {
+	Schedule       bonusSchedule
+	Source         string
+	MaxDebt        decimal.Decimal
+	ProgramVersion int
+}
+
+// resolveReferralProgram applies the leader override on top of the platform
+// schedule in force at `at`. Leaders without an override get the platform default.
+func (s *ReferralService) resolveReferralProgram(ctx context.Context, leaderID int, at int64) (referralProgram, error) {
+	s.logger.Infof("resolving referral program for leader: %d", leaderID)
+
+	schedule, err := s.resolveBonusSchedule(ctx, at)
+	if err != nil {
+		return referralProgram{}, err
+	}
+
+	program := referralProgram{
+		Schedule: schedule,
+		Source:   scheduleSourcePlatform,
+		MaxDebt:  decimal.NewFromInt(int64(maxDebt)),
+	}
+
+	leaderProgram, err := s.referral_repository.GetLeaderProgram(ctx, leaderID)
+	if err == mongo.ErrNoDocuments {
+		s.logger.Infof("no custom program for leader %d, using platform default", leaderID)
+		return program, nil
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get leader program: %v", err)
+		return referralProgram{}, errors.NewError(500, "failed to get leader program")
+	}
+
+	program.ProgramVersion = leaderProgram.Version
+
+	if len(leaderProgram.Levels) > 0 {
+		custom, err := bonusScheduleFromLevels(schedule.Version, leaderProgram.Levels)
+		if err != nil {
+			s.logger.Errorf("failed to convert leader program rates: %v", err)
+			return referralProgram{}, errors.NewError(500, "failed to convert leader program rates")
+		}
+		program.Schedule = custom
+		program.Source = scheduleSourceLeader
+	}
+
+	if leaderProgram.Depth > 0 {
+		program.Schedule.MaxLevel = min(program.Schedule.MaxLevel, leaderProgram.Depth-1)
+		program.Source = scheduleSourceLeader
+	}
+
+	if leaderProgram.MaxDebt != nil {
+		limit, err := decimal.NewFromString(leaderProgram.MaxDebt.String())
+		if err != nil {
+			s.logger.Errorf("failed to convert leader debt limit: %v", err)
+			return referralProgram{}, errors.NewError(500, "failed to convert leader debt limit")
+		}
+		program.MaxDebt = limit
+	}
+
+	s.logger.Infof("referral program resolved: source %s, schedule version %d, program version %d, max level %d, max debt %s",
+		program.Source, program.Schedule.Version, program.ProgramVersion, program.Schedule.MaxLevel, program.MaxDebt.String())
+	return program, nil
+}
+
+func (s *ReferralService) UpsertLeaderProgram(ctx context.Context, leaderID int, req referral_dto.LeaderProgramRequest) (referral_dto.LeaderProgram, error) {
+	s.logger.Infof("upserting leader program for leader %d: %+v", leaderID, req)
+
+	if len(req.Levels) > 0 {
+		if err := validateScheduleLevels(req.Levels); err != nil {
+			s.logger.Warnf("invalid leader program levels: %v", err)
+			return referral_dto.LeaderProgram{}, err
+		}
+	}
+
+	if req.MaxDebt != nil && req.MaxDebt.IsNegative() {
+		s.logger.Warnf("invalid leader debt limit: %s", req.MaxDebt.String())
+		return referral_dto.LeaderProgram{}, errors.NewError(400, "max debt must not be negative")
+	}
+
+	program, err := referral_adapters.CreateLeaderProgramFromDTO(leaderID, req)
+	if err != nil {
+		s.logger.Errorf("failed to convert leader program to model: %v", err)
+		return referral_dto.LeaderProgram{}, errors.NewError(500, "failed to convert leader program to model")
+	}
+
+	program, err = s.referral_repository.CreateLeaderProgram(ctx, program)
+	if err != nil {
+		s.logger.Errorf("failed to store leader program: %v", err)
+		return referral_dto.LeaderProgram{}, errors.NewError(500, "failed to store leader program")
+	}
+
+	programDTO, err := referral_adapters.CreateLeaderProgramFromModel(program)
+	if err != nil {
+		s.logger.Errorf("failed to convert leader program to DTO: %v", err)
+		return referral_dto.LeaderProgram{}, errors.NewError(500, "failed to convert leader program to DTO")
+	}
+
+	return programDTO, nil
+}
+
+func (s *ReferralService) DeleteLeaderProgram(ctx context.Context, leaderID int) error {
+	s.logger.Infof("deleting leader program for leader %d", leaderID)
+
+	err := s.referral_repository.DeleteLeaderProgram(ctx, leaderID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "leader program not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to delete leader program: %v", err)
+		return errors.NewError(500, "failed to delete leader program")
+	}
+
+	return nil
+}
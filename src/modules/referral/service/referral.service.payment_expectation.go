@@ -0,0 +1,81 @@
+package referral_service
+
+import (
+	"context"
+
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/shopspring/decimal"
+	"github.com/xssnick/tonutils-go/address"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PaymentExpectation returns the transfers the pay transaction of the order, or
+// of every order of the bundle when bundleID is set, has to make. It mirrors
+// the dictionary built by PayPaymentOrder and PayAllPaymentOrders.
+func (s *ReferralService) PaymentExpectation(ctx context.Context, paymentOrderID string, bundleID string) (validation_dto.PaymentExpectation, error) {
+	s.logger.Infof("building payment expectation for order %q, bundle %q", paymentOrderID, bundleID)
+
+	var orders []referral_model.PaymentOrder
+	if bundleID != "" {
+		bundle, err := s.referral_repository.GetPaymentOrdersByBundleID(ctx, bundleID)
+		if err != nil {
+			s.logger.Errorf("failed to get payment orders of bundle: %v", err)
+			return validation_dto.PaymentExpectation{}, errors.NewError(500, "failed to get payment orders of bundle")
+		}
+		orders = bundle
+	} else {
+		orderID, err := bson.ObjectIDFromHex(paymentOrderID)
+		if err != nil {
+			s.logger.Errorf("failed to convert payment order ID to ObjectID: %v", err)
+			return validation_dto.PaymentExpectation{}, errors.NewError(400, "invalid payment order ID")
+		}
+
+		order, err := s.referral_repository.GetPaymentOrderByID(ctx, orderID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			s.logger.Errorf("failed to get payment order: %v", err)
+			return validation_dto.PaymentExpectation{}, errors.NewError(500, "failed to get payment order")
+		}
+		if err == nil {
+			orders = append(orders, order)
+		}
+	}
+
+	if len(orders) == 0 {
+		return validation_dto.PaymentExpectation{}, errors.NewError(404, "no payment orders found")
+	}
+
+	ordersDTO, err := referral_adapters.CreatePaymentOrderFromModelList(orders)
+	if err != nil {
+		s.logger.Errorf("failed to convert payment orders to DTO: %v", err)
+		return validation_dto.PaymentExpectation{}, errors.NewError(500, "failed to convert payment orders to DTO")
+	}
+
+	totalAmount := decimal.NewFromInt(0)
+	entries := []referral_helper.JettonEntry{}
+	for _, order := range ordersDTO {
+		totalAmount = totalAmount.Add(order.TotalAmount)
+		for _, level := range order.Levels {
+			levelAddress, err := address.ParseAddr(level.Address)
+			if err != nil {
+				s.logger.Errorf("invalid level address %s: %v", level.Address, err)
+				return validation_dto.PaymentExpectation{}, errors.NewError(500, "invalid level address in payment order")
+			}
+			entries = append(entries, referral_helper.JettonEntry{Address: levelAddress, Amount: level.Amount})
+		}
+	}
+
+	expectation := validation_dto.PaymentExpectation{TotalAmount: totalAmount}
+	for _, entry := range referral_helper.MergeJettonEntries(entries) {
+		expectation.Recipients = append(expectation.Recipients, validation_dto.ExpectedTransfer{
+			Address: entry.Address.String(),
+			Amount:  entry.Amount,
+		})
+	}
+
+	return expectation, nil
+}
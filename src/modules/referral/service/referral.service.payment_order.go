@@ -6,6 +6,7 @@ import (
 
 	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
 	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
 	"github.com/shopspring/decimal"
 	"github.com/xssnick/tonutils-go/address"
@@ -29,6 +30,13 @@ func (s *ReferralService) PayPaymentOrder(ctx context.Context, paymentOrderID st
 
 	s.logger.Infof("payment order fetched successfully: %+v", paymentOrder)
 
+	// an awaiting order already has a transaction built, a second one could
+	// pay the order twice
+	if status := paymentOrder.CurrentStatus(); status != referral_model.PaymentOrderStatusOpen {
+		s.logger.Errorf("payment order %s is %s and can not be paid", paymentOrderID, status)
+		return "", errors.NewError(409, "payment order is "+string(status))
+	}
+
 	s.logger.Infof("converting payment order to DTO")
 	paymentOrderDTO, err := referral_adapters.CreatePaymentOrderFromModel(paymentOrder)
 	if err != nil {
@@ -51,7 +59,7 @@ func (s *ReferralService) PayPaymentOrder(ctx context.Context, paymentOrderID st
 	}
 
 	s.logger.Infof("fetching author data for user_id=%d", paymentOrderDTO.LeaderID)
-	authorData, err := s.getAuthorData(paymentOrderDTO.LeaderID)
+	authorData, err := s.getAuthorData(ctx, paymentOrderDTO.LeaderID)
 	if err != nil {
 		s.logger.Errorf("failed to get author data: %v", err)
 		return "", errors.NewError(500, "failed to get author data")
@@ -81,6 +89,7 @@ func (s *ReferralService) PayPaymentOrder(ctx context.Context, paymentOrderID st
 			Amount:  level.Amount,
 		})
 	}
+	accrualDictionary = referral_helper.MergeJettonEntries(accrualDictionary)
 	s.logger.Infof("accrual dictionary created successfully: %+v", accrualDictionary)
 
 	s.logger.Infof("creating a cell for a transaction with the values of referral bonus accruals")
@@ -92,17 +101,31 @@ func (s *ReferralService) PayPaymentOrder(ctx context.Context, paymentOrderID st
 
 	s.logger.Infof("transaction cell was created successfully: %+v", cell)
 
+	s.logger.Infof("moving payment order %s to awaiting payment", paymentOrderID)
+	if err := s.updatePaymentOrderStatus(ctx, orderID, referral_model.PaymentOrderStatusAwaitingPayment, ""); err != nil {
+		s.logger.Errorf("failed to move payment order to awaiting payment: %v", err)
+		return "", err
+	}
+
 	return base64.StdEncoding.EncodeToString(cell.ToBOC()), nil
 }
 
-func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int, walletAddress string) (string, error) {
+// PayAllPaymentOrders builds one transaction paying every unpaid order of the
+// author and bundles the orders under the returned bundle ID, so validating
+// that transaction settles all of them.
+func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int, walletAddress string) (string, string, error) {
 	s.logger.Infof("start pay all payment orders for user_id=%d", authorID)
 
 	s.logger.Infof("fetching payment orders in database by author_id: %d", authorID)
 	paymentOrders, err := s.referral_repository.GetPaymentOrdersByAuthorID(ctx, authorID)
 	if err != nil {
 		s.logger.Errorf("failed to get payment orders: %v", err)
-		return "", errors.NewError(500, "failed to get payment orders")
+		return "", "", errors.NewError(500, "failed to get payment orders")
+	}
+
+	if len(paymentOrders) == 0 {
+		s.logger.Infof("author %d has no unpaid payment orders", authorID)
+		return "", "", errors.NewError(404, "no unpaid payment orders")
 	}
 
 	s.logger.Infof("payment orders fetched successfully: %+v", paymentOrders)
@@ -111,16 +134,16 @@ func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int,
 	paymentOrderDTO, err := referral_adapters.CreatePaymentOrderFromModelList(paymentOrders)
 	if err != nil {
 		s.logger.Errorf("failed to convert payment order to DTO: %v", err)
-		return "", errors.NewError(500, "failed to convert payment order to DTO")
+		return "", "", errors.NewError(500, "failed to convert payment order to DTO")
 	}
 
 	s.logger.Infof("converted payment order to DTO: %+v", paymentOrderDTO)
 
 	s.logger.Infof("fetching author data for user_id=%d", authorID)
-	authorData, err := s.getAuthorData(authorID)
+	authorData, err := s.getAuthorData(ctx, authorID)
 	if err != nil {
 		s.logger.Errorf("failed to get author data: %v", err)
-		return "", errors.NewError(500, "failed to get author data")
+		return "", "", errors.NewError(500, "failed to get author data")
 	}
 
 	s.logger.Infof("author data fetched successfully: %+v", authorData)
@@ -129,7 +152,7 @@ func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int,
 	balance, err := s.precheckoutBalance(walletAddress)
 	if err != nil {
 		s.logger.Errorf("failed to get balance of author wallet: %v", err)
-		return "", errors.NewError(500, "failed to get balance of author wallet")
+		return "", "", errors.NewError(500, "failed to get balance of author wallet")
 	}
 
 	s.logger.Infof("balance of author wallet: %s", balance.String())
@@ -141,7 +164,7 @@ func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int,
 
 	if balance.LessThan(totalAmount) {
 		s.logger.Infof("insufficient funds on the balance sheet to pay the debt: %s", totalAmount.String())
-		return "", errors.NewError(402, "insufficient funds on the balance sheet to pay the debt")
+		return "", "", errors.NewError(402, "insufficient funds on the balance sheet to pay the debt")
 	}
 
 	s.logger.Infof("creating accrual dictionary for payment order")
@@ -155,16 +178,39 @@ func (s *ReferralService) PayAllPaymentOrders(ctx context.Context, authorID int,
 		}
 	}
 
+	accrualDictionary = referral_helper.MergeJettonEntries(accrualDictionary)
 	s.logger.Infof("accrual dictionary created successfully: %+v", accrualDictionary)
 
 	s.logger.Infof("creating a cell for a transaction with the values of referral bonus accruals")
 	cell, err := s.referral_helper.CellTransferJettonsFromLeader(accrualDictionary, totalAmount)
 	if err != nil {
 		s.logger.Errorf("failed to create cell: %v", err)
-		return "", errors.NewError(500, "failed to create cell")
+		return "", "", errors.NewError(500, "failed to create cell")
 	}
 
 	s.logger.Infof("transaction cell was created successfully: %+v", cell)
 
-	return base64.StdEncoding.EncodeToString(cell.ToBOC()), nil
+	bundleID := bson.NewObjectID().Hex()
+	orderIDs := make([]bson.ObjectID, len(paymentOrders))
+	for i, paymentOrder := range paymentOrders {
+		orderIDs[i] = paymentOrder.ID
+	}
+
+	s.logger.Infof("bundling %d payment orders into bundle: %s", len(orderIDs), bundleID)
+	bundled, err := s.referral_repository.BundlePaymentOrders(ctx, orderIDs, bundleID)
+	if err != nil {
+		s.logger.Errorf("failed to bundle payment orders: %v", err)
+		return "", "", errors.NewError(500, "failed to bundle payment orders")
+	}
+
+	if bundled != int64(len(orderIDs)) {
+		// an order was paid, cancelled or expired while the cell was built
+		s.logger.Errorf("only %d of %d payment orders were bundled", bundled, len(orderIDs))
+		if err := s.ReopenPaymentBundle(ctx, bundleID); err != nil {
+			return "", "", err
+		}
+		return "", "", errors.NewError(409, "payment orders changed, request the payment again")
+	}
+
+	return base64.StdEncoding.EncodeToString(cell.ToBOC()), bundleID, nil
 }
@@ -0,0 +1,171 @@
+package referral_service
+
+import (
+	"context"
+
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *ReferralService) transitionPaymentOrder(ctx context.Context, paymentOrderID string, status referral_model.PaymentOrderStatus, trHash string) error {
+	orderID, err := bson.ObjectIDFromHex(paymentOrderID)
+	if err != nil {
+		s.logger.Errorf("failed to convert payment order ID to ObjectID: %v", err)
+		return errors.NewError(400, "invalid payment order ID")
+	}
+
+	return s.updatePaymentOrderStatus(ctx, orderID, status, trHash)
+}
+
+func (s *ReferralService) updatePaymentOrderStatus(ctx context.Context, orderID bson.ObjectID, status referral_model.PaymentOrderStatus, trHash string) error {
+	_, err := s.referral_repository.UpdatePaymentOrderStatus(ctx, orderID, status, trHash)
+	switch {
+	case err == nil:
+		return nil
+	case err == mongo.ErrNoDocuments:
+		return errors.NewError(404, "payment order not found")
+	case err == referral_repository.ErrPaymentOrderTransition:
+		return errors.NewError(409, "payment order can not be moved to status "+string(status))
+	default:
+		s.logger.Errorf("failed to update payment order status: %v", err)
+		return errors.NewError(500, "failed to update payment order status")
+	}
+}
+
+func (s *ReferralService) CancelPaymentOrder(ctx context.Context, paymentOrderID string) error {
+	s.logger.Infof("cancelling payment order: %s", paymentOrderID)
+	return s.transitionPaymentOrder(ctx, paymentOrderID, referral_model.PaymentOrderStatusCancelled, "")
+}
+
+func (s *ReferralService) CancelAllPaymentOrders(ctx context.Context, authorID int) error {
+	s.logger.Infof("cancelling all payment orders of author ID: %d", authorID)
+
+	cancelled, err := s.referral_repository.CancelAllPaymentOrders(ctx, authorID)
+	if err != nil {
+		s.logger.Errorf("failed to cancel payment orders: %v", err)
+		return errors.NewError(500, "failed to cancel payment orders")
+	}
+
+	s.logger.Infof("cancelled %d payment orders", cancelled)
+	return nil
+}
+
+// AddTrHashToPaymentOrder stores the hash of the transaction paying the order.
+// An open order moves to awaiting payment on the way.
+func (s *ReferralService) AddTrHashToPaymentOrder(ctx context.Context, paymentOrderID string, trHash string) error {
+	s.logger.Infof("adding tr hash %s to payment order: %s", trHash, paymentOrderID)
+
+	orderID, err := bson.ObjectIDFromHex(paymentOrderID)
+	if err != nil {
+		s.logger.Errorf("failed to convert payment order ID to ObjectID: %v", err)
+		return errors.NewError(400, "invalid payment order ID")
+	}
+
+	order, err := s.referral_repository.GetPaymentOrderByID(ctx, orderID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewError(404, "payment order not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get payment order: %v", err)
+		return errors.NewError(500, "failed to get payment order")
+	}
+
+	switch order.CurrentStatus() {
+	case referral_model.PaymentOrderStatusOpen:
+		return s.updatePaymentOrderStatus(ctx, orderID, referral_model.PaymentOrderStatusAwaitingPayment, trHash)
+	case referral_model.PaymentOrderStatusAwaitingPayment:
+		err := s.referral_repository.AddTrHashToPaymentOrder(ctx, orderID, trHash)
+		if err == mongo.ErrNoDocuments {
+			// the order left awaiting payment between the read and the update
+			return errors.NewError(409, "payment order is no longer awaiting payment")
+		}
+		if err != nil {
+			s.logger.Errorf("failed to add tr hash to payment order: %v", err)
+			return errors.NewError(500, "failed to add tr hash to payment order")
+		}
+		return nil
+	default:
+		s.logger.Warnf("payment order %s is %s, tr hash is not accepted", paymentOrderID, order.CurrentStatus())
+		return errors.NewError(409, "payment order is "+string(order.CurrentStatus()))
+	}
+}
+
+// MarkPaymentOrderPaid is called once the payment transaction of the order was
+// validated. An order already paid by the same transaction is left as is, so
+// the validation can retry the call.
+func (s *ReferralService) MarkPaymentOrderPaid(ctx context.Context, paymentOrderID string, trHash string) error {
+	s.logger.Infof("marking payment order %s as paid by %s", paymentOrderID, trHash)
+
+	err := s.transitionPaymentOrder(ctx, paymentOrderID, referral_model.PaymentOrderStatusPaid, trHash)
+	if errors.GetCode(err) != 409 {
+		return err
+	}
+
+	orderID, _ := bson.ObjectIDFromHex(paymentOrderID)
+	order, getErr := s.referral_repository.GetPaymentOrderByID(ctx, orderID)
+	if getErr == nil && paidBy(order, trHash) {
+		s.logger.Infof("payment order %s is already paid by %s", paymentOrderID, trHash)
+		return nil
+	}
+
+	return err
+}
+
+func paidBy(order referral_model.PaymentOrder, trHash string) bool {
+	return order.CurrentStatus() == referral_model.PaymentOrderStatusPaid && order.TrHash == trHash
+}
+
+// ReopenPaymentOrder returns the order to open after its payment transaction failed.
+func (s *ReferralService) ReopenPaymentOrder(ctx context.Context, paymentOrderID string) error {
+	s.logger.Infof("reopening payment order: %s", paymentOrderID)
+	return s.transitionPaymentOrder(ctx, paymentOrderID, referral_model.PaymentOrderStatusOpen, "")
+}
+
+// MarkPaymentBundlePaid marks every order of a validated pay-all transaction as
+// paid. A bundle already paid by the same transaction is left as is.
+func (s *ReferralService) MarkPaymentBundlePaid(ctx context.Context, bundleID string, trHash string) error {
+	s.logger.Infof("marking payment bundle %s as paid by %s", bundleID, trHash)
+
+	paid, err := s.referral_repository.UpdatePaymentBundleStatus(ctx, bundleID, referral_model.PaymentOrderStatusPaid, trHash)
+	if err != nil {
+		s.logger.Errorf("failed to mark payment bundle as paid: %v", err)
+		return errors.NewError(500, "failed to mark payment bundle as paid")
+	}
+
+	if paid == 0 {
+		orders, err := s.referral_repository.GetPaymentOrdersByBundleID(ctx, bundleID)
+		if err != nil {
+			s.logger.Errorf("failed to get payment orders of bundle: %v", err)
+			return errors.NewError(500, "failed to get payment orders of bundle")
+		}
+		for _, order := range orders {
+			if paidBy(order, trHash) {
+				s.logger.Infof("payment bundle %s is already paid by %s", bundleID, trHash)
+				return nil
+			}
+		}
+
+		s.logger.Warnf("no payment orders of bundle %s were marked as paid", bundleID)
+		return errors.NewError(404, "no unpaid payment orders found in bundle")
+	}
+
+	s.logger.Infof("%d payment orders of bundle %s marked as paid", paid, bundleID)
+	return nil
+}
+
+// ReopenPaymentBundle returns the orders of a failed pay-all transaction to open.
+func (s *ReferralService) ReopenPaymentBundle(ctx context.Context, bundleID string) error {
+	s.logger.Infof("reopening payment bundle: %s", bundleID)
+
+	reopened, err := s.referral_repository.UpdatePaymentBundleStatus(ctx, bundleID, referral_model.PaymentOrderStatusOpen, "")
+	if err != nil {
+		s.logger.Errorf("failed to reopen payment bundle: %v", err)
+		return errors.NewError(500, "failed to reopen payment bundle")
+	}
+
+	s.logger.Infof("%d payment orders of bundle %s reopened", reopened, bundleID)
+	return nil
+}
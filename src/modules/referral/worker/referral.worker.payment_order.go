@@ -0,0 +1,53 @@
+package referral_worker
+
+import (
+	"context"
+	"time"
+
+	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+)
+
+const paymentOrderExpiryInterval = time.Minute
+
+// PaymentOrderExpirer expires payment orders left unpaid for longer than ttl.
+type PaymentOrderExpirer struct {
+	logger *logger.Logger
+	ttl    time.Duration
+
+	referral_repository referral_repository.IReferralRepository
+}
+
+func NewPaymentOrderExpirer(logger *logger.Logger, ttl time.Duration, referral_repository referral_repository.IReferralRepository) *PaymentOrderExpirer {
+	return &PaymentOrderExpirer{logger: logger, ttl: ttl, referral_repository: referral_repository}
+}
+
+// Run blocks until ctx is done. A zero ttl disables expiry.
+func (e *PaymentOrderExpirer) Run(ctx context.Context) {
+	if e.ttl <= 0 {
+		e.logger.Infof("payment order expiry is disabled")
+		return
+	}
+
+	e.logger.Infof("payment order expirer started, ttl %s", e.ttl)
+
+	ticker := time.NewTicker(paymentOrderExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := e.referral_repository.ExpirePaymentOrders(ctx, time.Now().Add(-e.ttl).Unix())
+		if err != nil {
+			e.logger.Errorf("failed to expire payment orders: %v", err)
+		}
+		if expired > 0 {
+			e.logger.Infof("%d payment orders expired", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			e.logger.Infof("payment order expirer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
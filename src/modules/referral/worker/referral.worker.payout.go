@@ -0,0 +1,241 @@
+package referral_worker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/root9464/Go_GamlerDefi/src/config"
+	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/shopspring/decimal"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	payoutLease       = 5 * time.Minute
+	payoutSendTimeout = payoutLease - 30*time.Second
+	maxPayoutAttempts = 5
+
+	staleReason = "dispatch interrupted, transfer state unknown"
+)
+
+// The contract sends a jetton transfer for every entry of the dictionary and
+// pays for it from the TON attached to the message.
+var (
+	transferMessageFee = tlb.MustFromTON("0.05")
+	jettonTransferFee  = tlb.MustFromTON("0.05")
+)
+
+// BatchOptions turns on batching: pending payouts are flushed every interval
+// as one transaction with up to maxMessagesPerTransaction transfer messages.
+type BatchOptions struct {
+	Enabled bool
+}
+
+// PayoutDispatcher drains the payout outbox and sends platform accruals on-chain.
+type PayoutDispatcher struct {
+	logger   *logger.Logger
+	config   *config.Config
+	interval time.Duration
+	batch    BatchOptions
+
+	ton_client          *ton.APIClient
+	referral_helper     referral_helper.IReferralHelper
+	referral_repository referral_repository.IReferralRepository
+
+	admin_wallet *wallet.Wallet
+}
+
+func NewPayoutDispatcher(
+	logger *logger.Logger,
+	config *config.Config,
+	interval time.Duration,
+	batch BatchOptions,
+	ton_client *ton.APIClient,
+	referral_helper referral_helper.IReferralHelper,
+	referral_repository referral_repository.IReferralRepository,
+) *PayoutDispatcher {
+	return &PayoutDispatcher{
+		logger:              logger,
+		config:              config,
+		interval:            interval,
+		batch:               batch,
+		ton_client:          ton_client,
+		referral_helper:     referral_helper,
+		referral_repository: referral_repository,
+	}
+}
+
+func (d *PayoutDispatcher) Run(ctx context.Context) {
+	d.logger.Infof("payout dispatcher started, interval %s, batching %t", d.interval, d.batch.Enabled)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.failStale(ctx)
+		if d.batch.Enabled {
+			d.dispatchBatch(ctx)
+		} else {
+			d.dispatch(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Infof("payout dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *PayoutDispatcher) failStale(ctx context.Context) {
+	stale, err := d.referral_repository.FailStalePayouts(ctx, staleReason)
+	if err != nil {
+		d.logger.Errorf("failed to check stale payouts: %v", err)
+	}
+	if stale > 0 {
+		d.logger.Warnf("%d payouts were interrupted while sending and need a manual check", stale)
+	}
+}
+
+func (d *PayoutDispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		payout, err := d.referral_repository.ClaimPayout(ctx, time.Now().Add(payoutLease).Unix())
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			d.logger.Errorf("failed to claim payout: %v", err)
+			return
+		}
+
+		if requeued := d.send(ctx, payout); requeued {
+			// leave the requeued payout to the next tick instead of spinning on it
+			return
+		}
+	}
+}
+
+// send reports whether the payout went back to the outbox for another attempt.
+func (d *PayoutDispatcher) send(ctx context.Context, payout referral_model.Payout) bool {
+	payoutID := payout.ID.Hex()
+
+	message, err := d.buildMessage(payout)
+	if err != nil {
+		return d.retryLater(ctx, payout, err)
+	}
+
+	adminWallet, err := d.wallet()
+	if err != nil {
+		return d.retryLater(ctx, payout, err)
+	}
+
+	d.logger.Infof("sending payout %s to the smart contract", payoutID)
+	sendCtx, cancel := context.WithTimeout(ctx, payoutSendTimeout)
+	defer cancel()
+
+	tx, _, err := adminWallet.SendWaitTransaction(sendCtx, message)
+	if err != nil {
+		// the message may already be broadcast, a blind resend could pay twice
+		d.logger.Errorf("payout %s transaction execution failed: %v", payoutID, err)
+		d.record(ctx, payout, referral_model.PayoutStatusFailed, "", fmt.Sprintf("transaction execution failed: %v", err))
+		return false
+	}
+
+	txHash := base64.StdEncoding.EncodeToString(tx.Hash)
+	d.logger.Infof("payout %s sent, hash of the transaction: %s", payoutID, txHash)
+	d.record(ctx, payout, referral_model.PayoutStatusSent, txHash, "")
+	return false
+}
+
+func (d *PayoutDispatcher) buildMessage(payout referral_model.Payout) (*wallet.Message, error) {
+	entries, err := payoutEntries(payout)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.transferMessage(referral_helper.MergeJettonEntries(entries))
+}
+
+func payoutEntries(payout referral_model.Payout) ([]referral_helper.JettonEntry, error) {
+	entries := make([]referral_helper.JettonEntry, 0, len(payout.Levels))
+	for _, level := range payout.Levels {
+		addr, err := address.ParseAddr(level.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address on level %d: %w", level.LevelNumber, err)
+		}
+
+		amount, err := decimal.NewFromString(level.Amount.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount on level %d: %w", level.LevelNumber, err)
+		}
+
+		entries = append(entries, referral_helper.JettonEntry{Address: addr, Amount: amount})
+	}
+
+	return entries, nil
+}
+
+func (d *PayoutDispatcher) transferMessage(entries []referral_helper.JettonEntry) (*wallet.Message, error) {
+	cell, err := d.referral_helper.CellTransferJettonsFromPlatform(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cell: %w", err)
+	}
+
+	return &wallet.Message{
+		Mode: wallet.PayGasSeparately,
+		InternalMessage: &tlb.InternalMessage{
+			Bounce:  true,
+			DstAddr: address.MustParseAddr(d.config.PlatformSmartContract),
+			Amount:  transferAmount(len(entries)),
+			Body:    cell,
+		},
+	}, nil
+}
+
+// transferAmount is the TON attached to a transfer message with `transfers` entries.
+func transferAmount(transfers int) tlb.Coins {
+	nano := new(big.Int).Mul(jettonTransferFee.Nano(), big.NewInt(int64(transfers)))
+	return tlb.FromNanoTON(nano.Add(nano, transferMessageFee.Nano()))
+}
+
+func (d *PayoutDispatcher) wallet() (*wallet.Wallet, error) {
+	if d.admin_wallet == nil {
+		adminWallet, err := wallet.FromSeed(d.ton_client, d.config.WalletSeed, wallet.V4R2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create wallet: %w", err)
+		}
+		d.logger.Infof("wallet created successfully: %+v", adminWallet.Address())
+		d.admin_wallet = adminWallet
+	}
+	return d.admin_wallet, nil
+}
+
+// retryLater returns a payout that failed before sending back to the outbox.
+func (d *PayoutDispatcher) retryLater(ctx context.Context, payout referral_model.Payout, cause error) bool {
+	if payout.Attempts >= maxPayoutAttempts {
+		d.logger.Errorf("payout %s failed after %d attempts: %v", payout.ID.Hex(), payout.Attempts, cause)
+		d.record(ctx, payout, referral_model.PayoutStatusFailed, "", cause.Error())
+		return false
+	}
+
+	d.logger.Warnf("payout %s will be retried: %v", payout.ID.Hex(), cause)
+	d.record(ctx, payout, referral_model.PayoutStatusPending, "", cause.Error())
+	return true
+}
+
+func (d *PayoutDispatcher) record(ctx context.Context, payout referral_model.Payout, status referral_model.PayoutStatus, txHash string, reason string) {
+	if err := d.referral_repository.UpdatePayoutStatus(ctx, payout.ID, status, txHash, reason); err != nil {
+		d.logger.Errorf("failed to record payout %s status %s: %v", payout.ID.Hex(), status, err)
+	}
+}
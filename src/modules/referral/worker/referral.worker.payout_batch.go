@@ -0,0 +1,175 @@
+package referral_worker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	"github.com/samber/lo"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// wallet v4r2 accepts at most 4 internal messages per external one
+	maxMessagesPerTransaction = 4
+	pendingBatchLimit         = 500
+)
+
+// messageBodyLimits keeps the jettons dictionary of one transfer message within
+// a quarter of the size limits of an external message, 1<<13 cells and 1<<21
+// bits, leaving room for the cells around it, so every message of the
+// transaction fits. The depth stays below the 512 levels of a cell tree.
+var messageBodyLimits = referral_helper.CellLimits{
+	Cells: (1<<13)/maxMessagesPerTransaction - 16,
+	Bits:  (1<<21)/maxMessagesPerTransaction - 16*1023,
+	Depth: 500,
+}
+
+func (d *PayoutDispatcher) dispatchBatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		pending, err := d.referral_repository.GetPendingPayouts(ctx, pendingBatchLimit)
+		if err != nil {
+			d.logger.Errorf("failed to get pending payouts: %v", err)
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+
+		batch, entries := d.claimBatch(ctx, pending)
+		if len(batch) == 0 {
+			return
+		}
+
+		if requeued := d.sendBatch(ctx, batch, entries); requeued {
+			return
+		}
+	}
+}
+
+// claimBatch leases pending payouts in order while their merged entries still
+// fit into one transaction. A payout that does not fit even alone is failed.
+func (d *PayoutDispatcher) claimBatch(ctx context.Context, pending []referral_model.Payout) ([]referral_model.Payout, []referral_helper.JettonEntry) {
+	leaseUntil := time.Now().Add(payoutLease).Unix()
+
+	sendable := []referral_model.Payout{}
+	entries := [][]referral_helper.JettonEntry{}
+	for _, payout := range pending {
+		own, err := payoutEntries(payout)
+		if err == nil {
+			err = fitsTransaction(own)
+		}
+		if err != nil {
+			d.failUnsendable(ctx, payout, leaseUntil, err)
+			continue
+		}
+
+		sendable = append(sendable, payout)
+		entries = append(entries, own)
+	}
+
+	// the dictionaries only grow with every payout added, so the payouts
+	// fitting into one transaction are a prefix of the pending ones
+	count := sort.Search(len(sendable), func(i int) bool {
+		return fitsTransaction(lo.Flatten(entries[:i+1])) != nil
+	})
+
+	batch := []referral_model.Payout{}
+	merged := []referral_helper.JettonEntry{}
+	for i, payout := range sendable[:count] {
+		claimed, err := d.referral_repository.ClaimPayoutByID(ctx, payout.ID, leaseUntil)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			break
+		}
+
+		batch = append(batch, claimed)
+		merged = append(merged, entries[i]...)
+	}
+
+	return batch, referral_helper.MergeJettonEntries(merged)
+}
+
+// fitsTransaction checks that the merged entries pack into the transfer
+// messages of one transaction.
+func fitsTransaction(entries []referral_helper.JettonEntry) error {
+	chunks, err := referral_helper.PackJettonEntries(referral_helper.MergeJettonEntries(entries), messageBodyLimits)
+	if err != nil {
+		return err
+	}
+	if len(chunks) > maxMessagesPerTransaction {
+		return fmt.Errorf("entries need %d messages, a transaction takes %d", len(chunks), maxMessagesPerTransaction)
+	}
+	return nil
+}
+
+func (d *PayoutDispatcher) failUnsendable(ctx context.Context, payout referral_model.Payout, leaseUntil int64, cause error) {
+	d.logger.Errorf("payout %s can not be sent: %v", payout.ID.Hex(), cause)
+
+	if _, err := d.referral_repository.ClaimPayoutByID(ctx, payout.ID, leaseUntil); err != nil {
+		return
+	}
+	d.record(ctx, payout, referral_model.PayoutStatusFailed, "", cause.Error())
+}
+
+// sendBatch reports whether the batch went back to the outbox for another attempt.
+func (d *PayoutDispatcher) sendBatch(ctx context.Context, batch []referral_model.Payout, entries []referral_helper.JettonEntry) bool {
+	batchID := bson.NewObjectID().Hex()
+	payoutIDs := lo.Map(batch, func(payout referral_model.Payout, _ int) bson.ObjectID { return payout.ID })
+
+	chunks, err := referral_helper.PackJettonEntries(entries, messageBodyLimits)
+	if err != nil {
+		return d.retryBatchLater(ctx, batch, err)
+	}
+
+	messages := []*wallet.Message{}
+	for _, chunk := range chunks {
+		message, err := d.transferMessage(chunk)
+		if err != nil {
+			return d.retryBatchLater(ctx, batch, err)
+		}
+		messages = append(messages, message)
+	}
+
+	adminWallet, err := d.wallet()
+	if err != nil {
+		return d.retryBatchLater(ctx, batch, err)
+	}
+
+	d.logger.Infof("sending batch %s: %d payouts, %d entries, %d messages", batchID, len(batch), len(entries), len(messages))
+	sendCtx, cancel := context.WithTimeout(ctx, payoutSendTimeout)
+	defer cancel()
+
+	tx, _, err := adminWallet.SendManyWaitTransaction(sendCtx, messages)
+	if err != nil {
+		d.logger.Errorf("batch %s transaction execution failed: %v", batchID, err)
+		d.recordBatch(ctx, payoutIDs, batchID, referral_model.PayoutStatusFailed, "", fmt.Sprintf("transaction execution failed: %v", err))
+		return false
+	}
+
+	txHash := base64.StdEncoding.EncodeToString(tx.Hash)
+	d.logger.Infof("batch %s sent, hash of the transaction: %s", batchID, txHash)
+	d.recordBatch(ctx, payoutIDs, batchID, referral_model.PayoutStatusSent, txHash, "")
+	return false
+}
+
+func (d *PayoutDispatcher) retryBatchLater(ctx context.Context, batch []referral_model.Payout, cause error) bool {
+	for _, payout := range batch {
+		d.retryLater(ctx, payout, cause)
+	}
+	return true
+}
+
+func (d *PayoutDispatcher) recordBatch(ctx context.Context, payoutIDs []bson.ObjectID, batchID string, status referral_model.PayoutStatus, txHash string, reason string) {
+	if err := d.referral_repository.CompletePayoutBatch(ctx, payoutIDs, batchID, status, txHash, reason); err != nil {
+		d.logger.Errorf("failed to record batch %s status %s: %v", batchID, status, err)
+	}
+}
@@ -1,19 +1,27 @@
 package ton_controllers
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	ton_service "github.com/root9464/Go_GamlerDefi/src/modules/ton/service"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 )
 
 type ITonController interface {
 	GetImage(c *fiber.Ctx) error
 	GetManifest(c *fiber.Ctx) error
+
+	GetProofPayload(c *fiber.Ctx) error
+	CheckProof(c *fiber.Ctx) error
 }
 
 type TonController struct {
-	logger *logger.Logger
+	logger    *logger.Logger
+	validator *validator.Validate
+
+	ton_service ton_service.ITonService
 }
 
-func NewTonController(logger *logger.Logger) ITonController {
-	return &TonController{logger: logger}
+func NewTonController(logger *logger.Logger, validator *validator.Validate, ton_service ton_service.ITonService) ITonController {
+	return &TonController{logger: logger, validator: validator, ton_service: ton_service}
 }
@@ -0,0 +1,65 @@
+package ton_controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	ton_dto "github.com/root9464/Go_GamlerDefi/src/modules/ton/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+)
+
+// @Summary Get ton_proof payload
+// @Description Get a single-use payload for the tonProof request of TON Connect
+// @Tags Ton
+// @Produce json
+// @Success 200 {object} ton_dto.ProofPayloadResponse "Success response"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/ton/proof/payload [get]
+func (c *TonController) GetProofPayload(ctx *fiber.Ctx) error {
+	payload, err := c.ton_service.GenerateProofPayload(ctx.Context())
+	if err != nil {
+		c.logger.Errorf("error generating proof payload: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(payload)
+}
+
+// @Summary Check ton_proof
+// @Description Verify that the caller controls the wallet and issue tokens carrying its address, payments are made from this wallet
+// @Tags Ton
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param proof body ton_dto.TonProofRequest true "Account and proof returned by TON Connect"
+// @Success 200 {object} jwt_dto.TokenPairResponse "Success response"
+// @Failure 400 {object} errors.MapError "Invalid request body"
+// @Failure 401 {object} errors.MapError "Invalid or expired proof"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/ton/proof/check [post]
+func (c *TonController) CheckProof(ctx *fiber.Ctx) error {
+	user, ok := admin_middleware.UserFromContext(ctx)
+	if !ok {
+		return errors.NewError(401, "authentication required")
+	}
+
+	request := new(ton_dto.TonProofRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("failed to parse ton_proof: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("failed to validate ton_proof: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	userData := jwt_dto.UserData{ID: user.Sub, Roles: user.Roles, Scopes: user.Scopes}
+	tokens, err := c.ton_service.CheckProof(ctx.Context(), userData, *request)
+	if err != nil {
+		c.logger.Errorf("error checking ton_proof: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(tokens)
+}
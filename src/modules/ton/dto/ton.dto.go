@@ -18,3 +18,78 @@ type Manifest struct {
 	// example: https://example.com/icon.png
 	IconURL string `json:"iconUrl"`
 }
+
+// ProofPayloadResponse is the challenge the wallet signs with ton_proof
+// @swagger:model ProofPayloadResponse
+type ProofPayloadResponse struct {
+	// Payload to pass to TON Connect as tonProof
+	// required: true
+	// example: "9b0f0bbd6f1c43c5c5e1a6f0a8a5f38b4d1d21f5e33f4e1b3a0c2f6a7d5e9c11"
+	Payload string `json:"payload"`
+
+	// Unix time after which the payload is not accepted
+	// required: true
+	// example: 1747001536
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// TonProofRequest is the account and the ton_proof item returned by TON Connect
+// @swagger:model TonProofRequest
+type TonProofRequest struct {
+	// Raw address of the wallet
+	// required: true
+	// example: "0:0db232ef397d8c111953e28cafd9b48f11573d96e9d23c8b396b590fb61097c9"
+	Address string `json:"address" validate:"required"`
+
+	// Network of the wallet, -239 for mainnet and -3 for testnet
+	// required: true
+	// example: "-239"
+	Network string `json:"network" validate:"required,oneof=-239 -3"`
+
+	// Hex public key of the wallet as reported by the wallet
+	// required: false
+	// example: "e0b2f5e39fb1e73cc33b7e3c8f2d0d1e0b8b1f3c6f8c4f0b27a6f2b1c7e4d3a9"
+	PublicKey string `json:"public_key,omitempty" validate:"omitempty,hexadecimal,len=64"`
+
+	// Signed proof
+	// required: true
+	Proof TonProof `json:"proof" validate:"required"`
+}
+
+type TonProof struct {
+	// Unix time the proof was signed at
+	// required: true
+	// example: 1747000636
+	Timestamp int64 `json:"timestamp" validate:"required"`
+
+	// Domain of the application the proof is made for
+	// required: true
+	Domain TonProofDomain `json:"domain" validate:"required"`
+
+	// Payload returned by /api/ton/proof/payload
+	// required: true
+	// example: "9b0f0bbd6f1c43c5c5e1a6f0a8a5f38b4d1d21f5e33f4e1b3a0c2f6a7d5e9c11"
+	Payload string `json:"payload" validate:"required"`
+
+	// Base64 ed25519 signature
+	// required: true
+	// example: "p0tb4LFeG0Vv1r2UaOB2iuGm8ht2kdtVbRp5u1ppbRBi1GNpFv7rG6tIbhkzGqkP2Xj6xMr7F3cNY2sI0VvmBQ=="
+	Signature string `json:"signature" validate:"required,base64"`
+
+	// Base64 BOC of the wallet state init, the key is read from the chain when omitted
+	// required: false
+	// example: "te6cckECFgEAAwQAAgE0AQIBFP8A9KQT9LzyyAsDAFEAAAAAKamjF..."
+	StateInit string `json:"state_init,omitempty" validate:"omitempty,base64"`
+}
+
+type TonProofDomain struct {
+	// Length of the domain in bytes
+	// required: true
+	// example: 13
+	LengthBytes uint32 `json:"lengthBytes" validate:"required"`
+
+	// Domain of the application
+	// required: true
+	// example: "gamler.online"
+	Value string `json:"value" validate:"required"`
+}
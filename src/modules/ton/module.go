@@ -1,32 +1,96 @@
 package ton_module
 
 import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/root9464/Go_GamlerDefi/src/config"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
 	ton_controllers "github.com/root9464/Go_GamlerDefi/src/modules/ton/controllers"
+	ton_proof "github.com/root9464/Go_GamlerDefi/src/modules/ton/proof"
+	ton_repository "github.com/root9464/Go_GamlerDefi/src/modules/ton/repository"
+	ton_service "github.com/root9464/Go_GamlerDefi/src/modules/ton/service"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+	"github.com/xssnick/tonutils-go/ton"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// defaultProofDomain is the domain of the TON Connect manifest.
+	defaultProofDomain = "gamler.online"
+	defaultProofTTL    = 15 * time.Minute
 )
 
 type TonModule struct {
-	config *config.Config
-	logger *logger.Logger
+	config     *config.Config
+	logger     *logger.Logger
+	validator  *validator.Validate
+	db         *mongo.Database
+	ton_client *ton.APIClient
+
+	jwt_service jwt_service.IJwtService
 
-	ton_controller ton_controllers.ITonController
+	ton_controller   ton_controllers.ITonController
+	ton_service      ton_service.ITonService
+	ton_repository   ton_repository.ITonRepository
+	admin_middleware *admin_middleware.Middleware
 }
 
-func NewTonModule(config *config.Config, logger *logger.Logger) *TonModule {
-	return &TonModule{config: config, logger: logger}
+func NewTonModule(
+	config *config.Config, logger *logger.Logger, validator *validator.Validate, db *mongo.Database,
+	ton_client *ton.APIClient, jwt_service jwt_service.IJwtService,
+	admin_middleware *admin_middleware.Middleware,
+) *TonModule {
+	return &TonModule{
+		config:           config,
+		logger:           logger,
+		validator:        validator,
+		db:               db,
+		ton_client:       ton_client,
+		jwt_service:      jwt_service,
+		admin_middleware: admin_middleware,
+	}
 }
 
 func (m *TonModule) Controller() ton_controllers.ITonController {
 	if m.ton_controller == nil {
-		m.ton_controller = ton_controllers.NewTonController(m.logger)
+		m.ton_controller = ton_controllers.NewTonController(m.logger, m.validator, m.Service())
 	}
 	return m.ton_controller
 }
 
+func (m *TonModule) Service() ton_service.ITonService {
+	if m.ton_service == nil {
+		options := ton_service.ProofOptions{Domains: m.config.TonProofDomains, TTL: m.config.TonProofTTL}
+		if len(options.Domains) == 0 {
+			options.Domains = []string{defaultProofDomain}
+		}
+		if options.TTL == 0 {
+			options.TTL = defaultProofTTL
+		}
+		m.ton_service = ton_service.NewTonService(m.logger, options, ton_proof.NewChainKeyResolver(m.ton_client), m.Repository(), m.jwt_service)
+	}
+	return m.ton_service
+}
+
+func (m *TonModule) Repository() ton_repository.ITonRepository {
+	if m.ton_repository == nil {
+		m.ton_repository = ton_repository.NewTonRepository(m.logger, m.db)
+	}
+	return m.ton_repository
+}
+
+func (m *TonModule) Middleware() *admin_middleware.Middleware {
+	return m.admin_middleware
+}
+
 func (m *TonModule) RegisterRoutes(app fiber.Router) {
 	ton := app.Group("/ton")
 	ton.Get("/image/:image_path", m.Controller().GetImage)
 	ton.Get("/manifest", m.Controller().GetManifest)
+
+	ton.Get("/proof/payload", m.Controller().GetProofPayload)
+	ton.Post("/proof/check", m.Middleware().Authenticated(), m.Controller().CheckProof)
 }
@@ -0,0 +1,140 @@
+package ton_proof
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+const (
+	proofPrefix   = "ton-proof-item-v2/"
+	connectPrefix = "ton-connect"
+)
+
+var (
+	// ErrStateInitMismatch is returned when the state init does not deploy the
+	// address the proof is made for.
+	ErrStateInitMismatch = errors.New("state init does not match the address")
+	// ErrUnknownWallet is returned when the public key can not be read from the
+	// state init because the wallet code is not a known version.
+	ErrUnknownWallet = errors.New("unknown wallet contract")
+)
+
+// Proof is the ton_proof item signed by the wallet on connection.
+type Proof struct {
+	Address   *address.Address
+	Domain    string
+	Timestamp int64
+	Payload   string
+	Signature []byte
+}
+
+// Message builds the bytes the wallet hashes and signs:
+//
+//	sha256(0xffff ++ "ton-connect" ++ sha256("ton-proof-item-v2/" ++ workchain ++ hash ++ domain length ++ domain ++ timestamp ++ payload))
+func (p Proof) Message() []byte {
+	message := new(bytes.Buffer)
+	message.WriteString(proofPrefix)
+	_ = binary.Write(message, binary.BigEndian, p.Address.Workchain())
+	message.Write(p.Address.Data())
+	_ = binary.Write(message, binary.LittleEndian, uint32(len(p.Domain)))
+	message.WriteString(p.Domain)
+	_ = binary.Write(message, binary.LittleEndian, uint64(p.Timestamp))
+	message.WriteString(p.Payload)
+	messageHash := sha256.Sum256(message.Bytes())
+
+	full := new(bytes.Buffer)
+	full.Write([]byte{0xff, 0xff})
+	full.WriteString(connectPrefix)
+	full.Write(messageHash[:])
+	fullHash := sha256.Sum256(full.Bytes())
+	return fullHash[:]
+}
+
+// Verify reports whether the proof is signed with the key.
+func (p Proof) Verify(publicKey ed25519.PublicKey) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(publicKey, p.Message(), p.Signature)
+}
+
+// dataKeyOffsets is where the public key starts in the data of the wallets
+// supported by the state init lookup.
+var dataKeyOffsets = map[wallet.Version]uint{
+	wallet.V1R1: 32, wallet.V1R2: 32, wallet.V1R3: 32,
+	wallet.V2R1: 32, wallet.V2R2: 32,
+	wallet.V3R1: 64, wallet.V3R2: 64,
+	wallet.V4R1: 64, wallet.V4R2: 64,
+	wallet.V5R1Beta:  113,
+	wallet.V5R1Final: 65,
+}
+
+// PublicKeyFromStateInit reads the wallet key from the state init sent with the
+// proof, the state init has to deploy the address of the proof.
+func PublicKeyFromStateInit(addr *address.Address, stateInitBoc []byte) (ed25519.PublicKey, error) {
+	root, err := cell.FromBOC(stateInitBoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state init: %w", err)
+	}
+
+	if !bytes.Equal(root.Hash(), addr.Data()) {
+		return nil, ErrStateInitMismatch
+	}
+
+	var stateInit tlb.StateInit
+	if err := tlb.LoadFromCell(&stateInit, root.BeginParse()); err != nil {
+		return nil, fmt.Errorf("failed to load state init: %w", err)
+	}
+	if stateInit.Code == nil || stateInit.Data == nil {
+		return nil, ErrUnknownWallet
+	}
+
+	version := wallet.GetWalletVersion(&tlb.Account{
+		IsActive: true,
+		State:    &tlb.AccountState{AccountStorage: tlb.AccountStorage{Status: tlb.AccountStatusActive}},
+		Code:     stateInit.Code,
+	})
+	offset, ok := dataKeyOffsets[version]
+	if !ok {
+		return nil, ErrUnknownWallet
+	}
+
+	data := stateInit.Data.BeginParse()
+	if _, err := data.LoadSlice(offset); err != nil {
+		return nil, fmt.Errorf("failed to skip wallet data: %w", err)
+	}
+	key, err := data.LoadSlice(ed25519.PublicKeySize * 8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public key: %w", err)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// IKeyResolver looks up the public key of a deployed wallet.
+type IKeyResolver interface {
+	PublicKey(ctx context.Context, addr *address.Address) (ed25519.PublicKey, error)
+}
+
+type ChainKeyResolver struct {
+	ton_client *ton.APIClient
+}
+
+// NewChainKeyResolver reads the key with the get_public_key method of the wallet.
+func NewChainKeyResolver(ton_client *ton.APIClient) IKeyResolver {
+	return &ChainKeyResolver{ton_client: ton_client}
+}
+
+func (r *ChainKeyResolver) PublicKey(ctx context.Context, addr *address.Address) (ed25519.PublicKey, error) {
+	return wallet.GetPublicKey(ctx, r.ton_client, addr)
+}
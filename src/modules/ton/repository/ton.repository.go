@@ -0,0 +1,89 @@
+package ton_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ITonRepository interface {
+	CreateProofPayload(ctx context.Context, payload string, expiresAt time.Time) error
+	ConsumeProofPayload(ctx context.Context, payload string, now time.Time) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type TonRepository struct {
+	logger *logger.Logger
+	db     *mongo.Database
+}
+
+const (
+	proof_payload_collection = "ton_proof_payload"
+)
+
+// proofPayload is a challenge handed out for ton_proof, it is removed once a
+// proof signed over it is checked.
+type proofPayload struct {
+	Payload   string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func NewTonRepository(logger *logger.Logger, db *mongo.Database) ITonRepository {
+	return &TonRepository{logger: logger, db: db}
+}
+
+func (r *TonRepository) CreateProofPayload(ctx context.Context, payload string, expiresAt time.Time) error {
+	collection := r.db.Collection(proof_payload_collection)
+	if _, err := collection.InsertOne(ctx, proofPayload{Payload: payload, ExpiresAt: expiresAt}); err != nil {
+		r.logger.Errorf("failed to insert proof payload: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ConsumeProofPayload removes a payload that has not expired at `now`, so
+// every payload backs one proof at most. Returns mongo.ErrNoDocuments for an
+// unknown, used or expired payload.
+func (r *TonRepository) ConsumeProofPayload(ctx context.Context, payload string, now time.Time) error {
+	collection := r.db.Collection(proof_payload_collection)
+
+	filter := bson.D{
+		{Key: "_id", Value: payload},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+
+	result, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.Errorf("failed to consume proof payload: %v", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// EnsureIndexes creates the TTL index removing payloads nobody signed.
+func (r *TonRepository) EnsureIndexes(ctx context.Context) error {
+	r.logger.Infof("ensuring indexes of %s", proof_payload_collection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+
+	names, err := r.db.Collection(proof_payload_collection).Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		r.logger.Errorf("failed to create indexes of %s: %v", proof_payload_collection, err)
+		return err
+	}
+
+	r.logger.Infof("indexes of %s are ready: %v", proof_payload_collection, names)
+	return nil
+}
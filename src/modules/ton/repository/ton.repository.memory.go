@@ -0,0 +1,45 @@
+package ton_repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MemoryRepository keeps proof payloads in memory with the errors of the mongo
+// repository, it is meant for tests and local runs without a database.
+type MemoryRepository struct {
+	mu       sync.Mutex
+	payloads map[string]time.Time
+}
+
+func NewMemoryRepository() ITonRepository {
+	return &MemoryRepository{payloads: map[string]time.Time{}}
+}
+
+func (r *MemoryRepository) CreateProofPayload(ctx context.Context, payload string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.payloads[payload] = expiresAt
+	return nil
+}
+
+func (r *MemoryRepository) ConsumeProofPayload(ctx context.Context, payload string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expiresAt, ok := r.payloads[payload]
+	if !ok || !expiresAt.After(now) {
+		return mongo.ErrNoDocuments
+	}
+
+	delete(r.payloads, payload)
+	return nil
+}
+
+func (r *MemoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
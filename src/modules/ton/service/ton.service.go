@@ -0,0 +1,48 @@
+package ton_service
+
+import (
+	"context"
+	"time"
+
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
+	ton_dto "github.com/root9464/Go_GamlerDefi/src/modules/ton/dto"
+	ton_proof "github.com/root9464/Go_GamlerDefi/src/modules/ton/proof"
+	ton_repository "github.com/root9464/Go_GamlerDefi/src/modules/ton/repository"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+)
+
+type ITonService interface {
+	GenerateProofPayload(ctx context.Context) (ton_dto.ProofPayloadResponse, error)
+	CheckProof(ctx context.Context, user jwt_dto.UserData, request ton_dto.TonProofRequest) (jwt_dto.TokenPairResponse, error)
+}
+
+// ProofOptions configures the ton_proof check. Domains lists the application
+// domains a proof may be made for, TTL bounds both the payload lifetime and the
+// age of the proof.
+type ProofOptions struct {
+	Domains []string
+	TTL     time.Duration
+}
+
+type TonService struct {
+	logger  *logger.Logger
+	options ProofOptions
+
+	keys           ton_proof.IKeyResolver
+	ton_repository ton_repository.ITonRepository
+	jwt_service    jwt_service.IJwtService
+}
+
+func NewTonService(
+	logger *logger.Logger, options ProofOptions, keys ton_proof.IKeyResolver,
+	ton_repository ton_repository.ITonRepository, jwt_service jwt_service.IJwtService,
+) ITonService {
+	return &TonService{
+		logger:         logger,
+		options:        options,
+		keys:           keys,
+		ton_repository: ton_repository,
+		jwt_service:    jwt_service,
+	}
+}
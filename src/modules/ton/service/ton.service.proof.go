@@ -0,0 +1,146 @@
+package ton_service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	ton_dto "github.com/root9464/Go_GamlerDefi/src/modules/ton/dto"
+	ton_proof "github.com/root9464/Go_GamlerDefi/src/modules/ton/proof"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/xssnick/tonutils-go/address"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	payloadSize = 32
+	// clockSkew tolerates wallets whose clock is slightly ahead of the server.
+	clockSkew        = time.Minute
+	testnetNetworkID = "-3"
+)
+
+// GenerateProofPayload hands out a single-use challenge for ton_proof.
+func (s *TonService) GenerateProofPayload(ctx context.Context) (ton_dto.ProofPayloadResponse, error) {
+	raw := make([]byte, payloadSize)
+	if _, err := rand.Read(raw); err != nil {
+		s.logger.Errorf("failed to generate proof payload: %v", err)
+		return ton_dto.ProofPayloadResponse{}, errors.NewError(500, "failed to generate proof payload")
+	}
+
+	payload := hex.EncodeToString(raw)
+	expiresAt := time.Now().Add(s.options.TTL)
+	if err := s.ton_repository.CreateProofPayload(ctx, payload, expiresAt); err != nil {
+		return ton_dto.ProofPayloadResponse{}, errors.NewError(500, "failed to store proof payload")
+	}
+
+	return ton_dto.ProofPayloadResponse{Payload: payload, ExpiresAt: expiresAt.Unix()}, nil
+}
+
+// CheckProof verifies that the user controls the wallet of the request and
+// issues tokens carrying the wallet address. The key is read from the state
+// init when the wallet sent one and from the chain otherwise. The roles and
+// scopes of the user are kept in the new tokens.
+func (s *TonService) CheckProof(ctx context.Context, user jwt_dto.UserData, request ton_dto.TonProofRequest) (jwt_dto.TokenPairResponse, error) {
+	s.logger.Infof("checking ton_proof of user %d for %s", user.ID, request.Address)
+
+	addr, err := address.ParseRawAddr(request.Address)
+	if err != nil {
+		s.logger.Warnf("invalid proof address %s: %v", request.Address, err)
+		return jwt_dto.TokenPairResponse{}, errors.NewError(400, "invalid wallet address")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(request.Proof.Signature)
+	if err != nil {
+		return jwt_dto.TokenPairResponse{}, errors.NewError(400, "invalid proof signature")
+	}
+
+	proof := ton_proof.Proof{
+		Address:   addr,
+		Domain:    request.Proof.Domain.Value,
+		Timestamp: request.Proof.Timestamp,
+		Payload:   request.Proof.Payload,
+		Signature: signature,
+	}
+
+	if err := s.checkProofItem(proof, request.Proof.Domain.LengthBytes); err != nil {
+		return jwt_dto.TokenPairResponse{}, err
+	}
+
+	publicKey, err := s.publicKey(ctx, addr, request.Proof.StateInit)
+	if err != nil {
+		return jwt_dto.TokenPairResponse{}, err
+	}
+
+	if request.PublicKey != "" && !strings.EqualFold(request.PublicKey, hex.EncodeToString(publicKey)) {
+		s.logger.Warnf("public key sent for %s does not match the wallet", request.Address)
+		return jwt_dto.TokenPairResponse{}, errors.NewError(401, "public key does not match the wallet")
+	}
+
+	if !proof.Verify(publicKey) {
+		s.logger.Warnf("invalid ton_proof signature for %s", request.Address)
+		return jwt_dto.TokenPairResponse{}, errors.NewError(401, "invalid proof signature")
+	}
+
+	// the payload is consumed only by a valid proof, a forged one can not burn it
+	if err := s.ton_repository.ConsumeProofPayload(ctx, proof.Payload, time.Now()); err != nil {
+		if err == mongo.ErrNoDocuments {
+			s.logger.Warnf("proof payload of %s is unknown, used or expired", request.Address)
+			return jwt_dto.TokenPairResponse{}, errors.NewError(401, "proof payload is unknown or expired")
+		}
+		return jwt_dto.TokenPairResponse{}, errors.NewError(500, "failed to check proof payload")
+	}
+
+	wallet := addr.Bounce(false).Testnet(request.Network == testnetNetworkID).String()
+	s.logger.Infof("user %d proved control of %s", user.ID, wallet)
+
+	user.Wallet = wallet
+	return s.jwt_service.IssueTokens(ctx, user)
+}
+
+func (s *TonService) checkProofItem(proof ton_proof.Proof, domainLength uint32) error {
+	if int(domainLength) != len(proof.Domain) || !slices.Contains(s.options.Domains, proof.Domain) {
+		s.logger.Warnf("ton_proof made for unexpected domain %q", proof.Domain)
+		return errors.NewError(401, "proof is made for another domain")
+	}
+
+	signedAt := time.Unix(proof.Timestamp, 0)
+	now := time.Now()
+	if signedAt.Before(now.Add(-s.options.TTL)) || signedAt.After(now.Add(clockSkew)) {
+		s.logger.Warnf("ton_proof timestamp %d is out of range", proof.Timestamp)
+		return errors.NewError(401, "proof has expired")
+	}
+	return nil
+}
+
+func (s *TonService) publicKey(ctx context.Context, addr *address.Address, stateInit string) (ed25519.PublicKey, error) {
+	if stateInit != "" {
+		boc, err := base64.StdEncoding.DecodeString(stateInit)
+		if err != nil {
+			return nil, errors.NewError(400, "invalid state init")
+		}
+
+		publicKey, err := ton_proof.PublicKeyFromStateInit(addr, boc)
+		if err == nil {
+			return publicKey, nil
+		}
+		if errors.Is(err, ton_proof.ErrStateInitMismatch) {
+			s.logger.Warnf("state init does not deploy %s", addr.String())
+			return nil, errors.NewError(401, "state init does not match the wallet")
+		}
+		// unknown wallets are still checked against the deployed contract
+		s.logger.Warnf("failed to read key from state init of %s, asking the chain: %v", addr.String(), err)
+	}
+
+	publicKey, err := s.keys.PublicKey(ctx, addr)
+	if err != nil {
+		s.logger.Errorf("failed to get public key of %s: %v", addr.String(), err)
+		return nil, errors.NewError(401, "failed to get the public key of the wallet")
+	}
+	return publicKey, nil
+}
@@ -21,26 +21,112 @@ func TransactionDTOToModel(transactionDTO validation_dto.WorkerTransactionDTO) (
 	}
 
 	return validation_model.WorkerTransaction{
-		ID:             transactionID,
-		TxHash:         transactionDTO.TxHash,
-		TxQueryID:      transactionDTO.TxQueryID,
-		TargetAddress:  transactionDTO.TargetAddress,
-		PaymentOrderId: paymentOrderID,
-		Status:         validation_model.WorkerStatus(transactionDTO.Status),
-		CreatedAt:      transactionDTO.CreatedAt,
-		UpdatedAt:      transactionDTO.UpdatedAt,
+		ID:              transactionID,
+		TxHash:          transactionDTO.TxHash,
+		TxQueryID:       transactionDTO.TxQueryID,
+		TargetAddress:   transactionDTO.TargetAddress,
+		PaymentOrderId:  paymentOrderID,
+		PaymentBundleId: transactionDTO.PaymentBundleId,
+		Status:          validation_model.WorkerStatus(transactionDTO.Status),
+		CreatedAt:       transactionDTO.CreatedAt,
+		UpdatedAt:       transactionDTO.UpdatedAt,
 	}, nil
 }
 
 func TransactionModelToDTOPoint(transactionModel validation_model.WorkerTransaction) *validation_dto.WorkerTransactionDTO {
 	return &validation_dto.WorkerTransactionDTO{
-		ID:             transactionModel.ID.Hex(),
-		TxHash:         transactionModel.TxHash,
-		TxQueryID:      transactionModel.TxQueryID,
-		TargetAddress:  transactionModel.TargetAddress,
-		PaymentOrderId: transactionModel.PaymentOrderId.Hex(),
-		Status:         validation_dto.WorkerStatus(transactionModel.Status),
-		CreatedAt:      transactionModel.CreatedAt,
-		UpdatedAt:      transactionModel.UpdatedAt,
+		ID:              transactionModel.ID.Hex(),
+		TxHash:          transactionModel.TxHash,
+		TxQueryID:       transactionModel.TxQueryID,
+		TargetAddress:   transactionModel.TargetAddress,
+		PaymentOrderId:  transactionModel.PaymentOrderId.Hex(),
+		PaymentBundleId: transactionModel.PaymentBundleId,
+		Status:          validation_dto.WorkerStatus(transactionModel.Status),
+		CreatedAt:       transactionModel.CreatedAt,
+		UpdatedAt:       transactionModel.UpdatedAt,
+		Attempts:        transactionModel.Attempts,
+		NextCheckAt:     transactionModel.NextCheckAt,
+		Report:          TraceReportModelToDTO(transactionModel.Report),
+	}
+}
+
+func TraceReportModelToDTO(report []validation_model.TraceNode) []validation_dto.TraceNode {
+	if len(report) == 0 {
+		return nil
+	}
+
+	nodes := make([]validation_dto.TraceNode, 0, len(report))
+	for _, node := range report {
+		nodes = append(nodes, validation_dto.TraceNode{
+			Hash:       node.Hash,
+			Address:    node.Address,
+			OpCode:     node.OpCode,
+			Depth:      node.Depth,
+			Success:    node.Success,
+			Status:     validation_dto.WorkerStatus(node.Status),
+			ExitCode:   node.ExitCode,
+			ResultCode: node.ResultCode,
+		})
+	}
+	return nodes
+}
+
+func TransactionModelsToDTO(transactionModels []validation_model.WorkerTransaction) []validation_dto.WorkerTransactionDTO {
+	transactions := make([]validation_dto.WorkerTransactionDTO, 0, len(transactionModels))
+	for _, transactionModel := range transactionModels {
+		transactions = append(transactions, *TransactionModelToDTOPoint(transactionModel))
+	}
+	return transactions
+}
+
+func TransactionHistoryModelToDTO(transactionModel validation_model.WorkerTransaction) *validation_dto.TransactionHistory {
+	history := &validation_dto.TransactionHistory{
+		TransactionID: transactionModel.ID.Hex(),
+		TxHash:        transactionModel.TxHash,
+		Status:        validation_dto.WorkerStatus(transactionModel.Status),
+		History:       make([]validation_dto.StatusTransition, 0, len(transactionModel.History)),
+	}
+
+	for _, transition := range transactionModel.History {
+		entry := validation_dto.StatusTransition{
+			From:     validation_dto.WorkerStatus(transition.From),
+			To:       validation_dto.WorkerStatus(transition.To),
+			At:       transition.At,
+			Reason:   string(transition.Reason),
+			Detail:   transition.Detail,
+			Instance: transition.Instance,
+		}
+		if transition.Trace != nil {
+			entry.Trace = &validation_dto.TraceSummary{
+				Hash:             transition.Trace.Hash,
+				Lt:               transition.Trace.Lt,
+				Transactions:     transition.Trace.Transactions,
+				Success:          transition.Trace.Success,
+				FailedHash:       transition.Trace.FailedHash,
+				ComputeExitCode:  transition.Trace.ComputeExitCode,
+				ActionResultCode: transition.Trace.ActionResultCode,
+			}
+		}
+		history.History = append(history.History, entry)
+	}
+
+	return history
+}
+
+func PaymentIntentModelToDTO(intent validation_model.PaymentIntent) *validation_dto.PaymentIntentDTO {
+	return &validation_dto.PaymentIntentDTO{
+		ID:           intent.ID.Hex(),
+		Payer:        intent.Payer,
+		Recipient:    intent.Recipient,
+		JettonMaster: intent.JettonMaster,
+		Amount:       intent.Amount,
+		Comment:      intent.Comment,
+		QueryID:      intent.QueryID,
+		Status:       validation_dto.PaymentIntentStatus(intent.Status),
+		ExpiresAt:    intent.ExpiresAt,
+		CreatedAt:    intent.CreatedAt,
+		UpdatedAt:    intent.UpdatedAt,
+		TxHash:       intent.TxHash,
+		Detail:       intent.Detail,
 	}
 }
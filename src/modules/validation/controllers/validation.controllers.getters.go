@@ -0,0 +1,88 @@
+package validation_controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+)
+
+// @Summary Get transaction
+// @Description Get the validation status of a queued transaction
+// @Tags Validation
+// @Produce json
+// @Param transaction_id path string true "Transaction ID"
+// @Success 200 {object} validation_dto.WorkerTransactionDTO "Success response"
+// @Failure 400 {object} errors.MapError "Invalid transaction ID"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/validation/transactions/{transaction_id} [get]
+func (c *ValidationController) GetTransaction(ctx *fiber.Ctx) error {
+	paramTransactionID := ctx.Params("transaction_id")
+	c.logger.Infof("transaction ID: %s", paramTransactionID)
+
+	transaction, err := c.validation_service.GetTransaction(ctx.Context(), paramTransactionID)
+	if err != nil {
+		c.logger.Errorf("error getting transaction: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(transaction)
+}
+
+// @Summary Get transaction history
+// @Description Get every status change of a transaction with its reason, trace summary and instance
+// @Tags Validation
+// @Produce json
+// @Param transaction_id path string true "Transaction ID"
+// @Success 200 {object} validation_dto.TransactionHistory "Success response"
+// @Failure 400 {object} errors.MapError "Invalid transaction ID"
+// @Failure 401 {object} errors.MapError "Unauthorized"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/validation/admin/transactions/{transaction_id}/history [get]
+func (c *ValidationController) GetTransactionHistory(ctx *fiber.Ctx) error {
+	paramTransactionID := ctx.Params("transaction_id")
+	c.logger.Infof("transaction ID: %s", paramTransactionID)
+
+	history, err := c.validation_service.GetTransactionHistory(ctx.Context(), paramTransactionID)
+	if err != nil {
+		c.logger.Errorf("error getting transaction history: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(history)
+}
+
+// @Summary Get transactions
+// @Description List validated transactions, newest first
+// @Tags Validation
+// @Produce json
+// @Param status query string false "Status" Enums(pending, waiting, running, success, failed, mismatch)
+// @Param target_address query string false "Target address"
+// @Param payment_order_id query string false "Payment order ID"
+// @Param limit query int false "Limit, 50 by default, 100 at most"
+// @Param offset query int false "Offset"
+// @Success 200 {array} validation_dto.WorkerTransactionDTO "Success response"
+// @Failure 400 {object} errors.MapError "Invalid query"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/validation/transactions [get]
+func (c *ValidationController) GetTransactions(ctx *fiber.Ctx) error {
+	query := new(validation_dto.TransactionQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		c.logger.Errorf("failed to parse query: %v", err)
+		return errors.NewError(400, "invalid query")
+	}
+
+	if err := c.validator.Struct(query); err != nil {
+		c.logger.Errorf("failed to validate query: %v", err)
+		return errors.NewError(400, "invalid query")
+	}
+
+	transactions, err := c.validation_service.GetTransactions(ctx.Context(), *query)
+	if err != nil {
+		c.logger.Errorf("error getting transactions: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(transactions)
+}
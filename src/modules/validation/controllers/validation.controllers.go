@@ -3,12 +3,24 @@ package validation_controllers
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
 	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 )
 
 type IValidationController interface {
 	ValidatorTransaction(c *fiber.Ctx) error
+	GetTransaction(c *fiber.Ctx) error
+	GetTransactions(c *fiber.Ctx) error
+	StreamTransactions(c *fiber.Ctx) error
+	GetTransactionHistory(c *fiber.Ctx) error
+	CreatePaymentIntent(c *fiber.Ctx) error
+	GetPaymentIntent(c *fiber.Ctx) error
+}
+
+// ITransactionQueue is notified when a transaction was queued for validation.
+type ITransactionQueue interface {
+	Wake()
 }
 
 type ValidationController struct {
@@ -16,8 +28,24 @@ type ValidationController struct {
 	validator *validator.Validate
 
 	validation_service validation_service.IValidationService
+	intent_service     validation_service.IPaymentIntentService
+	queue              ITransactionQueue
+	events             validation_events.IStatusBroker
 }
 
-func NewValidationController(logger *logger.Logger, validator *validator.Validate, validation_service validation_service.IValidationService) IValidationController {
-	return &ValidationController{logger: logger, validator: validator, validation_service: validation_service}
+func NewValidationController(
+	logger *logger.Logger, validator *validator.Validate,
+	validation_service validation_service.IValidationService,
+	intent_service validation_service.IPaymentIntentService,
+	queue ITransactionQueue,
+	events validation_events.IStatusBroker,
+) IValidationController {
+	return &ValidationController{
+		logger:             logger,
+		validator:          validator,
+		validation_service: validation_service,
+		intent_service:     intent_service,
+		queue:              queue,
+		events:             events,
+	}
 }
@@ -0,0 +1,64 @@
+package validation_controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+)
+
+// @Summary Create payment intent
+// @Description Create a jetton payment the server waits for. The payer sends the amount to the recipient with the returned query_id, the intent is paid once the transfer arrives
+// @Tags Validation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param intent body validation_dto.CreatePaymentIntentDTO true "Payment intent"
+// @Success 201 {object} validation_dto.PaymentIntentDTO "Payment intent created"
+// @Failure 400 {object} errors.MapError "Invalid request body"
+// @Failure 401 {object} errors.MapError "Missing or invalid token"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/validation/intents [post]
+func (c *ValidationController) CreatePaymentIntent(ctx *fiber.Ctx) error {
+	intent := new(validation_dto.CreatePaymentIntentDTO)
+	if err := ctx.BodyParser(intent); err != nil {
+		c.logger.Errorf("failed to parse payment intent: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	if err := c.validator.Struct(intent); err != nil {
+		c.logger.Errorf("failed to validate payment intent dto: %v", err)
+		return errors.NewError(400, "invalid request body")
+	}
+
+	created, err := c.intent_service.CreatePaymentIntent(ctx.Context(), intent)
+	if err != nil {
+		c.logger.Errorf("error creating payment intent: %v", err)
+		return err
+	}
+
+	ctx.Location("/api/validation/intents/" + created.ID)
+	return ctx.Status(201).JSON(created)
+}
+
+// @Summary Get payment intent
+// @Description Get a payment intent with its status and the transaction that paid it
+// @Tags Validation
+// @Produce json
+// @Param intent_id path string true "Payment intent ID"
+// @Success 200 {object} validation_dto.PaymentIntentDTO "Success response"
+// @Failure 400 {object} errors.MapError "Invalid payment intent ID"
+// @Failure 404 {object} errors.MapError "Not found"
+// @Failure 500 {object} errors.MapError "Internal server error"
+// @Router /api/validation/intents/{intent_id} [get]
+func (c *ValidationController) GetPaymentIntent(ctx *fiber.Ctx) error {
+	paramIntentID := ctx.Params("intent_id")
+	c.logger.Infof("payment intent ID: %s", paramIntentID)
+
+	intent, err := c.intent_service.GetPaymentIntent(ctx.Context(), paramIntentID)
+	if err != nil {
+		c.logger.Errorf("error getting payment intent: %v", err)
+		return err
+	}
+
+	return ctx.Status(200).JSON(intent)
+}
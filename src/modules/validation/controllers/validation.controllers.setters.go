@@ -7,14 +7,14 @@ import (
 )
 
 // @Summary Validate transaction
-// @Description Validate transaction
+// @Description Queue the transaction for validation, its status is fetched by the returned ID
 // @Tags Validation
 // @Accept json
 // @Produce json
 // @Param transaction body validation_dto.WorkerTransactionDTO true "Transaction"
-// @Success 200 {object} validation_dto.WorkerTransactionResponse "Transaction processed successfully"
+// @Success 202 {object} validation_dto.WorkerTransactionResponse "Transaction queued for validation"
 // @Failure 400 {object} errors.MapError "Invalid request body"
-// @Failure 409 {object} validation_dto.WorkerTransactionResponse "Failed transaction processing"
+// @Failure 422 {object} errors.MapError "Transaction can not be queued"
 // @Router /api/validation/validate [post]
 func (c *ValidationController) ValidatorTransaction(ctx *fiber.Ctx) error {
 	transaction := new(validation_dto.WorkerTransactionDTO)
@@ -32,60 +32,18 @@ func (c *ValidationController) ValidatorTransaction(ctx *fiber.Ctx) error {
 	}
 	c.logger.Info("validate dto success")
 
-	transaction, runnerStatus, err := c.validation_service.RunnerTransaction(ctx.Context(), transaction)
+	transaction, _, err := c.validation_service.RunnerTransaction(ctx.Context(), transaction)
 	if err != nil {
 		c.logger.Errorf("runner failed: %v", err)
 		return err
 	}
 
-	if !runnerStatus {
-		c.logger.Errorf("failed runner transaction: %v", transaction.TxHash)
-		return ctx.Status(409).JSON(validation_dto.WorkerTransactionResponse{
-			Message: "Failed transaction processing",
-			TxHash:  transaction.TxHash,
-			TxID:    transaction.ID,
-			Status:  transaction.Status,
-		})
-	}
-
-	c.logger.Infof("transaction data after runner: %+v", transaction)
-
-	transaction, subWorkerStatus, err := c.validation_service.SubWorkerTransaction(ctx.Context(), transaction)
-	if err != nil {
-		c.logger.Errorf("failed subworker transaction: %v", err)
-		return err
-	}
-	c.logger.Infof("transaction data after subworker: %+v", transaction)
-
-	if !subWorkerStatus {
-		c.logger.Errorf("failed subworker transaction: %v", transaction.TxHash)
-		return ctx.Status(409).JSON(validation_dto.WorkerTransactionResponse{
-			Message: "Failed subworker transaction",
-			TxHash:  transaction.TxHash,
-			TxID:    transaction.ID,
-			Status:  transaction.Status,
-		})
-	}
-
-	c.logger.Infof("transaction data before worker: %+v", transaction)
-	transaction, workerStatus, err := c.validation_service.WorkerTransaction(ctx.Context(), transaction)
-	if err != nil {
-		c.logger.Errorf("failed worker transaction: %v", err)
-		return err
-	}
-
-	if !workerStatus {
-		c.logger.Errorf("failed worker transaction: %v", transaction.TxHash)
-		return ctx.Status(errors.GetCode(err)).JSON(validation_dto.WorkerTransactionResponse{
-			Message: "Failed worker transaction",
-			TxHash:  transaction.TxHash,
-			TxID:    transaction.ID,
-			Status:  transaction.Status,
-		})
-	}
+	c.logger.Infof("transaction queued: %+v", transaction)
+	c.queue.Wake()
 
-	return ctx.Status(200).JSON(validation_dto.WorkerTransactionResponse{
-		Message: "Transaction processed successfully",
+	ctx.Location("/api/validation/transactions/" + transaction.ID)
+	return ctx.Status(202).JSON(validation_dto.WorkerTransactionResponse{
+		Message: "Transaction queued for validation",
 		TxHash:  transaction.TxHash,
 		TxID:    transaction.ID,
 		Status:  transaction.Status,
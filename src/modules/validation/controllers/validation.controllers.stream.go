@@ -0,0 +1,116 @@
+package validation_controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+// @Summary Stream transaction statuses
+// @Description Server-sent events with every status change of the transaction or of the transactions paying the payment order.
+// @Description When subscribed by transaction ID its current status is sent first.
+// @Tags Validation
+// @Produce text/event-stream
+// @Param transaction_id query string false "Transaction ID"
+// @Param payment_order_id query string false "Payment order ID"
+// @Success 200 {object} validation_events.StatusEvent "Stream of status events"
+// @Failure 400 {object} errors.MapError "Transaction or payment order ID is required"
+// @Router /api/validation/stream [get]
+func (c *ValidationController) StreamTransactions(ctx *fiber.Ctx) error {
+	filter := validation_events.Filter{
+		TransactionID:  ctx.Query("transaction_id"),
+		PaymentOrderID: ctx.Query("payment_order_id"),
+	}
+	c.logger.Infof("stream subscription: %+v", filter)
+
+	if filter.TransactionID == "" && filter.PaymentOrderID == "" {
+		return errors.NewError(400, "transaction_id or payment_order_id is required")
+	}
+	for _, id := range []string{filter.TransactionID, filter.PaymentOrderID} {
+		if _, err := bson.ObjectIDFromHex(id); id != "" && err != nil {
+			return errors.NewError(400, "invalid ID: "+id)
+		}
+	}
+
+	// subscribe before reading the current status so no change falls in between
+	events, unsubscribe := c.events.Subscribe(filter)
+
+	var initial []validation_events.StatusEvent
+	if filter.TransactionID != "" {
+		transaction, err := c.validation_service.GetTransaction(ctx.Context(), filter.TransactionID)
+		if err != nil && errors.GetCode(err) != 404 {
+			unsubscribe()
+			return err
+		}
+		if err == nil {
+			transactionModel, err := validation_adapters.TransactionDTOToModel(*transaction)
+			if err != nil {
+				unsubscribe()
+				c.logger.Errorf("failed to convert transaction dto to model: %v", err)
+				return errors.NewError(500, "failed to read transaction")
+			}
+			initial = append(initial, validation_events.NewStatusEvent(transactionModel))
+		}
+	}
+
+	ctx.Set("Content-Type", "text/event-stream")
+	ctx.Set("Cache-Control", "no-cache")
+	ctx.Set("Connection", "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		for _, event := range initial {
+			if err := writeStatusEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := writeStatusEvent(w, event); err != nil {
+					c.logger.Infof("stream subscriber gone: %v", err)
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					c.logger.Infof("stream subscriber gone: %v", err)
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeStatusEvent(w *bufio.Writer, event validation_events.StatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %s-%d\nevent: status\ndata: %s\n\n", event.TransactionID, event.UpdatedAt, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
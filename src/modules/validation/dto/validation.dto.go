@@ -1,5 +1,7 @@
 package validation_dto
 
+import "github.com/shopspring/decimal"
+
 // WorkerStatus defines the status of the worker transaction
 // @swagger:enum WorkerStatus
 type WorkerStatus string
@@ -10,6 +12,9 @@ const (
 	WorkerStatusRunning WorkerStatus = "running"
 	WorkerStatusSuccess WorkerStatus = "success"
 	WorkerStatusFailed  WorkerStatus = "failed"
+	// WorkerStatusMismatch marks a transaction that succeeded on-chain but
+	// moved other amounts than the payment orders expect.
+	WorkerStatusMismatch WorkerStatus = "mismatch"
 )
 
 type WorkerTransactionDTO struct {
@@ -38,6 +43,11 @@ type WorkerTransactionDTO struct {
 	// example: "6826ac79ff2f0eb00db5fa1d"
 	PaymentOrderId string `json:"payment_order_id,omitempty"`
 
+	// Bundle ID returned by pay-all, settles every order of the bundle
+	// required: false
+	// example: "6823dc5bcb80d8ea88f9b32b"
+	PaymentBundleId string `json:"payment_bundle_id,omitempty"`
+
 	// Status of the worker transaction
 	// required: true
 	// example: "pending"
@@ -52,6 +62,44 @@ type WorkerTransactionDTO struct {
 	// required: false
 	// example: 1715731200
 	UpdatedAt int64 `json:"updated_at"`
+
+	// Number of background checks made so far, ignored on submit
+	// required: false
+	// example: 2
+	Attempts int `json:"attempts,omitempty"`
+
+	// Unix time of the next background check, ignored on submit
+	// required: false
+	// example: 1715731260
+	NextCheckAt int64 `json:"next_check_at,omitempty"`
+
+	// Every transaction of the trace the status was decided on, ignored on submit
+	// required: false
+	Report []TraceNode `json:"report,omitempty"`
+}
+
+// TransactionQuery filters the list of validated transactions
+// @swagger:model TransactionQuery
+type TransactionQuery struct {
+	// Status of the transactions
+	// example: "waiting"
+	Status WorkerStatus `query:"status" validate:"omitempty,oneof=pending waiting running success failed mismatch"`
+
+	// Target address of the transactions
+	// example: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+	TargetAddress string `query:"target_address"`
+
+	// Payment order the transactions pay
+	// example: "6826ac79ff2f0eb00db5fa1d"
+	PaymentOrderId string `query:"payment_order_id" validate:"omitempty,len=24,hexadecimal"`
+
+	// Maximum number of transactions, 50 by default
+	// example: 50
+	Limit int64 `query:"limit" validate:"omitempty,min=1,max=100"`
+
+	// Number of transactions to skip
+	// example: 0
+	Offset int64 `query:"offset" validate:"omitempty,min=0"`
 }
 
 // WorkerTransactionResponse represents the response of the worker transaction
@@ -72,3 +120,227 @@ type WorkerTransactionResponse struct {
 	// Status of the worker transaction
 	Status WorkerStatus `json:"status"`
 }
+
+// TransactionHistory lists every status change of a transaction
+// @swagger:model TransactionHistory
+type TransactionHistory struct {
+	// ID of the worker transaction
+	// example: "682a67342a36c14af648479b"
+	TransactionID string `json:"transaction_id"`
+
+	// Hash of the transaction
+	// example: "105f7620bf78d534941ebcf97dda0dbe8e79c134a8ab346843787c71fe3308d5"
+	TxHash string `json:"tx_hash"`
+
+	// Current status of the worker transaction
+	// example: "success"
+	Status WorkerStatus `json:"status"`
+
+	// Status changes, oldest first
+	History []StatusTransition `json:"history"`
+}
+
+type StatusTransition struct {
+	// Previous status, empty for the submit
+	// example: "running"
+	From WorkerStatus `json:"from,omitempty"`
+
+	// New status
+	// example: "success"
+	To WorkerStatus `json:"to"`
+
+	// Unix time of the change
+	// example: 1715731200
+	At int64 `json:"at"`
+
+	// Why the status changed
+	// example: "validated"
+	Reason string `json:"reason"`
+
+	// Details of the reason, e.g. the error or the mismatching amounts
+	// example: "contract did not distribute the payment yet"
+	Detail string `json:"detail,omitempty"`
+
+	// Trace the change was decided on
+	Trace *TraceSummary `json:"trace,omitempty"`
+
+	// Instance that made the change
+	// example: "api-7f9c/1"
+	Instance string `json:"instance"`
+}
+
+type TraceSummary struct {
+	// Hash of the root transaction
+	// example: "105f7620bf78d534941ebcf97dda0dbe8e79c134a8ab346843787c71fe3308d5"
+	Hash string `json:"hash"`
+
+	// Logical time of the root transaction
+	// example: 56166043000001
+	Lt int64 `json:"lt"`
+
+	// Number of transactions in the trace
+	// example: 4
+	Transactions int `json:"transactions"`
+
+	// Whether every transaction of the trace succeeded
+	// example: true
+	Success bool `json:"success"`
+
+	// Hash of the first transaction that did not succeed
+	// example: "5a0c3f4f0b1a9c7b2f1e8d6c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928"
+	FailedHash string `json:"failed_hash,omitempty"`
+
+	// Compute phase exit code of the failed transaction
+	// example: 0
+	ComputeExitCode int32 `json:"compute_exit_code,omitempty"`
+
+	// Action phase result code of the failed transaction
+	// example: 37
+	ActionResultCode int32 `json:"action_result_code,omitempty"`
+}
+
+type TraceNode struct {
+	// Hash of the transaction
+	// example: "5a0c3f4f0b1a9c7b2f1e8d6c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928"
+	Hash string `json:"hash"`
+
+	// Account of the transaction
+	// example: "0:0db232ef397d8c111953e28cafd9b48f11573d96e9d23c8b396b590fb61097c9"
+	Address string `json:"address"`
+
+	// Op code of the inbound message
+	// example: "0x0f8a7ea5"
+	OpCode string `json:"op_code,omitempty"`
+
+	// Number of hops from the root transaction
+	// example: 1
+	Depth int `json:"depth"`
+
+	// Whether the transaction succeeded
+	// example: false
+	Success bool `json:"success"`
+
+	// Verdict on this transaction alone
+	// example: "failed"
+	Status WorkerStatus `json:"status"`
+
+	// Compute phase exit code
+	// example: 709
+	ExitCode int32 `json:"exit_code"`
+
+	// Action phase result code
+	// example: 0
+	ResultCode int32 `json:"result_code"`
+}
+
+// PaymentExpectation describes the jetton transfers that pay the payment orders
+// bound to a transaction: the payer sends TotalAmount to the platform contract
+// and the contract distributes it to the recipients.
+type PaymentExpectation struct {
+	TotalAmount decimal.Decimal
+	Recipients  []ExpectedTransfer
+}
+
+type ExpectedTransfer struct {
+	Address string
+	Amount  decimal.Decimal
+}
+
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentOpen    PaymentIntentStatus = "open"
+	PaymentIntentPaid    PaymentIntentStatus = "paid"
+	PaymentIntentExpired PaymentIntentStatus = "expired"
+)
+
+// CreatePaymentIntentDTO describes the jetton payment a client is about to make
+// @swagger:model CreatePaymentIntentDTO
+type CreatePaymentIntentDTO struct {
+	// Wallet the payment is sent from
+	// required: true
+	// example: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+	Payer string `json:"payer" validate:"required"`
+
+	// Owner receiving the jettons, the platform contract by default
+	// required: false
+	// example: "EQBQAMflxhyqE0OlZNsuVrNuVrxN_PudrtiYBw43ojP5u292"
+	Recipient string `json:"recipient,omitempty"`
+
+	// Jetton master of the paid jetton, the platform jetton by default
+	// required: false
+	// example: "EQDy6a9Smm8T7n6Jqrx9LKfS32FzEyiG2MZziHa6N5U1IHtQ"
+	JettonMaster string `json:"jetton_master,omitempty"`
+
+	// Amount in the smallest units of the jetton
+	// required: true
+	// example: "1500000000"
+	Amount string `json:"amount" validate:"required,numeric"`
+
+	// Comment shown to the payer
+	// required: false
+	// example: "Conference ticket"
+	Comment string `json:"comment,omitempty" validate:"max=120"`
+
+	// Seconds the intent stays open, the server default when empty
+	// required: false
+	// example: 1800
+	ExpiresIn int64 `json:"expires_in,omitempty" validate:"omitempty,min=60,max=86400"`
+}
+
+// PaymentIntentDTO is a jetton payment the server waits for. The payer sends
+// the amount to the recipient with query_id in the jetton transfer.
+// @swagger:model PaymentIntentDTO
+type PaymentIntentDTO struct {
+	// ID of the payment intent
+	// example: "6830b4a7c1d2e3f405162738"
+	ID string `json:"id"`
+
+	// Wallet the payment is expected from
+	// example: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+	Payer string `json:"payer"`
+
+	// Owner receiving the jettons
+	// example: "EQBQAMflxhyqE0OlZNsuVrNuVrxN_PudrtiYBw43ojP5u292"
+	Recipient string `json:"recipient"`
+
+	// Jetton master of the paid jetton
+	// example: "EQDy6a9Smm8T7n6Jqrx9LKfS32FzEyiG2MZziHa6N5U1IHtQ"
+	JettonMaster string `json:"jetton_master"`
+
+	// Amount in the smallest units of the jetton
+	// example: "1500000000"
+	Amount string `json:"amount"`
+
+	// Comment shown to the payer
+	// example: "Conference ticket"
+	Comment string `json:"comment,omitempty"`
+
+	// Query ID to put in the jetton transfer
+	// example: 4811739274651190123
+	QueryID uint64 `json:"query_id"`
+
+	// Status of the payment intent
+	// example: "open"
+	Status PaymentIntentStatus `json:"status"`
+
+	// Unix time the intent expires at
+	// example: 1715733000
+	ExpiresAt int64 `json:"expires_at"`
+
+	// Created at
+	// example: 1715731200
+	CreatedAt int64 `json:"created_at"`
+
+	// Updated at
+	// example: 1715731260
+	UpdatedAt int64 `json:"updated_at"`
+
+	// Hash of the transaction that received the transfer
+	// example: "5a0c3f4f0b1a9c7b2f1e8d6c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928"
+	TxHash string `json:"tx_hash,omitempty"`
+
+	// Transfers with the query ID that did not pay the intent
+	// example: "5a0c3f4f0b1a9c7b2f1e8d6c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928 transferred 1000000000, expected 1500000000"
+	Detail string `json:"detail,omitempty"`
+}
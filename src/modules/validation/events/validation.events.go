@@ -0,0 +1,110 @@
+package validation_events
+
+import (
+	"sync"
+
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before
+// further events are dropped for it.
+const subscriberBuffer = 16
+
+// StatusEvent is published on every status change of a transaction observer.
+type StatusEvent struct {
+	TransactionID   string                        `json:"transaction_id"`
+	TxHash          string                        `json:"tx_hash"`
+	PaymentOrderID  string                        `json:"payment_order_id,omitempty"`
+	PaymentBundleID string                        `json:"payment_bundle_id,omitempty"`
+	Status          validation_model.WorkerStatus `json:"status"`
+	UpdatedAt       int64                         `json:"updated_at"`
+}
+
+func NewStatusEvent(transaction validation_model.WorkerTransaction) StatusEvent {
+	event := StatusEvent{
+		TransactionID:   transaction.ID.Hex(),
+		TxHash:          transaction.TxHash,
+		PaymentBundleID: transaction.PaymentBundleId,
+		Status:          transaction.Status,
+		UpdatedAt:       transaction.UpdatedAt,
+	}
+	if !transaction.PaymentOrderId.IsZero() {
+		event.PaymentOrderID = transaction.PaymentOrderId.Hex()
+	}
+	return event
+}
+
+// Filter selects the events a subscriber receives. An event matches when any
+// of the set fields is equal.
+type Filter struct {
+	TransactionID  string
+	PaymentOrderID string
+}
+
+func (f Filter) matches(event StatusEvent) bool {
+	return (f.TransactionID != "" && f.TransactionID == event.TransactionID) ||
+		(f.PaymentOrderID != "" && f.PaymentOrderID == event.PaymentOrderID)
+}
+
+type IStatusPublisher interface {
+	Publish(event StatusEvent)
+}
+
+type IStatusBroker interface {
+	IStatusPublisher
+	Subscribe(filter Filter) (<-chan StatusEvent, func())
+}
+
+// StatusBroker fans status events out to the subscribers of this instance.
+type StatusBroker struct {
+	mu          sync.RWMutex
+	next        int
+	subscribers map[int]subscriber
+}
+
+type subscriber struct {
+	filter Filter
+	events chan StatusEvent
+}
+
+func NewStatusBroker() IStatusBroker {
+	return &StatusBroker{subscribers: map[int]subscriber{}}
+}
+
+// Subscribe returns the events matching filter and a function releasing the
+// subscription, which closes the channel.
+func (b *StatusBroker) Subscribe(filter Filter) (<-chan StatusEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	events := make(chan StatusEvent, subscriberBuffer)
+	b.subscribers[id] = subscriber{filter: filter, events: events}
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(events)
+		})
+	}
+}
+
+// Publish never blocks, a subscriber with a full buffer misses the event.
+func (b *StatusBroker) Publish(event StatusEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if !sub.filter.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
@@ -12,15 +12,169 @@ const (
 	WorkerStatusRunning WorkerStatus = "running"
 	WorkerStatusSuccess WorkerStatus = "success"
 	WorkerStatusFailed  WorkerStatus = "failed"
+	// WorkerStatusMismatch marks a transaction that succeeded on-chain but
+	// moved other amounts than the payment orders expect.
+	WorkerStatusMismatch WorkerStatus = "mismatch"
 )
 
+// WorkerStatusTransitions lists the statuses a transaction may move to from
+// each status. Success, failed and mismatch are final.
+var WorkerStatusTransitions = map[WorkerStatus][]WorkerStatus{
+	WorkerStatusPending: {WorkerStatusRunning},
+	WorkerStatusRunning: {
+		WorkerStatusWaiting,
+		WorkerStatusSuccess,
+		WorkerStatusFailed,
+		WorkerStatusMismatch,
+	},
+	WorkerStatusWaiting: {WorkerStatusRunning},
+}
+
+func (s WorkerStatus) CanTransitionTo(to WorkerStatus) bool {
+	for _, allowed := range WorkerStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 type WorkerTransaction struct {
 	ID             bson.ObjectID `bson:"_id"`
 	TxHash         string        `bson:"tx_hash"`
 	TxQueryID      uint64        `bson:"tx_query_id"`
 	TargetAddress  string        `bson:"target_address"`
 	PaymentOrderId bson.ObjectID `bson:"payment_order_id,omitempty"`
-	Status         WorkerStatus  `bson:"status"`
-	CreatedAt      int64         `bson:"created_at"`
-	UpdatedAt      int64         `bson:"updated_at"`
+	// PaymentBundleId is set for pay-all transactions settling several orders.
+	PaymentBundleId string       `bson:"payment_bundle_id,omitempty"`
+	Status          WorkerStatus `bson:"status"`
+	CreatedAt       int64        `bson:"created_at"`
+	UpdatedAt       int64        `bson:"updated_at"`
+
+	// Attempts, NextCheckAt and the lease are maintained by the validation
+	// scheduler re-checking waiting transactions in the background.
+	Attempts    int    `bson:"attempts,omitempty"`
+	NextCheckAt int64  `bson:"next_check_at,omitempty"`
+	LeaseUntil  int64  `bson:"lease_until,omitempty"`
+	LeaseOwner  string `bson:"lease_owner,omitempty"`
+
+	// History is append-only, every status change adds one entry.
+	History []StatusTransition `bson:"history,omitempty"`
+	// Report lists every transaction of the last trace the status was decided on.
+	Report []TraceNode `bson:"report,omitempty"`
+}
+
+// TransitionReason tells why a transaction changed its status.
+type TransitionReason string
+
+const (
+	ReasonSubmitted           TransitionReason = "submitted"
+	ReasonPrecheckout         TransitionReason = "precheckout"
+	ReasonRecheck             TransitionReason = "recheck"
+	ReasonTimeout             TransitionReason = "timeout"
+	ReasonTraceFetchError     TransitionReason = "trace_fetch_error"
+	ReasonInvalidAccount      TransitionReason = "invalid_account"
+	ReasonComputePhaseFailed  TransitionReason = "compute_phase_failed"
+	ReasonActionPhaseFailed   TransitionReason = "action_phase_failed"
+	ReasonExpectationError    TransitionReason = "payment_expectation_error"
+	ReasonDistributionPending TransitionReason = "distribution_pending"
+	ReasonDistributionFailed  TransitionReason = "distribution_mismatch"
+	ReasonDeadlineExceeded    TransitionReason = "deadline_exceeded"
+	ReasonSettlementPending   TransitionReason = "settlement_pending"
+	ReasonValidated           TransitionReason = "validated"
+)
+
+// StatusChange carries why a status changes, the repository adds the rest of
+// the StatusTransition.
+type StatusChange struct {
+	Reason TransitionReason
+	Detail string
+	Trace  *TraceSummary
+	// Report replaces the report of the transaction unless empty.
+	Report []TraceNode
+}
+
+type StatusTransition struct {
+	From     WorkerStatus     `bson:"from,omitempty"`
+	To       WorkerStatus     `bson:"to"`
+	At       int64            `bson:"at"`
+	Reason   TransitionReason `bson:"reason"`
+	Detail   string           `bson:"detail,omitempty"`
+	Trace    *TraceSummary    `bson:"trace,omitempty"`
+	Instance string           `bson:"instance"`
+}
+
+// TraceSummary is the part of the trace a status change was decided on.
+// FailedHash and the codes point at the first transaction that did not succeed.
+type TraceSummary struct {
+	Hash             string `bson:"hash"`
+	Lt               int64  `bson:"lt"`
+	Transactions     int    `bson:"transactions"`
+	Success          bool   `bson:"success"`
+	FailedHash       string `bson:"failed_hash,omitempty"`
+	ComputeExitCode  int32  `bson:"compute_exit_code,omitempty"`
+	ActionResultCode int32  `bson:"action_result_code,omitempty"`
+}
+
+// TraceNode is one transaction of the trace. Status is the verdict on this
+// transaction alone: failed when its compute phase failed, waiting when its
+// action phase did.
+type TraceNode struct {
+	Hash       string       `bson:"hash"`
+	Address    string       `bson:"address"`
+	OpCode     string       `bson:"op_code,omitempty"`
+	Depth      int          `bson:"depth"`
+	Success    bool         `bson:"success"`
+	Status     WorkerStatus `bson:"status"`
+	ExitCode   int32        `bson:"exit_code"`
+	ResultCode int32        `bson:"result_code"`
+}
+
+// TransactionFilter narrows a listing of transaction observers, zero fields are not filtered.
+type TransactionFilter struct {
+	Status         WorkerStatus
+	TargetAddress  string
+	PaymentOrderId bson.ObjectID
+	Limit          int64
+	Offset         int64
+}
+
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentOpen    PaymentIntentStatus = "open"
+	PaymentIntentPaid    PaymentIntentStatus = "paid"
+	PaymentIntentExpired PaymentIntentStatus = "expired"
+)
+
+// PaymentIntent is a jetton payment the server expects. The payer sends Amount
+// of the JettonMaster jetton to Recipient with QueryID in the transfer, which
+// is how the incoming transfer is matched to the intent.
+type PaymentIntent struct {
+	ID           bson.ObjectID `bson:"_id"`
+	Payer        string        `bson:"payer"`
+	Recipient    string        `bson:"recipient"`
+	JettonMaster string        `bson:"jetton_master"`
+	// Amount in the smallest units of the jetton.
+	Amount    string              `bson:"amount"`
+	Comment   string              `bson:"comment,omitempty"`
+	QueryID   uint64              `bson:"query_id"`
+	Status    PaymentIntentStatus `bson:"status"`
+	ExpiresAt int64               `bson:"expires_at"`
+	CreatedAt int64               `bson:"created_at"`
+	UpdatedAt int64               `bson:"updated_at"`
+
+	// Set once the transfer paying the intent was found.
+	TxHash string `bson:"tx_hash,omitempty"`
+	TxLt   uint64 `bson:"tx_lt,omitempty"`
+	// Describes the transfers with the query ID that did not pay the intent.
+	Detail string `bson:"detail,omitempty"`
+}
+
+// PaymentIntentSettlement is the incoming transfer an open intent is closed with.
+type PaymentIntentSettlement struct {
+	Status PaymentIntentStatus
+	TxHash string
+	TxLt   uint64
+	Detail string
 }
@@ -1,55 +1,193 @@
 package validation_module
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/root9464/Go_GamlerDefi/src/config"
 	validation_controllers "github.com/root9464/Go_GamlerDefi/src/modules/validation/controllers"
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
 	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
 	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	validation_worker "github.com/root9464/Go_GamlerDefi/src/modules/validation/worker"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
 	"github.com/tonkeeper/tonapi-go"
+	"github.com/xssnick/tonutils-go/ton"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+const (
+	defaultPollInterval = 10 * time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultDeadline     = 30 * time.Minute
+
+	defaultIntentTTL          = 30 * time.Minute
+	defaultIntentPollInterval = 15 * time.Second
+
+	traceProviderTonapi     = "tonapi"
+	traceProviderLiteserver = "liteserver"
+)
+
 type ValidationModule struct {
-	config    *config.Config
-	logger    *logger.Logger
-	validator *validator.Validate
-	db        *mongo.Database
-	ton_api   *tonapi.Client
+	config     *config.Config
+	logger     *logger.Logger
+	validator  *validator.Validate
+	db         *mongo.Database
+	ton_client *ton.APIClient
+	ton_api    *tonapi.Client
+
+	payment_orders validation_service.IPaymentOrderHook
 
 	validation_service    validation_service.IValidationService
+	intent_service        validation_service.IPaymentIntentService
 	validation_repository validation_repository.IValidationRepository
 	validation_controller validation_controllers.IValidationController
+	validation_scheduler  *validation_worker.ValidationScheduler
+	intent_watcher        *validation_worker.PaymentIntentWatcher
+	validation_events     validation_events.IStatusBroker
+	validation_traces     validation_trace.ITraceProvider
+	admin_middleware      *admin_middleware.Middleware
 }
 
-func NewValidationModule(config *config.Config, logger *logger.Logger, validator *validator.Validate, db *mongo.Database, ton_api *tonapi.Client) *ValidationModule {
-	return &ValidationModule{config: config, logger: logger, validator: validator, db: db, ton_api: ton_api}
+func NewValidationModule(
+	config *config.Config, logger *logger.Logger, validator *validator.Validate, db *mongo.Database,
+	ton_client *ton.APIClient, ton_api *tonapi.Client,
+	payment_orders validation_service.IPaymentOrderHook,
+	admin_middleware *admin_middleware.Middleware,
+) *ValidationModule {
+	return &ValidationModule{
+		config:           config,
+		logger:           logger,
+		validator:        validator,
+		db:               db,
+		ton_client:       ton_client,
+		ton_api:          ton_api,
+		payment_orders:   payment_orders,
+		admin_middleware: admin_middleware,
+	}
 }
 
 func (m *ValidationModule) Controller() validation_controllers.IValidationController {
 	if m.validation_controller == nil {
-		m.validation_controller = validation_controllers.NewValidationController(m.logger, m.validator, m.Service())
+		m.validation_controller = validation_controllers.NewValidationController(m.logger, m.validator, m.Service(), m.Intents(), m.Scheduler(), m.Events())
 	}
 	return m.validation_controller
 }
 
 func (m *ValidationModule) Service() validation_service.IValidationService {
 	if m.validation_service == nil {
-		m.validation_service = validation_service.NewValidationService(m.logger, m.ton_api, m.Repository())
+		m.validation_service = validation_service.NewValidationService(m.logger, m.Traces(), m.Repository(), m.payment_orders)
 	}
 	return m.validation_service
 }
 
+func (m *ValidationModule) Intents() validation_service.IPaymentIntentService {
+	if m.intent_service == nil {
+		options := validation_service.PaymentIntentOptions{
+			Recipient:    m.config.PlatformSmartContract,
+			JettonMaster: m.config.TargetJettonMaster,
+			TTL:          m.config.PaymentIntentTTL,
+		}
+		if options.TTL == 0 {
+			options.TTL = defaultIntentTTL
+		}
+
+		m.intent_service = validation_service.NewPaymentIntentService(m.logger, options, validation_trace.NewTransferSource(m.ton_client), m.Repository())
+	}
+	return m.intent_service
+}
+
+func (m *ValidationModule) IntentWatcher() *validation_worker.PaymentIntentWatcher {
+	if m.intent_watcher == nil {
+		interval := m.config.PaymentIntentPollInterval
+		if interval == 0 {
+			interval = defaultIntentPollInterval
+		}
+		m.intent_watcher = validation_worker.NewPaymentIntentWatcher(m.logger, interval, m.Intents())
+	}
+	return m.intent_watcher
+}
+
+func (m *ValidationModule) Traces() validation_trace.ITraceProvider {
+	if m.validation_traces == nil {
+		if m.config.ValidationTraceFixtures != "" {
+			traces, err := validation_trace.NewFixtureProvider(m.config.ValidationTraceFixtures)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to load validation trace fixtures: %v", err))
+			}
+			m.logger.Warnf("validation traces are served from fixtures: %s", m.config.ValidationTraceFixtures)
+			m.validation_traces = traces
+			return m.validation_traces
+		}
+
+		switch m.config.ValidationTraceProvider {
+		case traceProviderTonapi:
+			m.validation_traces = validation_trace.NewTonapiProvider(m.ton_api)
+		case traceProviderLiteserver:
+			m.validation_traces = validation_trace.NewLiteserverProvider(m.ton_client)
+		default:
+			m.validation_traces = validation_trace.NewFallbackProvider(m.logger,
+				validation_trace.NewTonapiProvider(m.ton_api),
+				validation_trace.NewLiteserverProvider(m.ton_client),
+			)
+		}
+	}
+	return m.validation_traces
+}
+
 func (m *ValidationModule) Repository() validation_repository.IValidationRepository {
 	if m.validation_repository == nil {
-		m.validation_repository = validation_repository.NewValidationRepository(m.logger, m.db)
+		m.validation_repository = validation_repository.NewValidationRepository(m.logger, m.db, m.Events())
 	}
 	return m.validation_repository
 }
 
+func (m *ValidationModule) Events() validation_events.IStatusBroker {
+	if m.validation_events == nil {
+		m.validation_events = validation_events.NewStatusBroker()
+	}
+	return m.validation_events
+}
+
+func (m *ValidationModule) Scheduler() *validation_worker.ValidationScheduler {
+	if m.validation_scheduler == nil {
+		options := validation_worker.ScheduleOptions{
+			Interval:   m.config.ValidationPollInterval,
+			MaxBackoff: m.config.ValidationMaxBackoff,
+			Deadline:   m.config.ValidationDeadline,
+		}
+		if options.Interval == 0 {
+			options.Interval = defaultPollInterval
+		}
+		if options.MaxBackoff == 0 {
+			options.MaxBackoff = defaultMaxBackoff
+		}
+		if options.Deadline == 0 {
+			options.Deadline = defaultDeadline
+		}
+
+		m.validation_scheduler = validation_worker.NewValidationScheduler(m.logger, options, m.Service(), m.Repository())
+	}
+	return m.validation_scheduler
+}
+
+func (m *ValidationModule) Middleware() *admin_middleware.Middleware {
+	return m.admin_middleware
+}
+
 func (m *ValidationModule) RegisterRoutes(app fiber.Router) {
 	validation := app.Group("/validation")
 	validation.Post("/validate", m.Controller().ValidatorTransaction)
+	validation.Get("/transactions", m.Controller().GetTransactions) // /transactions?status=<status>&target_address=<address>&payment_order_id=<id>
+	validation.Get("/transactions/:transaction_id", m.Controller().GetTransaction)
+	validation.Get("/stream", m.Controller().StreamTransactions) // /stream?transaction_id=<id>&payment_order_id=<id>
+	validation.Post("/intents", m.Middleware().Authenticated(), m.Controller().CreatePaymentIntent)
+	validation.Get("/intents/:intent_id", m.Controller().GetPaymentIntent)
+
+	admin := validation.Group("/admin", m.Middleware().AdminOnly())
+	admin.Get("/transactions/:transaction_id/history", m.Controller().GetTransactionHistory)
 }
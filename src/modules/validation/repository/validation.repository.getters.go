@@ -5,6 +5,8 @@ import (
 
 	validation_tr_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func (r *ValidationRepository) GetTransactionObserver(ctx context.Context, transactionID bson.ObjectID) (validation_tr_model.WorkerTransaction, error) {
@@ -25,3 +27,60 @@ func (r *ValidationRepository) GetTransactionObserver(ctx context.Context, trans
 	r.logger.Info("transaction observer found successfully")
 	return transaction, nil
 }
+
+// GetTransactionObservers lists the observers matching the filter, newest first.
+func (r *ValidationRepository) GetTransactionObservers(ctx context.Context, transactionFilter validation_tr_model.TransactionFilter) ([]validation_tr_model.WorkerTransaction, error) {
+	r.logger.Infof("getting transaction observers: %+v", transactionFilter)
+
+	collection := r.db.Collection(collection_name)
+
+	filter := bson.D{}
+	if transactionFilter.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: transactionFilter.Status})
+	}
+	if transactionFilter.TargetAddress != "" {
+		filter = append(filter, bson.E{Key: "target_address", Value: transactionFilter.TargetAddress})
+	}
+	if !transactionFilter.PaymentOrderId.IsZero() {
+		filter = append(filter, bson.E{Key: "payment_order_id", Value: transactionFilter.PaymentOrderId})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(transactionFilter.Offset).
+		SetLimit(transactionFilter.Limit)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.Errorf("failed to get transaction observers: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	transactions := []validation_tr_model.WorkerTransaction{}
+	if err := cursor.All(ctx, &transactions); err != nil {
+		r.logger.Errorf("failed to decode transaction observers: %v", err)
+		return nil, err
+	}
+
+	r.logger.Infof("%d transaction observers found", len(transactions))
+	return transactions, nil
+}
+
+func (r *ValidationRepository) GetTransactionObserverByTxHash(ctx context.Context, txHash string) (validation_tr_model.WorkerTransaction, error) {
+	r.logger.Infof("getting transaction observer by tx hash: %s", txHash)
+
+	collection := r.db.Collection(collection_name)
+
+	var transaction validation_tr_model.WorkerTransaction
+	err := collection.FindOne(ctx, bson.D{{Key: "tx_hash", Value: txHash}}).Decode(&transaction)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to get transaction observer by tx hash: %v", err)
+		}
+		return validation_tr_model.WorkerTransaction{}, err
+	}
+
+	r.logger.Infof("transaction observer found by tx hash: %s", transaction.ID.Hex())
+	return transaction, nil
+}
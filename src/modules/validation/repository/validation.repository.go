@@ -2,7 +2,10 @@ package validation_repository
 
 import (
 	"context"
+	"fmt"
+	"os"
 
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
 	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -12,20 +15,61 @@ import (
 type IValidationRepository interface {
 	CreateTransactionObserver(ctx context.Context, transaction validation_model.WorkerTransaction) (validation_model.WorkerTransaction, error)
 	GetTransactionObserver(ctx context.Context, transactionID bson.ObjectID) (validation_model.WorkerTransaction, error)
-	UpdateStatus(ctx context.Context, transactionID bson.ObjectID, status validation_model.WorkerStatus) (validation_model.WorkerTransaction, error)
+	GetTransactionObserverByTxHash(ctx context.Context, txHash string) (validation_model.WorkerTransaction, error)
+	GetTransactionObservers(ctx context.Context, filter validation_model.TransactionFilter) ([]validation_model.WorkerTransaction, error)
+	UpdateStatus(ctx context.Context, transactionID bson.ObjectID, from validation_model.WorkerStatus, to validation_model.WorkerStatus, change validation_model.StatusChange) (validation_model.WorkerTransaction, error)
 	PrecheckoutTransaction(ctx context.Context, transactionID bson.ObjectID) (validation_model.WorkerTransaction, error)
 	DeleteTransactionObserver(ctx context.Context, transactionID bson.ObjectID) error
+	ClaimTransactionObserver(ctx context.Context, owner string, now int64, staleBefore int64, leaseUntil int64) (validation_model.WorkerTransaction, error)
+	ReleaseTransactionObserver(ctx context.Context, transactionID bson.ObjectID, owner string, nextCheckAt int64) error
+	EnsureIndexes(ctx context.Context) error
+
+	CreatePaymentIntent(ctx context.Context, intent validation_model.PaymentIntent) (validation_model.PaymentIntent, error)
+	GetPaymentIntent(ctx context.Context, intentID bson.ObjectID) (validation_model.PaymentIntent, error)
+	GetOpenPaymentIntents(ctx context.Context) ([]validation_model.PaymentIntent, error)
+	SettlePaymentIntent(ctx context.Context, intentID bson.ObjectID, settlement validation_model.PaymentIntentSettlement) (validation_model.PaymentIntent, error)
+	NotePaymentIntent(ctx context.Context, intentID bson.ObjectID, detail string) error
+	ExpirePaymentIntents(ctx context.Context, now int64, intentIDs []bson.ObjectID) (int64, error)
 }
 
 type ValidationRepository struct {
 	logger *logger.Logger
 	db     *mongo.Database
+	events validation_events.IStatusPublisher
+}
+
+// StatusConflictError is returned when the transaction is not in the expected
+// status anymore or the state machine does not allow the transition. Actual is
+// empty in the latter case.
+type StatusConflictError struct {
+	TransactionID bson.ObjectID
+	From          validation_model.WorkerStatus
+	To            validation_model.WorkerStatus
+	Actual        validation_model.WorkerStatus
 }
 
+func (e *StatusConflictError) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("transaction %s can not move from %s to %s", e.TransactionID.Hex(), e.From, e.To)
+	}
+	return fmt.Sprintf("transaction %s is %s, expected %s to move to %s", e.TransactionID.Hex(), e.Actual, e.From, e.To)
+}
+
+// Instance names this process in the status history.
+var Instance = func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}()
+
 const (
 	collection_name = "validation_transaction"
 )
 
-func NewValidationRepository(logger *logger.Logger, db *mongo.Database) IValidationRepository {
-	return &ValidationRepository{logger: logger, db: db}
+// NewValidationRepository creates the repository, status changes are published
+// to events unless it is nil.
+func NewValidationRepository(logger *logger.Logger, db *mongo.Database, events validation_events.IStatusPublisher) IValidationRepository {
+	return &ValidationRepository{logger: logger, db: db, events: events}
 }
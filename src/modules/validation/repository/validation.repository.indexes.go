@@ -0,0 +1,57 @@
+package validation_repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnsureIndexes creates the indexes of the collections. The unique tx_hash index
+// keeps one transaction from being validated by several observers, the unique
+// query_id index keeps a transfer from matching several payment intents.
+func (r *ValidationRepository) EnsureIndexes(ctx context.Context) error {
+	r.logger.Infof("ensuring indexes of %s", collection_name)
+
+	collection := r.db.Collection(collection_name)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tx_hash", Value: 1}},
+			Options: options.Index().SetName("tx_hash_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_check_at", Value: 1}},
+			Options: options.Index().SetName("status_next_check_at"),
+		},
+	}
+
+	names, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		r.logger.Errorf("failed to create indexes of %s: %v", collection_name, err)
+		return err
+	}
+
+	r.logger.Infof("indexes of %s are ready: %v", collection_name, names)
+
+	intentIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "query_id", Value: 1}},
+			Options: options.Index().SetName("query_id_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("status_expires_at"),
+		},
+	}
+
+	names, err = r.db.Collection(intent_collection_name).Indexes().CreateMany(ctx, intentIndexes)
+	if err != nil {
+		r.logger.Errorf("failed to create indexes of %s: %v", intent_collection_name, err)
+		return err
+	}
+
+	r.logger.Infof("indexes of %s are ready: %v", intent_collection_name, names)
+	return nil
+}
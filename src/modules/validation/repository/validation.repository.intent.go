@@ -0,0 +1,164 @@
+package validation_repository
+
+import (
+	"context"
+	"time"
+
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	intent_collection_name = "validation_payment_intent"
+)
+
+// CreatePaymentIntent stores a new intent. A query ID already taken by another
+// intent fails with a duplicate key error.
+func (r *ValidationRepository) CreatePaymentIntent(ctx context.Context, intent validation_model.PaymentIntent) (validation_model.PaymentIntent, error) {
+	r.logger.Infof("creating payment intent with query id %d", intent.QueryID)
+
+	now := time.Now().Unix()
+	if intent.CreatedAt == 0 {
+		intent.CreatedAt = now
+	}
+	if intent.UpdatedAt == 0 {
+		intent.UpdatedAt = now
+	}
+	if intent.ID.IsZero() {
+		intent.ID = bson.NewObjectID()
+	}
+
+	collection := r.db.Collection(intent_collection_name)
+	if _, err := collection.InsertOne(ctx, intent); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			r.logger.Errorf("failed to insert payment intent: %v", err)
+		}
+		return validation_model.PaymentIntent{}, err
+	}
+
+	r.logger.Infof("payment intent created: %s", intent.ID.Hex())
+	return intent, nil
+}
+
+func (r *ValidationRepository) GetPaymentIntent(ctx context.Context, intentID bson.ObjectID) (validation_model.PaymentIntent, error) {
+	r.logger.Infof("getting payment intent: %s", intentID.Hex())
+
+	collection := r.db.Collection(intent_collection_name)
+
+	var intent validation_model.PaymentIntent
+	if err := collection.FindOne(ctx, bson.D{{Key: "_id", Value: intentID}}).Decode(&intent); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to get payment intent: %v", err)
+		}
+		return validation_model.PaymentIntent{}, err
+	}
+
+	return intent, nil
+}
+
+// GetOpenPaymentIntents lists the intents still waiting for a payment, with
+// the ones past their expiry not moved to expired yet.
+func (r *ValidationRepository) GetOpenPaymentIntents(ctx context.Context) ([]validation_model.PaymentIntent, error) {
+	collection := r.db.Collection(intent_collection_name)
+
+	filter := bson.D{{Key: "status", Value: validation_model.PaymentIntentOpen}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.Errorf("failed to get open payment intents: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	intents := []validation_model.PaymentIntent{}
+	if err := cursor.All(ctx, &intents); err != nil {
+		r.logger.Errorf("failed to decode payment intents: %v", err)
+		return nil, err
+	}
+
+	return intents, nil
+}
+
+// SettlePaymentIntent closes an open intent with the transfer that paid it.
+// Returns mongo.ErrNoDocuments when the intent is not open anymore.
+func (r *ValidationRepository) SettlePaymentIntent(ctx context.Context, intentID bson.ObjectID, settlement validation_model.PaymentIntentSettlement) (validation_model.PaymentIntent, error) {
+	r.logger.Infof("settling payment intent %s as %s with %s", intentID.Hex(), settlement.Status, settlement.TxHash)
+
+	collection := r.db.Collection(intent_collection_name)
+
+	filter := bson.D{
+		{Key: "_id", Value: intentID},
+		{Key: "status", Value: validation_model.PaymentIntentOpen},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: settlement.Status},
+		{Key: "tx_hash", Value: settlement.TxHash},
+		{Key: "tx_lt", Value: settlement.TxLt},
+		{Key: "detail", Value: settlement.Detail},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var intent validation_model.PaymentIntent
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&intent); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to settle payment intent: %v", err)
+		}
+		return validation_model.PaymentIntent{}, err
+	}
+
+	return intent, nil
+}
+
+// NotePaymentIntent stores the detail on an open intent without closing it.
+// Returns mongo.ErrNoDocuments when the intent is not open anymore.
+func (r *ValidationRepository) NotePaymentIntent(ctx context.Context, intentID bson.ObjectID, detail string) error {
+	collection := r.db.Collection(intent_collection_name)
+
+	filter := bson.D{
+		{Key: "_id", Value: intentID},
+		{Key: "status", Value: validation_model.PaymentIntentOpen},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "detail", Value: detail},
+		{Key: "updated_at", Value: time.Now().Unix()},
+	}}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to note payment intent: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ExpirePaymentIntents moves the given intents to expired if they are still
+// open and expired at `now`.
+func (r *ValidationRepository) ExpirePaymentIntents(ctx context.Context, now int64, intentIDs []bson.ObjectID) (int64, error) {
+	collection := r.db.Collection(intent_collection_name)
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: intentIDs}}},
+		{Key: "status", Value: validation_model.PaymentIntentOpen},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: validation_model.PaymentIntentExpired},
+		{Key: "updated_at", Value: now},
+	}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to expire payment intents: %v", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
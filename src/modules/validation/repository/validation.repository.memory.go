@@ -0,0 +1,354 @@
+package validation_repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// duplicateKeyCode is the mongo error code for a unique index violation.
+const duplicateKeyCode = 11000
+
+// MemoryRepository keeps transaction observers and payment intents in memory.
+// It follows the mongo repository closely, including its errors, and is meant
+// for tests and local runs without a database.
+type MemoryRepository struct {
+	mu           sync.Mutex
+	events       validation_events.IStatusPublisher
+	transactions map[bson.ObjectID]validation_model.WorkerTransaction
+	intents      map[bson.ObjectID]validation_model.PaymentIntent
+}
+
+func NewMemoryRepository(events validation_events.IStatusPublisher) IValidationRepository {
+	return &MemoryRepository{
+		events:       events,
+		transactions: map[bson.ObjectID]validation_model.WorkerTransaction{},
+		intents:      map[bson.ObjectID]validation_model.PaymentIntent{},
+	}
+}
+
+func (r *MemoryRepository) CreateTransactionObserver(ctx context.Context, transaction validation_model.WorkerTransaction) (validation_model.WorkerTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().Unix()
+	if transaction.CreatedAt == 0 {
+		transaction.CreatedAt = now
+	}
+	if transaction.UpdatedAt == 0 {
+		transaction.UpdatedAt = now
+	}
+	if transaction.ID.IsZero() {
+		transaction.ID = bson.NewObjectID()
+	}
+	if len(transaction.History) == 0 {
+		transaction.History = []validation_model.StatusTransition{{
+			To:       transaction.Status,
+			At:       transaction.CreatedAt,
+			Reason:   validation_model.ReasonSubmitted,
+			Instance: Instance,
+		}}
+	}
+
+	for _, existing := range r.transactions {
+		if existing.ID == transaction.ID || existing.TxHash == transaction.TxHash {
+			return validation_model.WorkerTransaction{}, mongo.WriteException{
+				WriteErrors: []mongo.WriteError{{Code: duplicateKeyCode, Message: "duplicate key"}},
+			}
+		}
+	}
+
+	r.transactions[transaction.ID] = clone(transaction)
+	r.publish(transaction)
+	return transaction, nil
+}
+
+func (r *MemoryRepository) GetTransactionObserver(ctx context.Context, transactionID bson.ObjectID) (validation_model.WorkerTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction, ok := r.transactions[transactionID]
+	if !ok {
+		return validation_model.WorkerTransaction{}, mongo.ErrNoDocuments
+	}
+	return clone(transaction), nil
+}
+
+func (r *MemoryRepository) GetTransactionObserverByTxHash(ctx context.Context, txHash string) (validation_model.WorkerTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, transaction := range r.transactions {
+		if transaction.TxHash == txHash {
+			return clone(transaction), nil
+		}
+	}
+	return validation_model.WorkerTransaction{}, mongo.ErrNoDocuments
+}
+
+func (r *MemoryRepository) GetTransactionObservers(ctx context.Context, filter validation_model.TransactionFilter) ([]validation_model.WorkerTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transactions := []validation_model.WorkerTransaction{}
+	for _, transaction := range r.transactions {
+		if filter.Status != "" && transaction.Status != filter.Status {
+			continue
+		}
+		if filter.TargetAddress != "" && transaction.TargetAddress != filter.TargetAddress {
+			continue
+		}
+		if !filter.PaymentOrderId.IsZero() && transaction.PaymentOrderId != filter.PaymentOrderId {
+			continue
+		}
+		transactions = append(transactions, clone(transaction))
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt > transactions[j].CreatedAt
+	})
+
+	if filter.Offset >= int64(len(transactions)) {
+		return []validation_model.WorkerTransaction{}, nil
+	}
+	transactions = transactions[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < int64(len(transactions)) {
+		transactions = transactions[:filter.Limit]
+	}
+	return transactions, nil
+}
+
+func (r *MemoryRepository) UpdateStatus(ctx context.Context, transactionID bson.ObjectID, from validation_model.WorkerStatus, to validation_model.WorkerStatus, change validation_model.StatusChange) (validation_model.WorkerTransaction, error) {
+	if !from.CanTransitionTo(to) {
+		return validation_model.WorkerTransaction{}, &StatusConflictError{TransactionID: transactionID, From: from, To: to}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction, ok := r.transactions[transactionID]
+	if !ok {
+		return validation_model.WorkerTransaction{}, mongo.ErrNoDocuments
+	}
+	if transaction.Status != from {
+		return validation_model.WorkerTransaction{}, &StatusConflictError{TransactionID: transactionID, From: from, To: to, Actual: transaction.Status}
+	}
+
+	now := time.Now().Unix()
+	transaction.Status = to
+	transaction.UpdatedAt = now
+	if len(change.Report) > 0 {
+		transaction.Report = change.Report
+	}
+	transaction.History = append(transaction.History, validation_model.StatusTransition{
+		From:     from,
+		To:       to,
+		At:       now,
+		Reason:   change.Reason,
+		Detail:   change.Detail,
+		Trace:    change.Trace,
+		Instance: Instance,
+	})
+
+	r.transactions[transactionID] = transaction
+	r.publish(transaction)
+	return clone(transaction), nil
+}
+
+func (r *MemoryRepository) PrecheckoutTransaction(ctx context.Context, transactionID bson.ObjectID) (validation_model.WorkerTransaction, error) {
+	transactionObserver, err := r.GetTransactionObserver(ctx, transactionID)
+	if err != nil {
+		return validation_model.WorkerTransaction{}, err
+	}
+
+	transaction, err := r.UpdateStatus(ctx, transactionID, transactionObserver.Status, validation_model.WorkerStatusRunning, validation_model.StatusChange{
+		Reason: validation_model.ReasonPrecheckout,
+	})
+	if err != nil {
+		return transactionObserver, err
+	}
+	return transaction, nil
+}
+
+func (r *MemoryRepository) DeleteTransactionObserver(ctx context.Context, transactionID bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.transactions, transactionID)
+	return nil
+}
+
+func (r *MemoryRepository) ClaimTransactionObserver(ctx context.Context, owner string, now int64, staleBefore int64, leaseUntil int64) (validation_model.WorkerTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed *validation_model.WorkerTransaction
+	for _, transaction := range r.transactions {
+		due := transaction.Status == validation_model.WorkerStatusPending ||
+			transaction.Status == validation_model.WorkerStatusWaiting ||
+			(transaction.Status == validation_model.WorkerStatusRunning && transaction.UpdatedAt < staleBefore)
+		if !due || transaction.NextCheckAt > now || transaction.LeaseUntil > now {
+			continue
+		}
+		if claimed == nil ||
+			transaction.NextCheckAt < claimed.NextCheckAt ||
+			(transaction.NextCheckAt == claimed.NextCheckAt && transaction.CreatedAt < claimed.CreatedAt) {
+			candidate := transaction
+			claimed = &candidate
+		}
+	}
+	if claimed == nil {
+		return validation_model.WorkerTransaction{}, mongo.ErrNoDocuments
+	}
+
+	claimed.LeaseOwner = owner
+	claimed.LeaseUntil = leaseUntil
+	r.transactions[claimed.ID] = *claimed
+	return clone(*claimed), nil
+}
+
+func (r *MemoryRepository) ReleaseTransactionObserver(ctx context.Context, transactionID bson.ObjectID, owner string, nextCheckAt int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction, ok := r.transactions[transactionID]
+	if !ok || transaction.LeaseOwner != owner {
+		return mongo.ErrNoDocuments
+	}
+
+	transaction.NextCheckAt = nextCheckAt
+	transaction.LeaseOwner = ""
+	transaction.LeaseUntil = 0
+	transaction.Attempts++
+	r.transactions[transactionID] = transaction
+	return nil
+}
+
+func (r *MemoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *MemoryRepository) CreatePaymentIntent(ctx context.Context, intent validation_model.PaymentIntent) (validation_model.PaymentIntent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().Unix()
+	if intent.CreatedAt == 0 {
+		intent.CreatedAt = now
+	}
+	if intent.UpdatedAt == 0 {
+		intent.UpdatedAt = now
+	}
+	if intent.ID.IsZero() {
+		intent.ID = bson.NewObjectID()
+	}
+
+	for _, existing := range r.intents {
+		if existing.ID == intent.ID || existing.QueryID == intent.QueryID {
+			return validation_model.PaymentIntent{}, mongo.WriteException{
+				WriteErrors: []mongo.WriteError{{Code: duplicateKeyCode, Message: "duplicate key"}},
+			}
+		}
+	}
+
+	r.intents[intent.ID] = intent
+	return intent, nil
+}
+
+func (r *MemoryRepository) GetPaymentIntent(ctx context.Context, intentID bson.ObjectID) (validation_model.PaymentIntent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intent, ok := r.intents[intentID]
+	if !ok {
+		return validation_model.PaymentIntent{}, mongo.ErrNoDocuments
+	}
+	return intent, nil
+}
+
+func (r *MemoryRepository) GetOpenPaymentIntents(ctx context.Context) ([]validation_model.PaymentIntent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intents := []validation_model.PaymentIntent{}
+	for _, intent := range r.intents {
+		if intent.Status == validation_model.PaymentIntentOpen {
+			intents = append(intents, intent)
+		}
+	}
+
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].CreatedAt < intents[j].CreatedAt
+	})
+	return intents, nil
+}
+
+func (r *MemoryRepository) SettlePaymentIntent(ctx context.Context, intentID bson.ObjectID, settlement validation_model.PaymentIntentSettlement) (validation_model.PaymentIntent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intent, ok := r.intents[intentID]
+	if !ok || intent.Status != validation_model.PaymentIntentOpen {
+		return validation_model.PaymentIntent{}, mongo.ErrNoDocuments
+	}
+
+	intent.Status = settlement.Status
+	intent.TxHash = settlement.TxHash
+	intent.TxLt = settlement.TxLt
+	intent.Detail = settlement.Detail
+	intent.UpdatedAt = time.Now().Unix()
+	r.intents[intentID] = intent
+	return intent, nil
+}
+
+func (r *MemoryRepository) NotePaymentIntent(ctx context.Context, intentID bson.ObjectID, detail string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intent, ok := r.intents[intentID]
+	if !ok || intent.Status != validation_model.PaymentIntentOpen {
+		return mongo.ErrNoDocuments
+	}
+
+	intent.Detail = detail
+	intent.UpdatedAt = time.Now().Unix()
+	r.intents[intentID] = intent
+	return nil
+}
+
+func (r *MemoryRepository) ExpirePaymentIntents(ctx context.Context, now int64, intentIDs []bson.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired int64
+	for _, id := range intentIDs {
+		intent, ok := r.intents[id]
+		if ok && intent.Status == validation_model.PaymentIntentOpen && intent.ExpiresAt <= now {
+			intent.Status = validation_model.PaymentIntentExpired
+			intent.UpdatedAt = now
+			r.intents[id] = intent
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (r *MemoryRepository) publish(transaction validation_model.WorkerTransaction) {
+	if r.events != nil {
+		r.events.Publish(validation_events.NewStatusEvent(transaction))
+	}
+}
+
+// clone copies the history and the report so callers can not change stored
+// observers.
+func clone(transaction validation_model.WorkerTransaction) validation_model.WorkerTransaction {
+	transaction.History = append([]validation_model.StatusTransition(nil), transaction.History...)
+	transaction.Report = append([]validation_model.TraceNode(nil), transaction.Report...)
+	return transaction
+}
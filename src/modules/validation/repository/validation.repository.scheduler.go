@@ -0,0 +1,85 @@
+package validation_repository
+
+import (
+	"context"
+
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ClaimTransactionObserver leases the transaction that is due for a check to
+// owner until `leaseUntil`. Due are queued and waiting transactions and running
+// ones not updated since `staleBefore`, whose next check time has come and
+// whose lease, if any, has expired. Returns mongo.ErrNoDocuments when nothing
+// is due.
+func (r *ValidationRepository) ClaimTransactionObserver(ctx context.Context, owner string, now int64, staleBefore int64, leaseUntil int64) (validation_model.WorkerTransaction, error) {
+	collection := r.db.Collection(collection_name)
+
+	filter := bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{
+				validation_model.WorkerStatusPending,
+				validation_model.WorkerStatusWaiting,
+			}}}}},
+			bson.D{
+				{Key: "status", Value: validation_model.WorkerStatusRunning},
+				{Key: "updated_at", Value: bson.D{{Key: "$lt", Value: staleBefore}}},
+			},
+		}},
+		{Key: "next_check_at", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gt", Value: now}}}}},
+		{Key: "lease_until", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gt", Value: now}}}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "lease_owner", Value: owner},
+		{Key: "lease_until", Value: leaseUntil},
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_check_at", Value: 1}, {Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var transaction validation_model.WorkerTransaction
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&transaction); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.Errorf("failed to claim transaction observer: %v", err)
+		}
+		return validation_model.WorkerTransaction{}, err
+	}
+
+	r.logger.Infof("transaction observer %s claimed by %s, attempt %d", transaction.ID.Hex(), owner, transaction.Attempts+1)
+	return transaction, nil
+}
+
+// ReleaseTransactionObserver drops the lease of owner, counts the attempt and
+// schedules the next check at `nextCheckAt`. Returns mongo.ErrNoDocuments when
+// the lease was lost to another instance.
+func (r *ValidationRepository) ReleaseTransactionObserver(ctx context.Context, transactionID bson.ObjectID, owner string, nextCheckAt int64) error {
+	collection := r.db.Collection(collection_name)
+
+	filter := bson.D{
+		{Key: "_id", Value: transactionID},
+		{Key: "lease_owner", Value: owner},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "next_check_at", Value: nextCheckAt}}},
+		{Key: "$unset", Value: bson.D{
+			{Key: "lease_owner", Value: ""},
+			{Key: "lease_until", Value: ""},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.logger.Errorf("failed to release transaction observer: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		r.logger.Warnf("transaction observer %s is no longer leased by %s", transactionID.Hex(), owner)
+		return mongo.ErrNoDocuments
+	}
+
+	r.logger.Infof("transaction observer %s released, next check at %d", transactionID.Hex(), nextCheckAt)
+	return nil
+}
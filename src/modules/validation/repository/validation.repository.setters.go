@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
 	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
@@ -27,6 +29,15 @@ func (r *ValidationRepository) CreateTransactionObserver(ctx context.Context, tr
 		transaction.ID = bson.NewObjectID()
 	}
 
+	if len(transaction.History) == 0 {
+		transaction.History = []validation_model.StatusTransition{{
+			To:       transaction.Status,
+			At:       transaction.CreatedAt,
+			Reason:   validation_model.ReasonSubmitted,
+			Instance: Instance,
+		}}
+	}
+
 	collection := r.db.Collection(collection_name)
 
 	result, err := collection.InsertOne(ctx, transaction)
@@ -37,27 +48,63 @@ func (r *ValidationRepository) CreateTransactionObserver(ctx context.Context, tr
 
 	r.logger.Infof("transaction observer created with ID: %v", result.InsertedID)
 	r.logger.Infof("transaction observer created successfully: %v", transaction)
+	if r.events != nil {
+		r.events.Publish(validation_events.NewStatusEvent(transaction))
+	}
 	return transaction, nil
 }
 
-func (r *ValidationRepository) UpdateStatus(ctx context.Context, transactionID bson.ObjectID, status validation_model.WorkerStatus) (validation_model.WorkerTransaction, error) {
-	r.logger.Infof("updating status for transaction: %v", transactionID)
+// UpdateStatus moves the transaction from `from` to `to` in one step, appends
+// the change to its history and stores the trace report it carries. It returns
+// *StatusConflictError when the transition is not allowed or the transaction
+// is no longer in `from`, and mongo.ErrNoDocuments when it does not exist.
+func (r *ValidationRepository) UpdateStatus(ctx context.Context, transactionID bson.ObjectID, from validation_model.WorkerStatus, to validation_model.WorkerStatus, change validation_model.StatusChange) (validation_model.WorkerTransaction, error) {
+	r.logger.Infof("updating status for transaction %v from %s to %s, reason %s", transactionID, from, to, change.Reason)
+
+	if !from.CanTransitionTo(to) {
+		r.logger.Warnf("transaction %v can not move from %s to %s", transactionID, from, to)
+		return validation_model.WorkerTransaction{}, &StatusConflictError{TransactionID: transactionID, From: from, To: to}
+	}
 
 	collection := r.db.Collection(collection_name)
 
-	filter := bson.D{{Key: "_id", Value: transactionID}}
+	filter := bson.D{
+		{Key: "_id", Value: transactionID},
+		{Key: "status", Value: from},
+	}
+	now := time.Now().Unix()
+	set := bson.D{
+		{Key: "status", Value: to},
+		{Key: "updated_at", Value: now},
+	}
+	if len(change.Report) > 0 {
+		set = append(set, bson.E{Key: "report", Value: change.Report})
+	}
 	update := bson.D{
-		{Key: "$set", Value: bson.D{
-			{Key: "status", Value: status},
-			{Key: "updated_at", Value: time.Now().Unix()},
-		}},
+		{Key: "$set", Value: set},
+		{Key: "$push", Value: bson.D{{Key: "history", Value: validation_model.StatusTransition{
+			From:     from,
+			To:       to,
+			At:       now,
+			Reason:   change.Reason,
+			Detail:   change.Detail,
+			Trace:    change.Trace,
+			Instance: Instance,
+		}}}},
 	}
 
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
 	var updatedDoc validation_model.WorkerTransaction
 	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDoc)
-
+	if err == mongo.ErrNoDocuments {
+		current, err := r.GetTransactionObserver(ctx, transactionID)
+		if err != nil {
+			return validation_model.WorkerTransaction{}, err
+		}
+		r.logger.Warnf("transaction %v is %s, expected %s", transactionID, current.Status, from)
+		return validation_model.WorkerTransaction{}, &StatusConflictError{TransactionID: transactionID, From: from, To: to, Actual: current.Status}
+	}
 	if err != nil {
 		r.logger.Errorf("failed to update status: %v", err)
 		return validation_model.WorkerTransaction{}, err
@@ -65,9 +112,14 @@ func (r *ValidationRepository) UpdateStatus(ctx context.Context, transactionID b
 
 	r.logger.Infof("status updated for transaction: %v", transactionID)
 	r.logger.Infof("new status order transaction: %v", updatedDoc.Status)
+	if r.events != nil {
+		r.events.Publish(validation_events.NewStatusEvent(updatedDoc))
+	}
 	return updatedDoc, nil
 }
 
+// PrecheckoutTransaction moves a pending or waiting transaction to running.
+// Of two concurrent calls only one succeeds, the other gets *StatusConflictError.
 func (r *ValidationRepository) PrecheckoutTransaction(ctx context.Context, transactionID bson.ObjectID) (validation_model.WorkerTransaction, error) {
 	r.logger.Infof("get transaction in db: %+v", transactionID)
 
@@ -77,16 +129,14 @@ func (r *ValidationRepository) PrecheckoutTransaction(ctx context.Context, trans
 		return validation_model.WorkerTransaction{}, err
 	}
 
-	r.logger.Infof("current transaction status: %v (%T)", transactionObserver.Status, transactionObserver.Status)
-	if transactionObserver.Status != validation_model.WorkerStatusRunning {
-		r.logger.Warnf("transaction status is not running: %v", transactionObserver.Status)
-	}
-
+	r.logger.Infof("current transaction status: %v", transactionObserver.Status)
 	r.logger.Info("update transaction status to running")
-	transaction, err := r.UpdateStatus(ctx, transactionID, validation_model.WorkerStatusRunning)
+	transaction, err := r.UpdateStatus(ctx, transactionID, transactionObserver.Status, validation_model.WorkerStatusRunning, validation_model.StatusChange{
+		Reason: validation_model.ReasonPrecheckout,
+	})
 	if err != nil {
 		r.logger.Errorf("failed to update status: %v", err)
-		return validation_model.WorkerTransaction{}, err
+		return transactionObserver, err
 	}
 
 	r.logger.Infof("transaction status updated to running: %v", transaction.Status)
@@ -0,0 +1,188 @@
+package validation_service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	"github.com/shopspring/decimal"
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const jettonDecimals = 9
+
+// JettonTransfer is a decoded jetton transfer request found in a trace.
+// Source is the owner that asked its jetton wallet to send, Destination is
+// the owner receiving the jettons. Both are raw addresses.
+type JettonTransfer struct {
+	Source      string
+	Destination string
+	Amount      *big.Int
+}
+
+// CollectJettonTransfers decodes every jetton transfer request of the trace, parents first.
+func CollectJettonTransfers(trace *tonapi.Trace) []JettonTransfer {
+	transfers := []JettonTransfer{}
+	if msg, ok := trace.Transaction.InMsg.Get(); ok {
+		if transfer, ok := decodeJettonTransfer(msg); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	for i := range trace.Children {
+		transfers = append(transfers, CollectJettonTransfers(&trace.Children[i])...)
+	}
+
+	return transfers
+}
+
+func decodeJettonTransfer(msg tonapi.Message) (JettonTransfer, bool) {
+	rawBody, ok := msg.RawBody.Get()
+	if !ok {
+		return JettonTransfer{}, false
+	}
+
+	source, ok := msg.Source.Get()
+	if !ok {
+		return JettonTransfer{}, false
+	}
+
+	boc, err := hex.DecodeString(rawBody)
+	if err != nil {
+		return JettonTransfer{}, false
+	}
+
+	body, err := cell.FromBOC(boc)
+	if err != nil {
+		return JettonTransfer{}, false
+	}
+
+	var payload jetton.TransferPayload
+	if err := tlb.LoadFromCell(&payload, body.BeginParse()); err != nil {
+		return JettonTransfer{}, false
+	}
+
+	if payload.Destination == nil {
+		return JettonTransfer{}, false
+	}
+
+	return JettonTransfer{
+		Source:      source.Address,
+		Destination: payload.Destination.StringRaw(),
+		Amount:      payload.Amount.Nano(),
+	}, true
+}
+
+func toJettonNano(amount decimal.Decimal) (*big.Int, error) {
+	coins, err := tlb.FromDecimal(amount.String(), jettonDecimals)
+	if err != nil {
+		return nil, err
+	}
+	return coins.Nano(), nil
+}
+
+// VerifyDistribution checks that the payer sent the expected total and that the
+// contract receiving it forwarded every expected amount. It returns waiting
+// while the contract has not distributed anything yet and mismatch with the
+// reason when an amount differs.
+func VerifyDistribution(trace *tonapi.Trace, payerAddress string, expectation validation_dto.PaymentExpectation) (validation_dto.WorkerStatus, string) {
+	payer, err := address.ParseAddr(payerAddress)
+	if err != nil {
+		return validation_dto.WorkerStatusMismatch, fmt.Sprintf("invalid payer address: %v", err)
+	}
+
+	transfers := CollectJettonTransfers(trace)
+
+	var payment *JettonTransfer
+	for i := range transfers {
+		if strings.EqualFold(transfers[i].Source, payer.StringRaw()) {
+			payment = &transfers[i]
+			break
+		}
+	}
+	if payment == nil {
+		return validation_dto.WorkerStatusMismatch, "no jetton transfer from the payer found"
+	}
+
+	expectedTotal, err := toJettonNano(expectation.TotalAmount)
+	if err != nil {
+		return validation_dto.WorkerStatusMismatch, fmt.Sprintf("invalid expected total: %v", err)
+	}
+	if payment.Amount.Cmp(expectedTotal) != 0 {
+		return validation_dto.WorkerStatusMismatch, fmt.Sprintf("transferred %s, expected %s", payment.Amount, expectedTotal)
+	}
+
+	received := map[string]*big.Int{}
+	for _, transfer := range transfers {
+		if !strings.EqualFold(transfer.Source, payment.Destination) {
+			continue
+		}
+		key := strings.ToLower(transfer.Destination)
+		if received[key] == nil {
+			received[key] = new(big.Int)
+		}
+		received[key].Add(received[key], transfer.Amount)
+	}
+
+	if len(received) == 0 && len(expectation.Recipients) > 0 {
+		return validation_dto.WorkerStatusWaiting, "contract did not distribute the payment yet"
+	}
+
+	for _, recipient := range expectation.Recipients {
+		recipientAddress, err := address.ParseAddr(recipient.Address)
+		if err != nil {
+			return validation_dto.WorkerStatusMismatch, fmt.Sprintf("invalid recipient address %s: %v", recipient.Address, err)
+		}
+
+		expected, err := toJettonNano(recipient.Amount)
+		if err != nil {
+			return validation_dto.WorkerStatusMismatch, fmt.Sprintf("invalid expected amount for %s: %v", recipient.Address, err)
+		}
+
+		got := received[strings.ToLower(recipientAddress.StringRaw())]
+		if got == nil {
+			return validation_dto.WorkerStatusMismatch, fmt.Sprintf("%s received nothing, expected %s", recipient.Address, expected)
+		}
+		if got.Cmp(expected) != 0 {
+			return validation_dto.WorkerStatusMismatch, fmt.Sprintf("%s received %s, expected %s", recipient.Address, got, expected)
+		}
+	}
+
+	return validation_dto.WorkerStatusSuccess, ""
+}
+
+// verifyPayment compares the trace with the payment orders bound to the
+// transaction and tells why it is not a success. Transactions without orders
+// are not checked.
+func (s *ValidationService) verifyPayment(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO, trace *tonapi.Trace) (validation_dto.WorkerStatus, string, error) {
+	paymentOrderID := transaction.PaymentOrderId
+	if paymentOrderID == bson.NilObjectID.Hex() {
+		paymentOrderID = ""
+	}
+
+	if s.payment_orders == nil || (paymentOrderID == "" && transaction.PaymentBundleId == "") {
+		return validation_dto.WorkerStatusSuccess, "", nil
+	}
+
+	expectation, err := s.payment_orders.PaymentExpectation(ctx, paymentOrderID, transaction.PaymentBundleId)
+	if err != nil {
+		s.logger.Errorf("failed to get expected payment: %v", err)
+		return "", "", err
+	}
+
+	s.logger.Infof("expected payment: %+v", expectation)
+	status, reason := VerifyDistribution(trace, transaction.TargetAddress, expectation)
+	if status != validation_dto.WorkerStatusSuccess {
+		s.logger.Warnf("payment distribution check is %s: %s", status, reason)
+	}
+
+	return status, reason, nil
+}
@@ -0,0 +1,65 @@
+package validation_service
+
+import (
+	"context"
+
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const defaultTransactionsLimit = 50
+
+func (s *ValidationService) GetTransaction(ctx context.Context, transactionID string) (*validation_dto.WorkerTransactionDTO, error) {
+	s.logger.Infof("get transaction: %s", transactionID)
+
+	id, err := bson.ObjectIDFromHex(transactionID)
+	if err != nil {
+		s.logger.Errorf("failed to convert transaction id: %v", err)
+		return nil, errors.NewError(400, "invalid transaction ID")
+	}
+
+	transaction, err := s.validation_repository.GetTransactionObserver(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewError(404, "transaction not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get transaction: %v", err)
+		return nil, errors.NewError(500, "failed to get transaction")
+	}
+
+	return validation_adapters.TransactionModelToDTOPoint(transaction), nil
+}
+
+func (s *ValidationService) GetTransactions(ctx context.Context, query validation_dto.TransactionQuery) ([]validation_dto.WorkerTransactionDTO, error) {
+	s.logger.Infof("get transactions: %+v", query)
+
+	filter := validation_model.TransactionFilter{
+		Status:        validation_model.WorkerStatus(query.Status),
+		TargetAddress: query.TargetAddress,
+		Limit:         query.Limit,
+		Offset:        query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultTransactionsLimit
+	}
+	if query.PaymentOrderId != "" {
+		paymentOrderID, err := bson.ObjectIDFromHex(query.PaymentOrderId)
+		if err != nil {
+			s.logger.Errorf("failed to convert payment order id: %v", err)
+			return nil, errors.NewError(400, "invalid payment order ID")
+		}
+		filter.PaymentOrderId = paymentOrderID
+	}
+
+	transactions, err := s.validation_repository.GetTransactionObservers(ctx, filter)
+	if err != nil {
+		s.logger.Errorf("failed to get transactions: %v", err)
+		return nil, errors.NewError(500, "failed to get transactions")
+	}
+
+	return validation_adapters.TransactionModelsToDTO(transactions), nil
+}
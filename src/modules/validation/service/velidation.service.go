@@ -5,26 +5,43 @@ import (
 
 	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
 	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
-	"github.com/tonkeeper/tonapi-go"
 )
 
 type IValidationService interface {
 	RunnerTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO) (*validation_dto.WorkerTransactionDTO, bool, error)
 	SubWorkerTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO) (*validation_dto.WorkerTransactionDTO, bool, error)
 	WorkerTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO) (*validation_dto.WorkerTransactionDTO, bool, error)
+	RecheckTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO, final bool) (*validation_dto.WorkerTransactionDTO, error)
+	GetTransaction(ctx context.Context, transactionID string) (*validation_dto.WorkerTransactionDTO, error)
+	GetTransactions(ctx context.Context, query validation_dto.TransactionQuery) ([]validation_dto.WorkerTransactionDTO, error)
+	GetTransactionHistory(ctx context.Context, transactionID string) (*validation_dto.TransactionHistory, error)
+}
+
+// IPaymentOrderHook settles the payment orders a validated transaction pays for.
+type IPaymentOrderHook interface {
+	MarkPaymentOrderPaid(ctx context.Context, paymentOrderID string, trHash string) error
+	ReopenPaymentOrder(ctx context.Context, paymentOrderID string) error
+	MarkPaymentBundlePaid(ctx context.Context, bundleID string, trHash string) error
+	ReopenPaymentBundle(ctx context.Context, bundleID string) error
+	PaymentExpectation(ctx context.Context, paymentOrderID string, bundleID string) (validation_dto.PaymentExpectation, error)
 }
 
 type ValidationService struct {
-	logger  *logger.Logger
-	ton_api *tonapi.Client
+	logger *logger.Logger
+	traces validation_trace.ITraceProvider
 
 	validation_repository validation_repository.IValidationRepository
+	payment_orders        IPaymentOrderHook
 }
 
+// NewValidationService creates the service, payment_orders may be nil when
+// transactions are not bound to payment orders.
 func NewValidationService(
-	logger *logger.Logger, ton_api *tonapi.Client,
+	logger *logger.Logger, traces validation_trace.ITraceProvider,
 	validation_repository validation_repository.IValidationRepository,
+	payment_orders IPaymentOrderHook,
 ) IValidationService {
-	return &ValidationService{logger: logger, ton_api: ton_api, validation_repository: validation_repository}
+	return &ValidationService{logger: logger, traces: traces, validation_repository: validation_repository, payment_orders: payment_orders}
 }
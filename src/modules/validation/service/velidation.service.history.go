@@ -0,0 +1,74 @@
+package validation_service
+
+import (
+	"context"
+
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/tonkeeper/tonapi-go"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SummarizeTrace keeps the root transaction of the trace and the first
+// transaction of the tree that did not succeed.
+func SummarizeTrace(trace *tonapi.Trace) *validation_model.TraceSummary {
+	summary := &validation_model.TraceSummary{
+		Hash:    trace.Transaction.Hash,
+		Lt:      trace.Transaction.Lt,
+		Success: true,
+	}
+	summarizeTraceNode(trace, summary)
+	return summary
+}
+
+func summarizeTraceNode(trace *tonapi.Trace, summary *validation_model.TraceSummary) {
+	summary.Transactions++
+
+	tx := trace.Transaction
+	failed := !tx.Success || tx.Aborted
+	if compute, ok := tx.ComputePhase.Get(); ok && !compute.Skipped && !compute.Success.Value {
+		failed = true
+	}
+	if action, ok := tx.ActionPhase.Get(); ok && (!action.Success || action.ResultCode != 0) {
+		failed = true
+	}
+
+	if failed && summary.Success {
+		summary.Success = false
+		summary.FailedHash = tx.Hash
+		if compute, ok := tx.ComputePhase.Get(); ok {
+			summary.ComputeExitCode = compute.ExitCode.Value
+		}
+		if action, ok := tx.ActionPhase.Get(); ok {
+			summary.ActionResultCode = action.ResultCode
+		}
+	}
+
+	for i := range trace.Children {
+		summarizeTraceNode(&trace.Children[i], summary)
+	}
+}
+
+func (s *ValidationService) GetTransactionHistory(ctx context.Context, transactionID string) (*validation_dto.TransactionHistory, error) {
+	s.logger.Infof("get transaction history: %s", transactionID)
+
+	id, err := bson.ObjectIDFromHex(transactionID)
+	if err != nil {
+		s.logger.Errorf("failed to convert transaction id: %v", err)
+		return nil, errors.NewError(400, "invalid transaction ID")
+	}
+
+	transaction, err := s.validation_repository.GetTransactionObserver(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewError(404, "transaction not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get transaction: %v", err)
+		return nil, errors.NewError(500, "failed to get transaction")
+	}
+
+	return validation_adapters.TransactionHistoryModelToDTO(transaction), nil
+}
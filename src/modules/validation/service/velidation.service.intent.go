@@ -0,0 +1,272 @@
+package validation_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/xssnick/tonutils-go/address"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// queryIDAttempts bounds the retries when a generated query ID is taken.
+const queryIDAttempts = 3
+
+type IPaymentIntentService interface {
+	CreatePaymentIntent(ctx context.Context, intent *validation_dto.CreatePaymentIntentDTO) (*validation_dto.PaymentIntentDTO, error)
+	GetPaymentIntent(ctx context.Context, intentID string) (*validation_dto.PaymentIntentDTO, error)
+	MatchPaymentIntents(ctx context.Context) (int, error)
+}
+
+// PaymentIntentOptions configures new intents. Recipient and JettonMaster are
+// used when the client does not name them, TTL is how long an intent stays
+// open by default.
+type PaymentIntentOptions struct {
+	Recipient    string
+	JettonMaster string
+	TTL          time.Duration
+}
+
+// PaymentIntentService creates payment intents and settles them with the
+// incoming jetton transfers carrying their query ID.
+type PaymentIntentService struct {
+	logger    *logger.Logger
+	options   PaymentIntentOptions
+	transfers validation_trace.ITransferSource
+
+	validation_repository validation_repository.IValidationRepository
+}
+
+func NewPaymentIntentService(
+	logger *logger.Logger, options PaymentIntentOptions,
+	transfers validation_trace.ITransferSource,
+	validation_repository validation_repository.IValidationRepository,
+) IPaymentIntentService {
+	return &PaymentIntentService{logger: logger, options: options, transfers: transfers, validation_repository: validation_repository}
+}
+
+func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, dto *validation_dto.CreatePaymentIntentDTO) (*validation_dto.PaymentIntentDTO, error) {
+	recipient := dto.Recipient
+	if recipient == "" {
+		recipient = s.options.Recipient
+	}
+	jettonMaster := dto.JettonMaster
+	if jettonMaster == "" {
+		jettonMaster = s.options.JettonMaster
+	}
+
+	addresses := []struct{ name, value string }{
+		{"payer", dto.Payer},
+		{"recipient", recipient},
+		{"jetton master", jettonMaster},
+	}
+	for _, addr := range addresses {
+		if _, err := address.ParseAddr(addr.value); err != nil {
+			s.logger.Errorf("invalid %s address %q: %v", addr.name, addr.value, err)
+			return nil, errors.NewError(400, "invalid "+addr.name+" address")
+		}
+	}
+
+	amount, ok := new(big.Int).SetString(dto.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.NewError(400, "amount must be a positive integer")
+	}
+
+	ttl := s.options.TTL
+	if dto.ExpiresIn > 0 {
+		ttl = time.Duration(dto.ExpiresIn) * time.Second
+	}
+
+	now := time.Now()
+	intent := validation_model.PaymentIntent{
+		Payer:        dto.Payer,
+		Recipient:    recipient,
+		JettonMaster: jettonMaster,
+		Amount:       amount.String(),
+		Comment:      dto.Comment,
+		Status:       validation_model.PaymentIntentOpen,
+		ExpiresAt:    now.Add(ttl).Unix(),
+		CreatedAt:    now.Unix(),
+		UpdatedAt:    now.Unix(),
+	}
+
+	for attempt := 1; ; attempt++ {
+		queryID, err := NewQueryID()
+		if err != nil {
+			s.logger.Errorf("failed to generate query id: %v", err)
+			return nil, errors.NewError(500, "failed to create payment intent")
+		}
+		intent.ID = bson.NewObjectID()
+		intent.QueryID = queryID
+
+		created, err := s.validation_repository.CreatePaymentIntent(ctx, intent)
+		if mongo.IsDuplicateKeyError(err) && attempt < queryIDAttempts {
+			s.logger.Warnf("query id %d is taken, generating another one", queryID)
+			continue
+		}
+		if err != nil {
+			s.logger.Errorf("failed to create payment intent: %v", err)
+			return nil, errors.NewError(500, "failed to create payment intent")
+		}
+
+		s.logger.Infof("payment intent %s created, query id %d", created.ID.Hex(), created.QueryID)
+		return validation_adapters.PaymentIntentModelToDTO(created), nil
+	}
+}
+
+func (s *PaymentIntentService) GetPaymentIntent(ctx context.Context, intentID string) (*validation_dto.PaymentIntentDTO, error) {
+	id, err := bson.ObjectIDFromHex(intentID)
+	if err != nil {
+		s.logger.Errorf("failed to convert payment intent id: %v", err)
+		return nil, errors.NewError(400, "invalid payment intent ID")
+	}
+
+	intent, err := s.validation_repository.GetPaymentIntent(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewError(404, "payment intent not found")
+	}
+	if err != nil {
+		s.logger.Errorf("failed to get payment intent: %v", err)
+		return nil, errors.NewError(500, "failed to get payment intent")
+	}
+
+	return validation_adapters.PaymentIntentModelToDTO(intent), nil
+}
+
+// MatchPaymentIntents settles the open intents paid to the jetton wallet of
+// their recipient with their query ID, including the ones that expired since
+// the last run but were paid in time. Transfers that carry the query ID but do
+// not pay the intent are noted on it, the intent stays open. Overdue intents
+// still unpaid after matching are expired. It returns how many intents were
+// settled.
+func (s *PaymentIntentService) MatchPaymentIntents(ctx context.Context) (int, error) {
+	now := time.Now().Unix()
+
+	intents, err := s.validation_repository.GetOpenPaymentIntents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	groups := map[string][]validation_model.PaymentIntent{}
+	for _, intent := range intents {
+		key := intent.Recipient + "/" + intent.JettonMaster
+		groups[key] = append(groups[key], intent)
+	}
+
+	settled := 0
+	// overdue lists the unpaid intents past their expiry whose transfers were
+	// checked, the others are kept open until their transfers can be fetched
+	overdue := []bson.ObjectID{}
+	for _, group := range groups {
+		recipient, jettonMaster := group[0].Recipient, group[0].JettonMaster
+		transfers, err := s.transfers.IncomingTransfers(ctx, recipient, jettonMaster)
+		if err != nil {
+			s.logger.Errorf("failed to get transfers to %s of %s: %v", recipient, jettonMaster, err)
+			continue
+		}
+
+		for _, intent := range group {
+			settlement, ok := MatchTransfer(intent, transfers)
+			if !ok {
+				if intent.ExpiresAt <= now {
+					overdue = append(overdue, intent.ID)
+				}
+				if settlement.Detail != "" && settlement.Detail != intent.Detail {
+					s.logger.Warnf("payment intent %s received transfers that do not pay it: %s", intent.ID.Hex(), settlement.Detail)
+					if err := s.validation_repository.NotePaymentIntent(ctx, intent.ID, settlement.Detail); err != nil && err != mongo.ErrNoDocuments {
+						s.logger.Errorf("failed to note mismatch of payment intent %s: %v", intent.ID.Hex(), err)
+					}
+				}
+				continue
+			}
+
+			updated, err := s.validation_repository.SettlePaymentIntent(ctx, intent.ID, settlement)
+			if err == mongo.ErrNoDocuments {
+				s.logger.Warnf("payment intent %s was settled concurrently", intent.ID.Hex())
+				continue
+			}
+			if err != nil {
+				s.logger.Errorf("failed to settle payment intent %s: %v", intent.ID.Hex(), err)
+				continue
+			}
+
+			s.logger.Infof("payment intent %s is %s by %s", updated.ID.Hex(), updated.Status, updated.TxHash)
+			settled++
+		}
+	}
+
+	if len(overdue) > 0 {
+		expired, err := s.validation_repository.ExpirePaymentIntents(ctx, now, overdue)
+		if err != nil {
+			return settled, err
+		}
+		if expired > 0 {
+			s.logger.Infof("%d payment intents expired", expired)
+		}
+	}
+
+	return settled, nil
+}
+
+// MatchTransfer finds the successful transfer carrying the query ID of the
+// intent that the payer sent with the exact amount before the intent expired.
+// Transfers with the query ID from someone else, with another amount or sent
+// too late do not settle the intent, they are only described in the detail of
+// the settlement.
+func MatchTransfer(intent validation_model.PaymentIntent, transfers []validation_trace.IncomingTransfer) (validation_model.PaymentIntentSettlement, bool) {
+	payer, err := address.ParseAddr(intent.Payer)
+	if err != nil {
+		return validation_model.PaymentIntentSettlement{Detail: fmt.Sprintf("invalid payer address: %v", err)}, false
+	}
+
+	mismatches := []string{}
+	for _, transfer := range transfers {
+		if transfer.QueryID != intent.QueryID || !transfer.Success {
+			continue
+		}
+
+		switch {
+		case !strings.EqualFold(payer.StringRaw(), transfer.Sender):
+			mismatches = append(mismatches, fmt.Sprintf("%s paid by %s, expected %s", transfer.TxHash, transfer.Sender, intent.Payer))
+		case transfer.Amount == nil || transfer.Amount.String() != intent.Amount:
+			mismatches = append(mismatches, fmt.Sprintf("%s transferred %s, expected %s", transfer.TxHash, transfer.Amount, intent.Amount))
+		case transfer.Utime > intent.ExpiresAt:
+			mismatches = append(mismatches, fmt.Sprintf("%s sent at %d, after the intent expired at %d", transfer.TxHash, transfer.Utime, intent.ExpiresAt))
+		default:
+			return validation_model.PaymentIntentSettlement{
+				Status: validation_model.PaymentIntentPaid,
+				TxHash: transfer.TxHash,
+				TxLt:   transfer.Lt,
+				Detail: strings.Join(mismatches, "; "),
+			}, true
+		}
+	}
+
+	return validation_model.PaymentIntentSettlement{Detail: strings.Join(mismatches, "; ")}, false
+}
+
+// NewQueryID returns a random non-zero query ID that fits in an int64, which is
+// how mongo stores it.
+func NewQueryID() (uint64, error) {
+	var buf [8]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, err
+		}
+		if queryID := binary.BigEndian.Uint64(buf[:]) >> 1; queryID != 0 {
+			return queryID, nil
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package validation_service
+
+import (
+	"context"
+
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RecheckTransaction moves a queued, waiting or stale transaction to running,
+// fetches its trace and stores the outcome. When the trace or the expected
+// payment can not be fetched the transaction goes to waiting and the error is
+// returned so the caller can retry. When final is set nothing is retried: a
+// transaction that still waits or can not be checked is finalized as failed.
+func (s *ValidationService) RecheckTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO, final bool) (*validation_dto.WorkerTransactionDTO, error) {
+	s.logger.Infof("recheck transaction %s, final %t", transaction.TxHash, final)
+	transactionID, err := bson.ObjectIDFromHex(transaction.ID)
+	if err != nil {
+		s.logger.Errorf("failed to convert transaction id: %v", err)
+		return transaction, err
+	}
+
+	if transaction.Status != validation_dto.WorkerStatusRunning {
+		tr, err := s.validation_repository.UpdateStatus(ctx, transactionID, validation_model.WorkerStatus(transaction.Status), validation_model.WorkerStatusRunning, validation_model.StatusChange{
+			Reason: validation_model.ReasonRecheck,
+		})
+		if err != nil {
+			s.logger.Errorf("failed to move transaction to running: %v", err)
+			return transaction, err
+		}
+		transaction = validation_adapters.TransactionModelToDTOPoint(tr)
+	}
+
+	status, change, err := s.evaluateTransaction(ctx, transaction)
+	if err != nil && !final {
+		if waiting, _, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusWaiting, change); err == nil {
+			transaction = waiting
+		}
+		return transaction, err
+	}
+	if err != nil || (final && status == validation_dto.WorkerStatusWaiting) {
+		s.logger.Warnf("transaction %s did not complete before the deadline", transaction.TxHash)
+		status = validation_dto.WorkerStatusFailed
+		if change.Detail != "" {
+			change.Detail = string(change.Reason) + ": " + change.Detail
+		} else {
+			change.Detail = string(change.Reason)
+		}
+		change.Reason = validation_model.ReasonDeadlineExceeded
+	}
+
+	updated, _, err := s.finalizeTransaction(ctx, transactionID, status, change)
+	if err != nil {
+		s.logger.Errorf("failed to finalize transaction: %v", err)
+		return transaction, err
+	}
+
+	s.logger.Infof("transaction %s rechecked, status: %v", transaction.TxHash, updated.Status)
+	return updated, nil
+}
+
+// evaluateTransaction runs the checks of WorkerTransaction against a fresh trace
+// without storing the result. The change tells why the status was chosen, also
+// when an error is returned.
+func (s *ValidationService) evaluateTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO) (validation_dto.WorkerStatus, validation_model.StatusChange, error) {
+	txTrace, err := s.traces.GetTrace(ctx, validation_trace.TraceRequest{TxHash: transaction.TxHash, Account: transaction.TargetAddress})
+	if err != nil {
+		s.logger.Errorf("failed to get transaction trace: %v", err)
+		return "", validation_model.StatusChange{Reason: validation_model.ReasonTraceFetchError, Detail: err.Error()}, err
+	}
+
+	verdict, report := EvaluateTrace(txTrace)
+	change := validation_model.StatusChange{Trace: SummarizeTrace(txTrace), Report: report}
+
+	if !s.IsAccountValid(transaction, txTrace) {
+		s.logger.Errorf("account is not valid: %v", transaction.TargetAddress)
+		change.Reason = validation_model.ReasonInvalidAccount
+		return validation_dto.WorkerStatusFailed, change, nil
+	}
+
+	switch verdict {
+	case validation_dto.WorkerStatusFailed:
+		change.Reason = validation_model.ReasonComputePhaseFailed
+		change.Detail = describeFailure(report, validation_model.WorkerStatusFailed)
+		return validation_dto.WorkerStatusFailed, change, nil
+	case validation_dto.WorkerStatusWaiting:
+		change.Reason = validation_model.ReasonActionPhaseFailed
+		change.Detail = describeFailure(report, validation_model.WorkerStatusWaiting)
+		return validation_dto.WorkerStatusWaiting, change, nil
+	}
+
+	status, reason, err := s.verifyPayment(ctx, transaction, txTrace)
+	change.Detail = reason
+	switch {
+	case err != nil:
+		change.Reason = validation_model.ReasonExpectationError
+		change.Detail = err.Error()
+	case status == validation_dto.WorkerStatusWaiting:
+		change.Reason = validation_model.ReasonDistributionPending
+	case status == validation_dto.WorkerStatusMismatch:
+		change.Reason = validation_model.ReasonDistributionFailed
+	default:
+		change.Reason = validation_model.ReasonValidated
+	}
+
+	return status, change, err
+}
@@ -5,8 +5,10 @@ import (
 
 	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
 	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func (s *ValidationService) RunnerTransaction(ctx context.Context, transaction *validation_dto.WorkerTransactionDTO) (*validation_dto.WorkerTransactionDTO, bool, error) {
@@ -51,17 +53,48 @@ func (s *ValidationService) RunnerTransaction(ctx context.Context, transaction *
 			return transaction, false, err
 		}
 
+		s.logger.Infof("get transaction in db by tx hash: %s", transaction.TxHash)
+		existing, err := s.validation_repository.GetTransactionObserverByTxHash(ctx, transaction.TxHash)
+		if err == nil {
+			return s.reuseTransactionObserver(transactionModel, existing)
+		}
+		if err != mongo.ErrNoDocuments {
+			s.logger.Errorf("failed to get transaction by tx hash: %v", err)
+			return transaction, false, errors.NewError(500, "failed to get transaction by tx hash")
+		}
+
 		s.logger.Info("create transaction observer")
-		transactionModel, err = s.validation_repository.CreateTransactionObserver(ctx, transactionModel)
+		created, err := s.validation_repository.CreateTransactionObserver(ctx, transactionModel)
+		if mongo.IsDuplicateKeyError(err) {
+			s.logger.Warnf("transaction with tx hash %s was created concurrently", transaction.TxHash)
+			existing, err := s.validation_repository.GetTransactionObserverByTxHash(ctx, transaction.TxHash)
+			if err != nil {
+				s.logger.Errorf("failed to get transaction by tx hash: %v", err)
+				return transaction, false, errors.NewError(500, "failed to get transaction by tx hash")
+			}
+			return s.reuseTransactionObserver(transactionModel, existing)
+		}
 		if err != nil {
 			s.logger.Errorf("failed to create transaction observer: %v", err)
 			return transaction, false, err
 		}
 
-		s.logger.Infof("transaction observer created successfully: %+v", transactionModel.ID)
+		s.logger.Infof("transaction observer created successfully: %+v", created.ID)
 		s.logger.Info("convert transaction model to dto")
-		transactionDTO := validation_adapters.TransactionModelToDTOPoint(transactionModel)
+		transactionDTO := validation_adapters.TransactionModelToDTOPoint(created)
 		s.logger.Info("transaction dto created successfully")
 		return transactionDTO, true, nil
 	}
 }
+
+// reuseTransactionObserver returns the observer already validating the tx hash
+// unless the submitted transaction binds it to other payment orders.
+func (s *ValidationService) reuseTransactionObserver(submitted validation_model.WorkerTransaction, existing validation_model.WorkerTransaction) (*validation_dto.WorkerTransactionDTO, bool, error) {
+	if existing.PaymentOrderId != submitted.PaymentOrderId || existing.PaymentBundleId != submitted.PaymentBundleId {
+		s.logger.Warnf("tx hash %s is already used by transaction %s for other payment orders", existing.TxHash, existing.ID.Hex())
+		return validation_adapters.TransactionModelToDTOPoint(submitted), false, errors.NewError(409, "tx hash is already used for another payment order")
+	}
+
+	s.logger.Infof("tx hash %s is already observed by transaction %s", existing.TxHash, existing.ID.Hex())
+	return validation_adapters.TransactionModelToDTOPoint(existing), true, nil
+}
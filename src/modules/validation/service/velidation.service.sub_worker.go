@@ -5,6 +5,7 @@ import (
 
 	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
 	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -24,6 +25,10 @@ func (s *ValidationService) SubWorkerTransaction(ctx context.Context, transactio
 	tr, err := s.validation_repository.PrecheckoutTransaction(ctx, transactionID)
 	s.logger.Infof("transaction after precheckout in db: %+v", tr)
 	transaction = validation_adapters.TransactionModelToDTOPoint(tr)
+	if conflict, ok := err.(*validation_repository.StatusConflictError); ok {
+		s.logger.Warnf("transaction is already processed: %v", conflict)
+		return transaction, false, errors.NewError(409, conflict.Error())
+	}
 	if err != nil {
 		s.logger.Errorf("failed to precheckout transaction: %v", err)
 		return transaction, false, errors.NewError(400, err.Error())
@@ -2,35 +2,90 @@ package validation_service
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
 	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
 	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
 	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
 	"github.com/tonkeeper/tonapi-go"
 	"github.com/xssnick/tonutils-go/address"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// IsTransactionValid returns the aggregated verdict of EvaluateTrace.
 func IsTransactionValid(tx *tonapi.Trace) validation_dto.WorkerStatus {
-	if tx.Transaction.ComputePhase.IsSet() &&
-		!tx.Transaction.ComputePhase.Value.Skipped &&
-		!tx.Transaction.ComputePhase.Value.Success.Value {
-		return validation_dto.WorkerStatusFailed
+	status, _ := EvaluateTrace(tx)
+	return status
+}
+
+// EvaluateTrace checks every transaction of the trace and reports each of them
+// in depth-first order. A failed compute phase anywhere in the tree fails the
+// trace, otherwise a failed action phase anywhere leaves it waiting.
+func EvaluateTrace(tx *tonapi.Trace) (validation_dto.WorkerStatus, []validation_model.TraceNode) {
+	report := []validation_model.TraceNode{}
+	evaluateTraceNode(tx, 0, &report)
+
+	status := validation_dto.WorkerStatusSuccess
+	for _, node := range report {
+		switch node.Status {
+		case validation_model.WorkerStatusFailed:
+			return validation_dto.WorkerStatusFailed, report
+		case validation_model.WorkerStatusWaiting:
+			status = validation_dto.WorkerStatusWaiting
+		}
+	}
+
+	return status, report
+}
+
+func evaluateTraceNode(tx *tonapi.Trace, depth int, report *[]validation_model.TraceNode) {
+	node := validation_model.TraceNode{
+		Hash:    tx.Transaction.Hash,
+		Address: tx.Transaction.Account.Address,
+		Depth:   depth,
+		Success: tx.Transaction.Success,
+		Status:  validation_model.WorkerStatusSuccess,
+	}
+	if in, ok := tx.Transaction.InMsg.Get(); ok {
+		node.OpCode = in.OpCode.Value
 	}
 
-	if tx.Transaction.ActionPhase.IsSet() &&
-		(!tx.Transaction.ActionPhase.Value.Success || tx.Transaction.ActionPhase.Value.ResultCode != 0) {
-		return validation_dto.WorkerStatusWaiting
+	if compute, ok := tx.Transaction.ComputePhase.Get(); ok {
+		node.ExitCode = compute.ExitCode.Value
+		if !compute.Skipped && !compute.Success.Value {
+			node.Status = validation_model.WorkerStatusFailed
+		}
 	}
 
-	for _, child := range tx.Children {
-		return IsTransactionValid(&child)
+	if action, ok := tx.Transaction.ActionPhase.Get(); ok {
+		node.ResultCode = action.ResultCode
+		if node.Status == validation_model.WorkerStatusSuccess && (!action.Success || action.ResultCode != 0) {
+			node.Status = validation_model.WorkerStatusWaiting
+		}
 	}
 
-	return validation_dto.WorkerStatusSuccess
+	*report = append(*report, node)
+	for i := range tx.Children {
+		evaluateTraceNode(&tx.Children[i], depth+1, report)
+	}
+}
+
+// describeFailure names the first transaction of the report with the status,
+// so the history shows which hop decided it.
+func describeFailure(report []validation_model.TraceNode, status validation_model.WorkerStatus) string {
+	for _, node := range report {
+		if node.Status != status {
+			continue
+		}
+		return fmt.Sprintf("transaction %s of %s at depth %d, op %s: exit code %d, result code %d",
+			node.Hash, node.Address, node.Depth, node.OpCode, node.ExitCode, node.ResultCode)
+	}
+	return ""
 }
 
 func (s *ValidationService) IsAccountValid(transaction *validation_dto.WorkerTransactionDTO, trace *tonapi.Trace) bool {
@@ -61,7 +116,9 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 	select {
 	case <-ctx.Done():
 		s.logger.Infof("transaction validation timed out")
-		transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed)
+		transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed, validation_model.StatusChange{
+			Reason: validation_model.ReasonTimeout,
+		})
 		if err != nil {
 			s.logger.Errorf("failed to finalize transaction: %v", err)
 			return transaction, false, err
@@ -71,27 +128,36 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 		return transaction, status, errors.NewError(408, "transaction validation timed out")
 	default:
 		s.logger.Infof("get transaction trace by tx hash: %v", transaction.TxHash)
-		txTrace, err := s.ton_api.GetTrace(ctx, tonapi.GetTraceParams{
-			TraceID: transaction.TxHash,
-		})
+		txTrace, err := s.traces.GetTrace(ctx, validation_trace.TraceRequest{TxHash: transaction.TxHash, Account: transaction.TargetAddress})
 
 		if err != nil {
+			// the trace may not be indexed yet, the scheduler checks the waiting
+			// transaction again until its deadline, the orders stay awaiting payment
 			s.logger.Errorf("failed to get transaction trace: %v", err)
-			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusWaiting, validation_model.StatusChange{
+				Reason: validation_model.ReasonTraceFetchError,
+				Detail: err.Error(),
+			})
 			if err != nil {
 				s.logger.Errorf("failed to finalize transaction: %v", err)
 				return transaction, false, err
 			}
-			s.logger.Infof("transaction finalize error, status: %v", status)
+			s.logger.Infof("transaction status updated to waiting: %v", status)
 			s.logger.Infof("transaction data: %+v", transaction)
-			return transaction, status, errors.NewError(502, "failed to get transaction trace")
+			return transaction, status, errors.NewError(502, "failed to get transaction trace, the transaction is checked again")
 		}
 
 		s.logger.Infof("validate transaction: %v", transaction.TxHash)
+		traceSummary := SummarizeTrace(txTrace)
+		isValid, report := EvaluateTrace(txTrace)
 		isAccountValid := s.IsAccountValid(transaction, txTrace)
 		if !isAccountValid {
 			s.logger.Errorf("account is not valid: %v", transaction.TargetAddress)
-			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed, validation_model.StatusChange{
+				Reason: validation_model.ReasonInvalidAccount,
+				Trace:  traceSummary,
+				Report: report,
+			})
 			if err != nil {
 				s.logger.Errorf("failed to finalize transaction: %v", err)
 				return transaction, false, err
@@ -101,10 +167,14 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 			return transaction, status, errors.NewError(400, "account is not valid")
 		}
 
-		isValid := IsTransactionValid(txTrace)
 		if isValid == validation_dto.WorkerStatusWaiting {
 			s.logger.Warnf("transaction incomplete, waiting: %v", transaction.TxHash)
-			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusWaiting)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusWaiting, validation_model.StatusChange{
+				Reason: validation_model.ReasonActionPhaseFailed,
+				Detail: describeFailure(report, validation_model.WorkerStatusWaiting),
+				Trace:  traceSummary,
+				Report: report,
+			})
 			if err != nil {
 				s.logger.Errorf("failed to finalize transaction: %v", err)
 				return transaction, false, err
@@ -116,7 +186,12 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 
 		if isValid == validation_dto.WorkerStatusFailed {
 			s.logger.Errorf("transaction failed: %v", transaction.TxHash)
-			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusFailed, validation_model.StatusChange{
+				Reason: validation_model.ReasonComputePhaseFailed,
+				Detail: describeFailure(report, validation_model.WorkerStatusFailed),
+				Trace:  traceSummary,
+				Report: report,
+			})
 			if err != nil {
 				s.logger.Errorf("failed to finalize transaction: %v", err)
 				return transaction, false, err
@@ -126,8 +201,52 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 			return transaction, status, nil
 		}
 
+		s.logger.Infof("verify payment distribution: %v", transaction.TxHash)
+		paymentStatus, paymentReason, err := s.verifyPayment(ctx, transaction, txTrace)
+		if err != nil {
+			return transaction, false, errors.NewError(500, "failed to get expected payment")
+		}
+
+		if paymentStatus == validation_dto.WorkerStatusWaiting {
+			s.logger.Warnf("payment not distributed yet, waiting: %v", transaction.TxHash)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusWaiting, validation_model.StatusChange{
+				Reason: validation_model.ReasonDistributionPending,
+				Detail: paymentReason,
+				Trace:  traceSummary,
+				Report: report,
+			})
+			if err != nil {
+				s.logger.Errorf("failed to finalize transaction: %v", err)
+				return transaction, false, err
+			}
+			s.logger.Infof("transaction status updated to waiting: %v", status)
+			s.logger.Infof("transaction data: %+v", transaction)
+			return transaction, status, nil
+		}
+
+		if paymentStatus == validation_dto.WorkerStatusMismatch {
+			s.logger.Errorf("payment amounts mismatch: %v", transaction.TxHash)
+			transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusMismatch, validation_model.StatusChange{
+				Reason: validation_model.ReasonDistributionFailed,
+				Detail: paymentReason,
+				Trace:  traceSummary,
+				Report: report,
+			})
+			if err != nil {
+				s.logger.Errorf("failed to finalize transaction: %v", err)
+				return transaction, false, err
+			}
+			s.logger.Infof("transaction status updated to mismatch: %v", status)
+			s.logger.Infof("transaction data: %+v", transaction)
+			return transaction, status, errors.NewError(422, "transferred amounts do not match the payment orders")
+		}
+
 		s.logger.Infof("validate transaction success")
-		transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusSuccess)
+		transaction, status, err := s.finalizeTransaction(ctx, transactionID, validation_dto.WorkerStatusSuccess, validation_model.StatusChange{
+			Reason: validation_model.ReasonValidated,
+			Trace:  traceSummary,
+			Report: report,
+		})
 		if err != nil {
 			s.logger.Errorf("failed to finalize transaction: %v", err)
 			return transaction, false, err
@@ -138,10 +257,26 @@ func (s *ValidationService) WorkerTransaction(ctx context.Context, transaction *
 	}
 }
 
-func (s *ValidationService) finalizeTransaction(ctx context.Context, transactionID bson.ObjectID, status validation_dto.WorkerStatus) (*validation_dto.WorkerTransactionDTO, bool, error) {
+// finalizeTransaction stores the outcome of a running transaction. The payment
+// orders of a valid transaction are marked as paid before it is stored as
+// success; when that fails it goes to waiting instead, so the scheduler checks
+// it again and retries the settlement.
+func (s *ValidationService) finalizeTransaction(ctx context.Context, transactionID bson.ObjectID, status validation_dto.WorkerStatus, change validation_model.StatusChange) (*validation_dto.WorkerTransactionDTO, bool, error) {
+	var settleErr error
+	if status == validation_dto.WorkerStatusSuccess {
+		if settleErr = s.markPaymentOrderPaid(ctx, transactionID); settleErr != nil {
+			status = validation_dto.WorkerStatusWaiting
+			change.Reason = validation_model.ReasonSettlementPending
+			change.Detail = settleErr.Error()
+		}
+	}
 	success := status == validation_dto.WorkerStatusSuccess
 
-	tr, err := s.validation_repository.UpdateStatus(ctx, transactionID, validation_model.WorkerStatus(status))
+	tr, err := s.validation_repository.UpdateStatus(ctx, transactionID, validation_model.WorkerStatusRunning, validation_model.WorkerStatus(status), change)
+	if conflict, ok := err.(*validation_repository.StatusConflictError); ok {
+		s.logger.Warnf("status update conflict: %v", conflict)
+		return nil, false, errors.NewError(409, conflict.Error())
+	}
 	if err != nil {
 		s.logger.Errorf("status update failed: %v", err)
 		return nil, false, errors.NewError(400, "status update failed, transaction id: "+transactionID.Hex())
@@ -150,5 +285,83 @@ func (s *ValidationService) finalizeTransaction(ctx context.Context, transaction
 	transaction := validation_adapters.TransactionModelToDTOPoint(tr)
 	s.logger.Infof("finalize transaction, status: %v", tr.Status)
 	s.logger.Infof("finalize transaction data: %+v", transaction)
+
+	if settleErr != nil {
+		return transaction, false, errors.NewError(500, "transaction is valid but its payment orders were not marked as paid, settlement is retried")
+	}
+
+	s.releasePaymentOrder(ctx, tr)
 	return transaction, success, nil
 }
+
+// paymentSettlement returns how to settle the payment orders bound to the
+// transaction. A bundle takes precedence over a single order, ok is false when
+// the transaction is not bound to any.
+func (s *ValidationService) paymentSettlement(ctx context.Context, tr validation_model.WorkerTransaction) (target string, markPaid func() error, reopen func() error, ok bool) {
+	if s.payment_orders == nil {
+		return "", nil, nil, false
+	}
+
+	switch {
+	case tr.PaymentBundleId != "":
+		target = "payment bundle " + tr.PaymentBundleId
+		markPaid = func() error { return s.payment_orders.MarkPaymentBundlePaid(ctx, tr.PaymentBundleId, tr.TxHash) }
+		reopen = func() error { return s.payment_orders.ReopenPaymentBundle(ctx, tr.PaymentBundleId) }
+	case !tr.PaymentOrderId.IsZero():
+		target = "payment order " + tr.PaymentOrderId.Hex()
+		markPaid = func() error { return s.payment_orders.MarkPaymentOrderPaid(ctx, tr.PaymentOrderId.Hex(), tr.TxHash) }
+		reopen = func() error { return s.payment_orders.ReopenPaymentOrder(ctx, tr.PaymentOrderId.Hex()) }
+	default:
+		return "", nil, nil, false
+	}
+
+	return target, markPaid, reopen, true
+}
+
+// markPaymentOrderPaid marks the payment orders bound to the running
+// transaction as paid. Orders already paid by the same transaction count as
+// paid, so a settlement interrupted before the status update can be repeated.
+func (s *ValidationService) markPaymentOrderPaid(ctx context.Context, transactionID bson.ObjectID) error {
+	if s.payment_orders == nil {
+		return nil
+	}
+
+	tr, err := s.validation_repository.GetTransactionObserver(ctx, transactionID)
+	if err != nil {
+		s.logger.Errorf("failed to get transaction to settle: %v", err)
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	target, markPaid, _, ok := s.paymentSettlement(ctx, tr)
+	if !ok {
+		return nil
+	}
+
+	s.logger.Infof("marking %s as paid", target)
+	if err := markPaid(); err != nil {
+		s.logger.Errorf("failed to mark %s as paid: %v", target, err)
+		return fmt.Errorf("%s was not marked as paid: %w", target, err)
+	}
+
+	return nil
+}
+
+// releasePaymentOrder moves the payment orders bound to a failed transaction
+// back to open. Orders of a mismatched one are left for a manual check.
+func (s *ValidationService) releasePaymentOrder(ctx context.Context, tr validation_model.WorkerTransaction) {
+	target, _, reopen, ok := s.paymentSettlement(ctx, tr)
+	if !ok {
+		return
+	}
+
+	switch tr.Status {
+	case validation_model.WorkerStatusFailed:
+		s.logger.Infof("reopening %s", target)
+		if err := reopen(); err != nil {
+			s.logger.Warnf("%s was not reopened: %v", target, err)
+		}
+	case validation_model.WorkerStatusMismatch:
+		// jettons did move, so the orders stay awaiting payment for a manual check
+		s.logger.Warnf("%s is left awaiting payment, transferred amounts mismatch", target)
+	}
+}
@@ -0,0 +1,37 @@
+package validation_trace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// FallbackProvider asks the providers in order and returns the first trace.
+type FallbackProvider struct {
+	logger    *logger.Logger
+	providers []ITraceProvider
+}
+
+func NewFallbackProvider(logger *logger.Logger, providers ...ITraceProvider) ITraceProvider {
+	return &FallbackProvider{logger: logger, providers: providers}
+}
+
+func (p *FallbackProvider) GetTrace(ctx context.Context, request TraceRequest) (*tonapi.Trace, error) {
+	errs := make([]error, 0, len(p.providers))
+	for i, provider := range p.providers {
+		trace, err := provider.GetTrace(ctx, request)
+		if err == nil {
+			return trace, nil
+		}
+
+		p.logger.Warnf("trace provider %d failed for %s: %v", i, request.TxHash, err)
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package validation_trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// FixtureProvider replays traces recorded from tonapi. The directory holds one
+// `<tx_hash>.json` file per trace with the body of the tonapi trace response.
+type FixtureProvider struct {
+	dir string
+}
+
+func NewFixtureProvider(dir string) (ITraceProvider, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace fixtures: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("trace fixtures %s is not a directory", dir)
+	}
+
+	return &FixtureProvider{dir: dir}, nil
+}
+
+func (p *FixtureProvider) GetTrace(ctx context.Context, request TraceRequest) (*tonapi.Trace, error) {
+	name := strings.ToLower(request.TxHash)
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, fmt.Errorf("invalid tx hash %q", request.TxHash)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: no fixture for %s", ErrTraceNotFound, request.TxHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace fixture: %w", err)
+	}
+
+	trace := new(tonapi.Trace)
+	if err := trace.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("failed to decode trace fixture %s: %w", name, err)
+	}
+
+	return trace, nil
+}
@@ -0,0 +1,26 @@
+package validation_trace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// ErrTraceNotFound is returned when the provider does not know the transaction.
+var ErrTraceNotFound = errors.New("trace not found")
+
+// TraceRequest identifies the root transaction of a trace.
+type TraceRequest struct {
+	// TxHash is the hex encoded hash of the root transaction.
+	TxHash string
+	// Account holding the root transaction. Providers that can not look a
+	// transaction up by its hash alone search the account history.
+	Account string
+}
+
+// ITraceProvider returns the tree of transactions caused by a transaction.
+// Traces are returned in the tonapi shape whichever source they come from.
+type ITraceProvider interface {
+	GetTrace(ctx context.Context, request TraceRequest) (*tonapi.Trace, error)
+}
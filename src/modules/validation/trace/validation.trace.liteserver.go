@@ -0,0 +1,259 @@
+package validation_trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+)
+
+const (
+	// liteserverScanLimit is how many transactions of an account are searched
+	// for the root transaction and for every child.
+	liteserverScanLimit = 60
+	liteserverPageSize  = 15
+	// liteserverMaxDepth and liteserverMaxNodes bound the size of a rebuilt trace.
+	liteserverMaxDepth = 16
+	liteserverMaxNodes = 256
+)
+
+// LiteserverProvider rebuilds traces from liteserver data: it finds the root
+// transaction in the account history and follows every internal out message
+// to the transaction it caused. Messages not processed yet are left out, so a
+// trace still in progress looks like tonapi returns it.
+type LiteserverProvider struct {
+	ton_client *ton.APIClient
+}
+
+func NewLiteserverProvider(ton_client *ton.APIClient) ITraceProvider {
+	return &LiteserverProvider{ton_client: ton_client}
+}
+
+func (p *LiteserverProvider) GetTrace(ctx context.Context, request TraceRequest) (*tonapi.Trace, error) {
+	hash, err := hex.DecodeString(request.TxHash)
+	if err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("invalid tx hash %q", request.TxHash)
+	}
+
+	account, err := address.ParseAddr(request.Account)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account %q: %w", request.Account, err)
+	}
+
+	root, err := p.findTransaction(ctx, account, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := 0
+	return p.buildTrace(ctx, account, root, 0, &nodes)
+}
+
+func (p *LiteserverProvider) findTransaction(ctx context.Context, account *address.Address, hash []byte) (*tlb.Transaction, error) {
+	return p.scanTransactions(ctx, account, func(transaction *tlb.Transaction) bool {
+		return bytes.Equal(transaction.Hash, hash)
+	})
+}
+
+// scanTransactions walks the account history from the newest transaction and
+// returns the first one accepted by match.
+func (p *LiteserverProvider) scanTransactions(ctx context.Context, account *address.Address, match func(*tlb.Transaction) bool) (*tlb.Transaction, error) {
+	block, err := p.ton_client.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get masterchain info: %w", err)
+	}
+
+	state, err := p.ton_client.WaitForBlock(block.SeqNo).GetAccount(ctx, block, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if !state.IsActive {
+		return nil, fmt.Errorf("%w: account %s is not active", ErrTraceNotFound, account)
+	}
+
+	lt, txHash := state.LastTxLT, state.LastTxHash
+	for scanned := 0; lt != 0 && scanned < liteserverScanLimit; scanned += liteserverPageSize {
+		transactions, err := p.ton_client.ListTransactions(ctx, account, liteserverPageSize, lt, txHash)
+		if errors.Is(err, ton.ErrNoTransactionsWereFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		for _, transaction := range transactions {
+			if match(transaction) {
+				return transaction, nil
+			}
+		}
+
+		lt, txHash = transactions[0].PrevTxLT, transactions[0].PrevTxHash
+	}
+
+	return nil, fmt.Errorf("%w: no matching transaction among the last %d of %s", ErrTraceNotFound, liteserverScanLimit, account)
+}
+
+// findChild looks for the transaction of the destination account whose inbound
+// message is the given one. Sender and creation lt identify a message, unlike
+// the body hash, which repeats for plain transfers.
+func (p *LiteserverProvider) findChild(ctx context.Context, message *tlb.InternalMessage) (*tlb.Transaction, error) {
+	return p.scanTransactions(ctx, message.DstAddr, func(transaction *tlb.Transaction) bool {
+		if transaction.IO.In == nil || transaction.IO.In.MsgType != tlb.MsgTypeInternal {
+			return false
+		}
+		in := transaction.IO.In.AsInternal()
+		return in.CreatedLT == message.CreatedLT && in.SrcAddr.Equals(message.SrcAddr)
+	})
+}
+
+func (p *LiteserverProvider) buildTrace(ctx context.Context, account *address.Address, transaction *tlb.Transaction, depth int, nodes *int) (*tonapi.Trace, error) {
+	*nodes++
+
+	trace := &tonapi.Trace{
+		Transaction: convertTransaction(account, transaction),
+		Interfaces:  []string{},
+		Children:    []tonapi.Trace{},
+	}
+	if depth >= liteserverMaxDepth || transaction.IO.Out == nil {
+		return trace, nil
+	}
+
+	messages, err := transaction.IO.Out.ToSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read out messages: %w", err)
+	}
+
+	for _, message := range messages {
+		if message.MsgType != tlb.MsgTypeInternal || *nodes >= liteserverMaxNodes {
+			continue
+		}
+
+		internal := message.AsInternal()
+		destination := internal.DstAddr
+		child, err := p.findChild(ctx, internal)
+		if errors.Is(err, ErrTraceNotFound) {
+			// the message is not processed yet
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find transaction caused by message to %s: %w", destination, err)
+		}
+
+		childTrace, err := p.buildTrace(ctx, destination, child, depth+1, nodes)
+		if err != nil {
+			return nil, err
+		}
+		trace.Children = append(trace.Children, *childTrace)
+	}
+
+	return trace, nil
+}
+
+func convertTransaction(account *address.Address, transaction *tlb.Transaction) tonapi.Transaction {
+	tx := tonapi.Transaction{
+		Hash:            hex.EncodeToString(transaction.Hash),
+		Lt:              int64(transaction.LT),
+		Account:         tonapi.AccountAddress{Address: account.StringRaw()},
+		Utime:           int64(transaction.Now),
+		TransactionType: tonapi.TransactionTypeTransOrd,
+		OutMsgs:         []tonapi.Message{},
+		Success:         true,
+	}
+	if transaction.PrevTxHash != nil {
+		tx.PrevTransHash.SetTo(hex.EncodeToString(transaction.PrevTxHash))
+		tx.PrevTransLt.SetTo(int64(transaction.PrevTxLT))
+	}
+
+	if description, ok := transaction.Description.(tlb.TransactionDescriptionOrdinary); ok {
+		tx.Aborted = description.Aborted
+		tx.Destroyed = description.Destroyed
+
+		switch phase := description.ComputePhase.Phase.(type) {
+		case tlb.ComputePhaseVM:
+			compute := tonapi.ComputePhase{}
+			compute.Success.SetTo(phase.Success)
+			compute.ExitCode.SetTo(phase.Details.ExitCode)
+			tx.ComputePhase.SetTo(compute)
+			tx.Success = tx.Success && phase.Success
+		case tlb.ComputePhaseSkipped:
+			tx.ComputePhase.SetTo(tonapi.ComputePhase{Skipped: true})
+		}
+
+		if description.ActionPhase != nil {
+			tx.ActionPhase.SetTo(tonapi.ActionPhase{
+				Success:        description.ActionPhase.Success,
+				ResultCode:     description.ActionPhase.ResultCode,
+				TotalActions:   int32(description.ActionPhase.TotalActions),
+				SkippedActions: int32(description.ActionPhase.SkippedActions),
+			})
+			tx.Success = tx.Success && description.ActionPhase.Success
+		}
+		tx.Success = tx.Success && !description.Aborted
+	}
+
+	if transaction.IO.In != nil {
+		tx.InMsg.SetTo(convertMessage(transaction.IO.In))
+	}
+	if transaction.IO.Out != nil {
+		if messages, err := transaction.IO.Out.ToSlice(); err == nil {
+			for i := range messages {
+				tx.OutMsgs = append(tx.OutMsgs, convertMessage(&messages[i]))
+			}
+		}
+	}
+
+	return tx
+}
+
+func convertMessage(message *tlb.Message) tonapi.Message {
+	msg := tonapi.Message{}
+	if c, err := tlb.ToCell(message.Msg); err == nil {
+		msg.Hash = hex.EncodeToString(c.Hash())
+	}
+
+	switch message.MsgType {
+	case tlb.MsgTypeInternal:
+		internal := message.AsInternal()
+		msg.MsgType = tonapi.MessageMsgTypeIntMsg
+		msg.Bounce = internal.Bounce
+		msg.Bounced = internal.Bounced
+		msg.Value = internal.Amount.Nano().Int64()
+		msg.CreatedLt = int64(internal.CreatedLT)
+		msg.CreatedAt = int64(internal.CreatedAt)
+		setAccount(&msg.Source, internal.SrcAddr)
+		setAccount(&msg.Destination, internal.DstAddr)
+	case tlb.MsgTypeExternalIn:
+		msg.MsgType = tonapi.MessageMsgTypeExtInMsg
+		setAccount(&msg.Destination, message.AsExternalIn().DstAddr)
+	case tlb.MsgTypeExternalOut:
+		msg.MsgType = tonapi.MessageMsgTypeExtOutMsg
+		setAccount(&msg.Source, message.AsExternalOut().SrcAddr)
+	}
+
+	if body := message.Msg.Payload(); body != nil {
+		msg.RawBody.SetTo(hex.EncodeToString(body.ToBOC()))
+		if slice := body.BeginParse(); slice.BitsLeft() >= 32 {
+			if op, err := slice.LoadUInt(32); err == nil {
+				msg.OpCode.SetTo(fmt.Sprintf("0x%08x", op))
+			}
+		}
+	}
+
+	return msg
+}
+
+func setAccount(target *tonapi.OptAccountAddress, addr *address.Address) {
+	if addr == nil || addr.IsAddrNone() {
+		return
+	}
+	target.SetTo(tonapi.AccountAddress{Address: addr.StringRaw()})
+}
@@ -0,0 +1,19 @@
+package validation_trace
+
+import (
+	"context"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+type TonapiProvider struct {
+	ton_api *tonapi.Client
+}
+
+func NewTonapiProvider(ton_api *tonapi.Client) ITraceProvider {
+	return &TonapiProvider{ton_api: ton_api}
+}
+
+func (p *TonapiProvider) GetTrace(ctx context.Context, request TraceRequest) (*tonapi.Trace, error) {
+	return p.ton_api.GetTrace(ctx, tonapi.GetTraceParams{TraceID: request.TxHash})
+}
@@ -0,0 +1,140 @@
+package validation_trace
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+)
+
+// opInternalTransfer is sent by the jetton wallet of the sender to the jetton
+// wallet of the receiver.
+const opInternalTransfer = 0x178d4519
+
+// IncomingTransfer is a jetton transfer received by the jetton wallet of an owner.
+type IncomingTransfer struct {
+	TxHash  string
+	Lt      uint64
+	Utime   int64
+	QueryID uint64
+	Amount  *big.Int
+	// Sender is the raw address of the owner that sent the jettons.
+	Sender string
+	// Success is false when the receiving jetton wallet rejected the transfer.
+	Success bool
+}
+
+// ITransferSource lists the latest jetton transfers received by owner, newest first.
+type ITransferSource interface {
+	IncomingTransfers(ctx context.Context, owner string, jettonMaster string) ([]IncomingTransfer, error)
+}
+
+// TransferSource reads incoming transfers from the liteserver. Jetton wallet
+// addresses are resolved once per owner and master.
+type TransferSource struct {
+	traces  *LiteserverProvider
+	wallets sync.Map
+}
+
+func NewTransferSource(ton_client *ton.APIClient) ITransferSource {
+	return &TransferSource{traces: &LiteserverProvider{ton_client: ton_client}}
+}
+
+func (s *TransferSource) IncomingTransfers(ctx context.Context, owner string, jettonMaster string) ([]IncomingTransfer, error) {
+	wallet, err := s.jettonWallet(ctx, owner, jettonMaster)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := []IncomingTransfer{}
+	_, err = s.traces.scanTransactions(ctx, wallet, func(transaction *tlb.Transaction) bool {
+		if transfer, ok := decodeIncomingTransfer(transaction); ok {
+			transfers = append(transfers, transfer)
+		}
+		return false
+	})
+	// nothing matches on purpose, so not found only means the scan is over
+	if err != nil && !errors.Is(err, ErrTraceNotFound) {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (s *TransferSource) jettonWallet(ctx context.Context, owner string, jettonMaster string) (*address.Address, error) {
+	key := owner + "/" + jettonMaster
+	if wallet, ok := s.wallets.Load(key); ok {
+		return wallet.(*address.Address), nil
+	}
+
+	ownerAddr, err := address.ParseAddr(owner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner %q: %w", owner, err)
+	}
+	masterAddr, err := address.ParseAddr(jettonMaster)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jetton master %q: %w", jettonMaster, err)
+	}
+
+	wallet, err := jetton.NewJettonMasterClient(s.traces.ton_client, masterAddr).GetJettonWallet(ctx, ownerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jetton wallet of %s: %w", owner, err)
+	}
+
+	s.wallets.Store(key, wallet.Address())
+	return wallet.Address(), nil
+}
+
+func decodeIncomingTransfer(transaction *tlb.Transaction) (IncomingTransfer, bool) {
+	if transaction.IO.In == nil || transaction.IO.In.MsgType != tlb.MsgTypeInternal {
+		return IncomingTransfer{}, false
+	}
+
+	body := transaction.IO.In.AsInternal().Payload()
+	if body == nil {
+		return IncomingTransfer{}, false
+	}
+
+	slice := body.BeginParse()
+	op, err := slice.LoadUInt(32)
+	if err != nil || op != opInternalTransfer {
+		return IncomingTransfer{}, false
+	}
+	queryID, err := slice.LoadUInt(64)
+	if err != nil {
+		return IncomingTransfer{}, false
+	}
+	amount, err := slice.LoadBigCoins()
+	if err != nil {
+		return IncomingTransfer{}, false
+	}
+	sender, err := slice.LoadAddr()
+	if err != nil {
+		return IncomingTransfer{}, false
+	}
+
+	transfer := IncomingTransfer{
+		TxHash:  hex.EncodeToString(transaction.Hash),
+		Lt:      transaction.LT,
+		Utime:   int64(transaction.Now),
+		QueryID: queryID,
+		Amount:  amount,
+		Sender:  sender.StringRaw(),
+		Success: true,
+	}
+	if description, ok := transaction.Description.(tlb.TransactionDescriptionOrdinary); ok {
+		transfer.Success = !description.Aborted
+		if phase, ok := description.ComputePhase.Phase.(tlb.ComputePhaseVM); ok && !phase.Success {
+			transfer.Success = false
+		}
+	}
+
+	return transfer, true
+}
@@ -0,0 +1,45 @@
+package validation_worker
+
+import (
+	"context"
+	"time"
+
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+)
+
+// PaymentIntentWatcher looks for the transfers paying open payment intents.
+type PaymentIntentWatcher struct {
+	logger   *logger.Logger
+	interval time.Duration
+
+	intent_service validation_service.IPaymentIntentService
+}
+
+func NewPaymentIntentWatcher(logger *logger.Logger, interval time.Duration, intent_service validation_service.IPaymentIntentService) *PaymentIntentWatcher {
+	return &PaymentIntentWatcher{logger: logger, interval: interval, intent_service: intent_service}
+}
+
+func (w *PaymentIntentWatcher) Run(ctx context.Context) {
+	w.logger.Infof("payment intent watcher started, interval %s", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		settled, err := w.intent_service.MatchPaymentIntents(ctx)
+		if err != nil {
+			w.logger.Errorf("failed to match payment intents: %v", err)
+		}
+		if settled > 0 {
+			w.logger.Infof("%d payment intents settled", settled)
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Infof("payment intent watcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
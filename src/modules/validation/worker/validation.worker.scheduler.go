@@ -0,0 +1,134 @@
+package validation_worker
+
+import (
+	"context"
+	"time"
+
+	validation_adapters "github.com/root9464/Go_GamlerDefi/src/modules/validation/adapters"
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	recheckLease   = 2 * time.Minute
+	recheckTimeout = time.Minute
+	// running transactions untouched for longer were left by an interrupted request
+	staleRunningAfter = 5 * time.Minute
+)
+
+// ScheduleOptions configures how often waiting transactions are re-checked.
+// The delay between checks doubles from Interval up to MaxBackoff, after
+// Deadline since creation the transaction is finalized.
+type ScheduleOptions struct {
+	Interval   time.Duration
+	MaxBackoff time.Duration
+	Deadline   time.Duration
+}
+
+// ValidationScheduler checks queued, waiting and stale running transactions
+// until they are finalized. Every instance leases the transactions it checks, so
+// several instances can run side by side.
+type ValidationScheduler struct {
+	logger  *logger.Logger
+	options ScheduleOptions
+	owner   string
+	wake    chan struct{}
+
+	validation_service    validation_service.IValidationService
+	validation_repository validation_repository.IValidationRepository
+}
+
+func NewValidationScheduler(
+	logger *logger.Logger,
+	options ScheduleOptions,
+	validation_service validation_service.IValidationService,
+	validation_repository validation_repository.IValidationRepository,
+) *ValidationScheduler {
+	return &ValidationScheduler{
+		logger:                logger,
+		options:               options,
+		owner:                 bson.NewObjectID().Hex(),
+		wake:                  make(chan struct{}, 1),
+		validation_service:    validation_service,
+		validation_repository: validation_repository,
+	}
+}
+
+func (w *ValidationScheduler) Run(ctx context.Context) {
+	w.logger.Infof("validation scheduler %s started, interval %s, deadline %s", w.owner, w.options.Interval, w.options.Deadline)
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Infof("validation scheduler stopped")
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// Wake makes the scheduler poll right away instead of on the next tick.
+func (w *ValidationScheduler) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *ValidationScheduler) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		transaction, err := w.validation_repository.ClaimTransactionObserver(
+			ctx, w.owner, now.Unix(), now.Add(-staleRunningAfter).Unix(), now.Add(recheckLease).Unix(),
+		)
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			w.logger.Errorf("failed to claim transaction: %v", err)
+			return
+		}
+
+		w.recheck(ctx, transaction)
+	}
+}
+
+func (w *ValidationScheduler) recheck(ctx context.Context, transaction validation_model.WorkerTransaction) {
+	final := time.Since(time.Unix(transaction.CreatedAt, 0)) >= w.options.Deadline
+
+	checkCtx, cancel := context.WithTimeout(ctx, recheckTimeout)
+	updated, err := w.validation_service.RecheckTransaction(checkCtx, validation_adapters.TransactionModelToDTOPoint(transaction), final)
+	cancel()
+
+	if err != nil {
+		w.logger.Warnf("transaction %s recheck failed, attempt %d: %v", transaction.ID.Hex(), transaction.Attempts+1, err)
+	} else if updated.Status != validation_dto.WorkerStatusWaiting {
+		w.logger.Infof("transaction %s finalized by scheduler, status: %s", transaction.ID.Hex(), updated.Status)
+	}
+
+	nextCheckAt := time.Now().Add(Backoff(w.options.Interval, w.options.MaxBackoff, transaction.Attempts))
+	if err := w.validation_repository.ReleaseTransactionObserver(ctx, transaction.ID, w.owner, nextCheckAt.Unix()); err != nil {
+		w.logger.Errorf("failed to release transaction %s: %v", transaction.ID.Hex(), err)
+	}
+}
+
+// Backoff returns the delay before the check following `attempts` previous
+// ones: interval doubled per attempt and capped at maxBackoff.
+func Backoff(interval time.Duration, maxBackoff time.Duration, attempts int) time.Duration {
+	delay := interval
+	for i := 0; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
@@ -0,0 +1,54 @@
+package admin_middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+)
+
+const (
+	userLocal  = "user"
+	adminLocal = "admin"
+)
+
+// Authenticated accepts any valid access token and stores its payload in the
+// request context. Tokens with the admin role accepted by AdminOnly are marked
+// so handlers can let them act on behalf of any user.
+func (m *Middleware) Authenticated() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		tokenString := ctx.Get("Authorization")
+		if tokenString == "" {
+			m.logger.Warn("Missing Authorization header")
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Missing Authorization header",
+			})
+		}
+
+		if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
+			tokenString = tokenString[7:]
+		}
+
+		payload, err := m.jwtHelpers.ParseJwtWithKeys(tokenString, m.keys)
+		if err != nil {
+			m.logger.Warnf("Invalid JWT token: %s", err.Error())
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid JWT token",
+			})
+		}
+
+		ctx.Locals(userLocal, payload)
+		ctx.Locals(adminLocal, payload.HasRole(jwt_dto.RoleAdmin))
+		return ctx.Next()
+	}
+}
+
+// UserFromContext returns the payload stored by Authenticated or AdminOnly.
+func UserFromContext(ctx *fiber.Ctx) (*jwt_dto.UserJwtPayload, bool) {
+	payload, ok := ctx.Locals(userLocal).(*jwt_dto.UserJwtPayload)
+	return payload, ok && payload != nil
+}
+
+// IsAdmin reports whether the request was authenticated with an admin token.
+func IsAdmin(ctx *fiber.Ctx) bool {
+	admin, _ := ctx.Locals(adminLocal).(bool)
+	return admin
+}
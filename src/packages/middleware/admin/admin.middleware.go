@@ -1,31 +1,30 @@
 package admin_middleware
 
 import (
-	"crypto/ecdsa"
-	"crypto/x509"
-	"encoding/base64"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
 	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 )
 
 type Middleware struct {
 	logger     *logger.Logger
 	jwtHelpers jwt_helpers.IJwtHelper
-	publicKey  string
+	keys       *jwt_keys.KeyRing
 }
 
 func NewMiddleware(
 	logger *logger.Logger,
 	jwtHelpers jwt_helpers.IJwtHelper,
-	publicKey string,
+	keys *jwt_keys.KeyRing,
 ) *Middleware {
 	return &Middleware{
 		logger:     logger,
 		jwtHelpers: jwtHelpers,
-		publicKey:  publicKey,
+		keys:       keys,
 	}
 }
 
@@ -38,78 +37,32 @@ func (m *Middleware) AdminOnly() fiber.Handler {
 				"error": "Missing Authorization header",
 			})
 		}
-		m.logger.Infof("tokenString: %s", tokenString)
 
 		if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
 			tokenString = tokenString[7:]
 		}
 
-		publicKeyBytes, err := base64.StdEncoding.DecodeString(m.publicKey)
-		if err != nil {
-			m.logger.Warnf("Failed to decode base64 public key: %s", err.Error())
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Invalid public key",
-			})
-		}
-		m.logger.Infof("publicKey: %s", m.publicKey)
-
-		parsedKey, err := x509.ParsePKIXPublicKey(publicKeyBytes)
-		if err != nil {
-			m.logger.Warnf("Failed to parse public key: %s", err.Error())
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Invalid public key",
-			})
-		}
-		m.logger.Infof("parsedKey: %v", parsedKey)
-		ecdsaPublicKey, ok := parsedKey.(*ecdsa.PublicKey)
-		if !ok {
-			m.logger.Warn("Public key is not ECDSA")
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Invalid public key type",
-			})
-		}
-
-		m.logger.Infof("ecdsaPublicKey: %v", ecdsaPublicKey)
-		payload, err := m.jwtHelpers.ParseJwt(tokenString, ecdsaPublicKey)
+		payload, err := m.jwtHelpers.ParseJwtWithKeys(tokenString, m.keys)
 		if err != nil {
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				m.logger.Warn("Token has expired")
+				return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Token has expired",
+				})
+			}
 			m.logger.Warnf("Invalid JWT token: %s", err.Error())
 			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid JWT token",
 			})
 		}
-		m.logger.Infof("payload: %v", payload)
-		token, err := m.jwtHelpers.VerifyJwt(tokenString, ecdsaPublicKey)
-		if err != nil {
-			m.logger.Warnf("Token verification failed: %s", err.Error())
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid JWT token",
-			})
-		}
-		m.logger.Infof("token: %v", token)
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["role"] != "admin" {
+		if !payload.HasRole(jwt_dto.RoleAdmin) {
 			m.logger.Warn("Token does not have admin role")
 			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Admin access required",
 			})
 		}
-		m.logger.Infof("claims: %v", claims)
-		isValid, err := m.jwtHelpers.CheckTokenExpiration(tokenString, ecdsaPublicKey)
-		if err != nil {
-			m.logger.Warnf("Token expiration check failed: %s", err.Error())
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired token",
-			})
-		}
-
-		if !isValid {
-			m.logger.Warn("Token has expired")
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Token has expired",
-			})
-		}
-		m.logger.Infof("isValid: %v", isValid)
-		ctx.Locals("user", payload)
+		ctx.Locals(userLocal, payload)
+		ctx.Locals(adminLocal, true)
 		return ctx.Next()
 	}
 }
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+)
+
+// RequireScopes lets the request through when its token carries every scope.
+// It reads the payload stored by Authenticated or AdminOnly, so it has to be
+// registered after one of them.
+func RequireScopes(scopes ...jwt_dto.Scope) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user, ok := admin_middleware.UserFromContext(ctx)
+		if !ok {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		if !user.HasScopes(scopes...) {
+			missing := []string{}
+			for _, scope := range scopes {
+				if !user.HasScopes(scope) {
+					missing = append(missing, string(scope))
+				}
+			}
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Missing scopes: " + strings.Join(missing, ", "),
+			})
+		}
+
+		return ctx.Next()
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -26,16 +27,37 @@ func parseError(body []byte, status int) string {
 	return fmt.Sprintf("%s (status %d)", body, status)
 }
 
+// APIError is returned when the remote side answered with a 4xx/5xx status.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s", e.Message)
+}
+
 func Get[T any](url string) (T, error) {
+	return GetWithTimeout[T](url, 0)
+}
+
+// GetWithTimeout works like Get but aborts the request after `timeout`.
+// A zero timeout waits for the response indefinitely.
+func GetWithTimeout[T any](url string, timeout time.Duration) (T, error) {
 	var result T
 
-	status, body, errs := fiber.Get(url).Bytes()
+	agent := fiber.Get(url)
+	if timeout > 0 {
+		agent.Timeout(timeout)
+	}
+
+	status, body, errs := agent.Bytes()
 	if len(errs) > 0 {
 		return result, fmt.Errorf("request failed: %v", errs)
 	}
 
 	if status >= 400 {
-		return result, fmt.Errorf("API error: %s", parseError(body, status))
+		return result, &APIError{Status: status, Message: parseError(body, status)}
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -13,6 +13,7 @@ import (
 	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
 	jwt_functions "github.com/root9464/Go_GamlerDefi/src/modules/jwt/functions"
 	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,7 @@ type JwtFuncsTestSuite struct {
 	validator  *validator.Validate
 	privateKey *ecdsa.PrivateKey
 	publicKey  *ecdsa.PublicKey
+	keys       *jwt_keys.KeyRing
 	helpers    jwt_helpers.IJwtHelper
 	jwtFuncs   *jwt_functions.JwtFuncs
 }
@@ -51,8 +53,11 @@ func (s *JwtFuncsTestSuite) SetupSuite() {
 	require.NoError(s.T(), err, "Failed to parse public key")
 	s.publicKey = publicKey.(*ecdsa.PublicKey)
 
+	s.keys, err = jwt_keys.NewKeyRing(jwt_keys.Key{PrivateKey: s.privateKey, PublicKey: s.publicKey})
+	require.NoError(s.T(), err, "Failed to build key ring")
+
 	s.helpers = jwt_helpers.NewJwtHelper(s.logger, s.validator)
-	s.jwtFuncs = jwt_functions.NewJwtFuncs(s.logger, s.validator, s.privateKey, s.publicKey, s.helpers).(*jwt_functions.JwtFuncs)
+	s.jwtFuncs = jwt_functions.NewJwtFuncs(s.logger, s.validator, s.keys, s.helpers).(*jwt_functions.JwtFuncs)
 }
 
 func (s *JwtFuncsTestSuite) TestGenerateKeyPair_Success() {
@@ -130,7 +135,7 @@ func (s *JwtFuncsTestSuite) TestGenerateKeyPair_InvalidUserData() {
 }
 
 func (s *JwtFuncsTestSuite) TestGenerateKeyPair_NilPrivateKey() {
-	jwtFuncs := jwt_functions.NewJwtFuncs(s.logger, s.validator, nil, s.publicKey, s.helpers).(*jwt_functions.JwtFuncs)
+	jwtFuncs := jwt_functions.NewJwtFuncs(s.logger, s.validator, nil, s.helpers).(*jwt_functions.JwtFuncs)
 	userData := jwt_dto.UserData{
 		ID: 123,
 	}
@@ -143,7 +148,7 @@ func (s *JwtFuncsTestSuite) TestGenerateKeyPair_NilPrivateKey() {
 }
 
 func (s *JwtFuncsTestSuite) TestGenerateKeyPair_NilHelpers() {
-	jwtFuncs := jwt_functions.NewJwtFuncs(s.logger, s.validator, s.privateKey, s.publicKey, nil).(*jwt_functions.JwtFuncs)
+	jwtFuncs := jwt_functions.NewJwtFuncs(s.logger, s.validator, s.keys, nil).(*jwt_functions.JwtFuncs)
 	userData := jwt_dto.UserData{
 		ID: 123,
 	}
@@ -162,7 +167,7 @@ func (s *JwtFuncsTestSuite) TestRefreshAccessToken_Success() {
 	_, refreshToken, err := s.jwtFuncs.GenerateKeyPair(userData)
 	require.NoError(s.T(), err, "Failed to generate refresh token")
 
-	accessToken, err := s.jwtFuncs.RefreshAccessToken(*refreshToken, s.publicKey, s.privateKey)
+	accessToken, err := s.jwtFuncs.RefreshAccessToken(*refreshToken, s.keys)
 	assert.NoError(s.T(), err, "Failed to refresh access token")
 	assert.NotNil(s.T(), accessToken, "Access token should not be nil")
 
@@ -179,7 +184,7 @@ func (s *JwtFuncsTestSuite) TestRefreshAccessToken_Success() {
 
 func (s *JwtFuncsTestSuite) TestRefreshAccessToken_InvalidToken() {
 	invalidToken := "invalid.token.string"
-	accessToken, err := s.jwtFuncs.RefreshAccessToken(invalidToken, s.publicKey, s.privateKey)
+	accessToken, err := s.jwtFuncs.RefreshAccessToken(invalidToken, s.keys)
 	assert.Error(s.T(), err, "Expected error for invalid refresh token")
 	assert.Contains(s.T(), err.Error(), "invalid refresh token", "Error message should indicate invalid token")
 	assert.Nil(s.T(), accessToken, "Access token should be nil")
@@ -195,12 +200,13 @@ func (s *JwtFuncsTestSuite) TestRefreshAccessToken_ExpiredToken() {
 		"iat":       time.Now().Add(-25 * time.Hour).Unix(),
 		"exp":       time.Now().Add(-24 * time.Hour).Unix(),
 		"user_hash": "somehash",
+		"typ":       "refresh",
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, expiredClaims)
 	expiredRefreshToken, err := token.SignedString(s.privateKey)
 	require.NoError(s.T(), err, "Failed to create expired refresh token")
 
-	accessToken, err := s.jwtFuncs.RefreshAccessToken(expiredRefreshToken, s.publicKey, s.privateKey)
+	accessToken, err := s.jwtFuncs.RefreshAccessToken(expiredRefreshToken, s.keys)
 	assert.Error(s.T(), err, "Expected error for expired refresh token")
 	assert.Contains(s.T(), err.Error(), "refresh token has expired", "Error message should indicate expired token")
 	assert.Nil(s.T(), accessToken, "Access token should be nil")
@@ -216,12 +222,25 @@ func (s *JwtFuncsTestSuite) TestRefreshAccessToken_MissingClaims() {
 	invalidRefreshToken, err := token.SignedString(s.privateKey)
 	require.NoError(s.T(), err, "Failed to create invalid refresh token")
 
-	accessToken, err := s.jwtFuncs.RefreshAccessToken(invalidRefreshToken, s.publicKey, s.privateKey)
+	accessToken, err := s.jwtFuncs.RefreshAccessToken(invalidRefreshToken, s.keys)
 	assert.Error(s.T(), err, "Expected error for missing claims")
 	assert.Contains(s.T(), err.Error(), "refresh token missing user ID", "Error message should indicate missing user ID")
 	assert.Nil(s.T(), accessToken, "Access token should be nil")
 }
 
+func (s *JwtFuncsTestSuite) TestRefreshAccessToken_AccessToken() {
+	userData := jwt_dto.UserData{
+		ID: 123,
+	}
+	accessToken, _, err := s.jwtFuncs.GenerateKeyPair(userData)
+	require.NoError(s.T(), err, "Failed to generate access token")
+
+	refreshedToken, err := s.jwtFuncs.RefreshAccessToken(*accessToken, s.keys)
+	assert.Error(s.T(), err, "Expected error for access token")
+	assert.Contains(s.T(), err.Error(), "not a refresh token", "Error message should indicate wrong token type")
+	assert.Nil(s.T(), refreshedToken, "Access token should be nil")
+}
+
 func TestJwtFuncsTestSuite(t *testing.T) {
 	suite.Run(t, new(JwtFuncsTestSuite))
 }
@@ -0,0 +1,158 @@
+package jwt_keys_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	privateKeyStr = "MHcCAQEEIPemCJai8w+gAm+3N30cyqvIuZqmudIulBf6soXQD+iooAoGCCqGSM49AwEHoUQDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+	publicKeyStr  = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+)
+
+type KeyRingTestSuite struct {
+	suite.Suite
+	helpers jwt_helpers.IJwtHelper
+}
+
+func (s *KeyRingTestSuite) SetupSuite() {
+	s.helpers = jwt_helpers.NewJwtHelper(logger.GetLogger(), validator.New())
+}
+
+func (s *KeyRingTestSuite) newKey(id string) jwt_keys.Key {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(s.T(), err)
+	return jwt_keys.Key{ID: id, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+}
+
+func (s *KeyRingTestSuite) claims() *jwt_dto.UserClaims {
+	return &jwt_dto.UserClaims{
+		Iss:  "GamlerDefi::admin",
+		Sub:  42,
+		Iat:  time.Now().Unix(),
+		Exp:  time.Now().Add(time.Hour).Unix(),
+		Hash: "hash",
+		Typ:  jwt_dto.TokenAccess,
+	}
+}
+
+func (s *KeyRingTestSuite) sign(key jwt_keys.Key) string {
+	token, err := s.helpers.SignJwt(s.claims(), &key)
+	require.NoError(s.T(), err)
+	return *token
+}
+
+func (s *KeyRingTestSuite) TestRotation() {
+	old := s.newKey("2024-01")
+	next := s.newKey("2024-02")
+	foreign := s.newKey("2024-01")
+
+	before, err := jwt_keys.NewKeyRing(old)
+	require.NoError(s.T(), err)
+	oldToken := s.sign(*before.Active())
+	legacy, err := s.helpers.CreateJwt(s.claims(), old.PrivateKey)
+	require.NoError(s.T(), err)
+
+	after, err := jwt_keys.NewKeyRing(next, jwt_keys.Key{ID: old.ID, PublicKey: old.PublicKey})
+	require.NoError(s.T(), err)
+	s.Equal(next.ID, after.Active().ID, "new tokens should be signed with the new key")
+
+	cases := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "token of the active key", token: s.sign(*after.Active()), valid: true},
+		{name: "token of the retired key", token: oldToken, valid: true},
+		{name: "token without kid", token: *legacy, valid: true},
+		{name: "unknown kid", token: s.sign(s.newKey("2023-12")), valid: false},
+		{name: "known kid signed by another key", token: s.sign(foreign), valid: false},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			payload, err := s.helpers.ParseJwtWithKeys(tc.token, after)
+			if !tc.valid {
+				s.Error(err)
+				return
+			}
+			require.NoError(s.T(), err)
+			s.Equal(int64(42), payload.Sub)
+		})
+	}
+
+	_, err = s.helpers.ParseJwtWithKeys(s.sign(s.newKey("2023-12")), after)
+	s.ErrorIs(err, jwt_keys.ErrUnknownKey)
+}
+
+func (s *KeyRingTestSuite) TestJWKS() {
+	active := s.newKey("")
+	retired := s.newKey("2024-01")
+
+	ring, err := jwt_keys.NewKeyRing(active, jwt_keys.Key{ID: retired.ID, PublicKey: retired.PublicKey})
+	require.NoError(s.T(), err)
+
+	set := ring.JWKS()
+	require.Len(s.T(), set.Keys, 2)
+	s.Equal(jwt_keys.Thumbprint(active.PublicKey), set.Keys[0].Kid, "keys without id should be named by their thumbprint")
+	s.Equal(retired.ID, set.Keys[1].Kid)
+
+	for i, key := range []jwt_keys.Key{active, retired} {
+		jwk := set.Keys[i]
+		s.Equal("EC", jwk.Kty)
+		s.Equal("P-256", jwk.Crv)
+		s.Equal("ES256", jwk.Alg)
+		s.Equal("sig", jwk.Use)
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		require.NoError(s.T(), err)
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		require.NoError(s.T(), err)
+		published := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		s.True(key.PublicKey.Equal(published), "published key should match")
+	}
+}
+
+func (s *KeyRingTestSuite) TestLoad() {
+	retired := s.newKey("")
+	retiredBytes, err := x509.MarshalPKIXPublicKey(retired.PublicKey)
+	require.NoError(s.T(), err)
+	retiredStr := base64.StdEncoding.EncodeToString(retiredBytes)
+
+	ring, err := jwt_keys.Load(privateKeyStr, publicKeyStr, "2024-02", []string{"2024-01:" + retiredStr, " "})
+	require.NoError(s.T(), err)
+	s.Equal("2024-02", ring.Active().ID)
+	s.Len(ring.JWKS().Keys, 2)
+
+	ring, err = jwt_keys.Load(privateKeyStr, publicKeyStr, "", []string{retiredStr})
+	require.NoError(s.T(), err)
+	s.Equal(jwt_keys.Thumbprint(retired.PublicKey), ring.JWKS().Keys[1].Kid)
+
+	_, err = jwt_keys.Load(privateKeyStr, retiredStr, "", nil)
+	s.Error(err, "public key of another private key should be rejected")
+
+	_, err = jwt_keys.Load(privateKeyStr, publicKeyStr, "2024-01", []string{"2024-01:" + retiredStr})
+	s.Error(err, "duplicate kid should be rejected")
+
+	_, err = jwt_keys.Load(privateKeyStr, publicKeyStr, "", []string{"2024-01:not-a-key"})
+	s.Error(err, "invalid verify key should be rejected")
+}
+
+func TestKeyRingTestSuite(t *testing.T) {
+	suite.Run(t, new(KeyRingTestSuite))
+}
@@ -0,0 +1,148 @@
+package jwt_service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_functions "github.com/root9464/Go_GamlerDefi/src/modules/jwt/functions"
+	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	jwt_repository "github.com/root9464/Go_GamlerDefi/src/modules/jwt/repository"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	privateKeyStr = "MHcCAQEEIPemCJai8w+gAm+3N30cyqvIuZqmudIulBf6soXQD+iooAoGCCqGSM49AwEHoUQDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+	publicKeyStr  = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+)
+
+type JwtServiceTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	publicKey *ecdsa.PublicKey
+	service   jwt_service.IJwtService
+	helpers   jwt_helpers.IJwtHelper
+}
+
+func (s *JwtServiceTestSuite) SetupTest() {
+	log := logger.GetLogger()
+	validator := validator.New()
+
+	keys, err := jwt_keys.Load(privateKeyStr, publicKeyStr, "", nil)
+	require.NoError(s.T(), err, "Failed to load keys")
+
+	s.ctx = context.Background()
+	s.publicKey = keys.Active().PublicKey
+	s.helpers = jwt_helpers.NewJwtHelper(log, validator)
+	funcs := jwt_functions.NewJwtFuncs(log, validator, keys, s.helpers)
+	s.service = jwt_service.NewJwtService(log, keys, funcs, jwt_repository.NewMemoryRepository())
+}
+
+func (s *JwtServiceTestSuite) issue() jwt_dto.TokenPairResponse {
+	tokens, err := s.service.IssueTokens(s.ctx, jwt_dto.UserData{ID: 5187512201})
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), tokens.AccessToken)
+	require.NotEmpty(s.T(), tokens.RefreshToken)
+	return tokens
+}
+
+func (s *JwtServiceTestSuite) TestIssueTokens_InvalidUserData() {
+	_, err := s.service.IssueTokens(s.ctx, jwt_dto.UserData{ID: 0})
+	s.Equal(400, errors.GetCode(err))
+}
+
+func (s *JwtServiceTestSuite) TestRefreshTokens_Rotates() {
+	tokens := s.issue()
+
+	rotated, err := s.service.RefreshTokens(s.ctx, tokens.RefreshToken)
+	require.NoError(s.T(), err)
+	s.NotEqual(tokens.RefreshToken, rotated.RefreshToken, "refresh token should be rotated")
+
+	payload, err := s.helpers.ParseJwt(rotated.AccessToken, s.publicKey)
+	require.NoError(s.T(), err)
+	s.Equal(int64(5187512201), payload.Sub)
+
+	_, err = s.service.RefreshTokens(s.ctx, rotated.RefreshToken)
+	s.NoError(err, "rotated token should be usable once")
+}
+
+func (s *JwtServiceTestSuite) TestRefreshTokens_KeepsRoles() {
+	tokens, err := s.service.IssueTokens(s.ctx, jwt_dto.UserData{
+		ID:     5187512201,
+		Roles:  []jwt_dto.Role{jwt_dto.RoleHost},
+		Scopes: []jwt_dto.Scope{jwt_dto.ScopeOrdersRead},
+	})
+	require.NoError(s.T(), err)
+
+	rotated, err := s.service.RefreshTokens(s.ctx, tokens.RefreshToken)
+	require.NoError(s.T(), err)
+	rotated, err = s.service.RefreshTokens(s.ctx, rotated.RefreshToken)
+	require.NoError(s.T(), err)
+
+	payload, err := s.helpers.ParseJwt(rotated.AccessToken, s.publicKey)
+	require.NoError(s.T(), err)
+	s.Equal([]jwt_dto.Role{jwt_dto.RoleHost}, payload.Roles)
+	s.ElementsMatch([]jwt_dto.Scope{jwt_dto.ScopeConferenceHost, jwt_dto.ScopeOrdersRead}, payload.Scopes)
+	s.False(payload.HasScopes(jwt_dto.ScopeOrdersDelete))
+}
+
+func (s *JwtServiceTestSuite) TestRefreshTokens_ReuseRevokesFamily() {
+	tokens := s.issue()
+
+	rotated, err := s.service.RefreshTokens(s.ctx, tokens.RefreshToken)
+	require.NoError(s.T(), err)
+
+	_, err = s.service.RefreshTokens(s.ctx, tokens.RefreshToken)
+	s.Equal(401, errors.GetCode(err))
+	s.Contains(err.Error(), "reuse")
+
+	_, err = s.service.RefreshTokens(s.ctx, rotated.RefreshToken)
+	s.Equal(401, errors.GetCode(err), "tokens of a reused family should be revoked")
+
+	other := s.issue()
+	_, err = s.service.RefreshTokens(s.ctx, other.RefreshToken)
+	s.NoError(err, "other sessions should stay valid")
+}
+
+func (s *JwtServiceTestSuite) TestRefreshTokens_Unknown() {
+	cases := []struct {
+		name  string
+		token string
+	}{
+		{name: "garbage", token: "not-a-token"},
+		{name: "access token", token: s.issue().AccessToken},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			_, err := s.service.RefreshTokens(s.ctx, tc.token)
+			s.Equal(401, errors.GetCode(err))
+		})
+	}
+}
+
+func (s *JwtServiceTestSuite) TestRevokeTokens() {
+	tokens := s.issue()
+	rotated, err := s.service.RefreshTokens(s.ctx, tokens.RefreshToken)
+	require.NoError(s.T(), err)
+
+	s.NoError(s.service.RevokeTokens(s.ctx, rotated.RefreshToken))
+	s.NoError(s.service.RevokeTokens(s.ctx, rotated.RefreshToken), "logout should be idempotent")
+
+	_, err = s.service.RefreshTokens(s.ctx, rotated.RefreshToken)
+	s.Equal(401, errors.GetCode(err))
+
+	s.Equal(401, errors.GetCode(s.service.RevokeTokens(s.ctx, "not-a-token")))
+}
+
+func TestJwtServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(JwtServiceTestSuite))
+}
@@ -0,0 +1,177 @@
+package admin_middleware_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/root9464/Go_GamlerDefi/src/packages/middleware"
+	admin_middleware "github.com/root9464/Go_GamlerDefi/src/packages/middleware/admin"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	privateKeyStr = "MHcCAQEEIPemCJai8w+gAm+3N30cyqvIuZqmudIulBf6soXQD+iooAoGCCqGSM49AwEHoUQDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+	publicKeyStr  = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+)
+
+type AuthMiddlewareTestSuite struct {
+	suite.Suite
+	logger     *logger.Logger
+	privateKey *ecdsa.PrivateKey
+	helpers    jwt_helpers.IJwtHelper
+	app        *fiber.App
+}
+
+type callerResponse struct {
+	Sub   int64 `json:"sub"`
+	Admin bool  `json:"admin"`
+}
+
+func (s *AuthMiddlewareTestSuite) SetupSuite() {
+	s.logger = logger.GetLogger()
+
+	privateKeyBytes, err := base64.StdEncoding.DecodeString(privateKeyStr)
+	require.NoError(s.T(), err, "Failed to decode private key")
+	s.privateKey, err = x509.ParseECPrivateKey(privateKeyBytes)
+	require.NoError(s.T(), err, "Failed to parse private key")
+
+	s.helpers = jwt_helpers.NewJwtHelper(s.logger, validator.New())
+	keys, err := jwt_keys.Load(privateKeyStr, publicKeyStr, "", nil)
+	require.NoError(s.T(), err, "Failed to load keys")
+	auth := admin_middleware.NewMiddleware(s.logger, s.helpers, keys)
+
+	caller := func(ctx *fiber.Ctx) error {
+		user, ok := admin_middleware.UserFromContext(ctx)
+		if !ok {
+			return ctx.SendStatus(fiber.StatusInternalServerError)
+		}
+		return ctx.JSON(callerResponse{Sub: user.Sub, Admin: admin_middleware.IsAdmin(ctx)})
+	}
+
+	s.app = fiber.New()
+	s.app.Get("/me", auth.Authenticated(), caller)
+	s.app.Get("/admin", auth.AdminOnly(), caller)
+	s.app.Get("/host", auth.Authenticated(), middleware.RequireScopes(jwt_dto.ScopeConferenceHost), caller)
+	s.app.Get("/unauthenticated", middleware.RequireScopes(jwt_dto.ScopeOrdersRead), caller)
+}
+
+func (s *AuthMiddlewareTestSuite) token(claims jwt.MapClaims) string {
+	token, err := s.helpers.CreateJwt(claims, s.privateKey)
+	require.NoError(s.T(), err, "Failed to sign token")
+	return *token
+}
+
+func (s *AuthMiddlewareTestSuite) claims(sub int64, role string, ttl time.Duration) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":       "GamlerDefi::admin",
+		"sub":       sub,
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(ttl).Unix(),
+		"user_hash": "hash",
+		"typ":       "access",
+	}
+	if role != "" {
+		claims["role"] = role
+	}
+	return claims
+}
+
+func (s *AuthMiddlewareTestSuite) request(path string, authorization string) (int, callerResponse) {
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := s.app.Test(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	var body callerResponse
+	if resp.StatusCode == fiber.StatusOK {
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp.StatusCode, body
+}
+
+func (s *AuthMiddlewareTestSuite) TestAuthenticated() {
+	withoutHash := s.claims(42, "", time.Hour)
+	delete(withoutHash, "user_hash")
+	refresh := s.claims(42, "", time.Hour)
+	refresh["typ"] = "refresh"
+
+	cases := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		caller        callerResponse
+	}{
+		{name: "missing header", path: "/me", status: fiber.StatusUnauthorized},
+		{name: "malformed token", path: "/me", authorization: "Bearer not-a-token", status: fiber.StatusUnauthorized},
+		{name: "expired token", path: "/me", authorization: "Bearer " + s.token(s.claims(42, "", -time.Minute)), status: fiber.StatusUnauthorized},
+		{name: "missing claims", path: "/me", authorization: "Bearer " + s.token(withoutHash), status: fiber.StatusUnauthorized},
+		{name: "refresh token", path: "/me", authorization: "Bearer " + s.token(refresh), status: fiber.StatusUnauthorized},
+		{name: "admin only rejects refresh token", path: "/admin", authorization: "Bearer " + s.token(refresh), status: fiber.StatusUnauthorized},
+		{name: "user token", path: "/me", authorization: "Bearer " + s.token(s.claims(42, "", time.Hour)), status: fiber.StatusOK, caller: callerResponse{Sub: 42}},
+		{name: "admin token", path: "/me", authorization: "Bearer " + s.token(s.claims(7, "admin", time.Hour)), status: fiber.StatusOK, caller: callerResponse{Sub: 7, Admin: true}},
+		{name: "admin only rejects user", path: "/admin", authorization: "Bearer " + s.token(s.claims(42, "", time.Hour)), status: fiber.StatusForbidden},
+		{name: "admin only accepts admin", path: "/admin", authorization: "Bearer " + s.token(s.claims(7, "admin", time.Hour)), status: fiber.StatusOK, caller: callerResponse{Sub: 7, Admin: true}},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			status, caller := s.request(tc.path, tc.authorization)
+			s.Equal(tc.status, status)
+			s.Equal(tc.caller, caller)
+		})
+	}
+}
+
+func (s *AuthMiddlewareTestSuite) TestRequireScopes() {
+	withRoles := func(roles ...string) jwt.MapClaims {
+		claims := s.claims(42, "", time.Hour)
+		claims["roles"] = roles
+		return claims
+	}
+	withScope := withRoles("user")
+	withScope["scopes"] = []string{"conference:host"}
+
+	cases := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{name: "no payload", path: "/unauthenticated", status: fiber.StatusUnauthorized},
+		{name: "legacy user token", path: "/host", authorization: "Bearer " + s.token(s.claims(42, "", time.Hour)), status: fiber.StatusForbidden},
+		{name: "user role", path: "/host", authorization: "Bearer " + s.token(withRoles("user")), status: fiber.StatusForbidden},
+		{name: "host role", path: "/host", authorization: "Bearer " + s.token(withRoles("user", "host")), status: fiber.StatusOK},
+		{name: "extra scope", path: "/host", authorization: "Bearer " + s.token(withScope), status: fiber.StatusOK},
+		{name: "legacy admin token", path: "/host", authorization: "Bearer " + s.token(s.claims(7, "admin", time.Hour)), status: fiber.StatusOK},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			status, _ := s.request(tc.path, tc.authorization)
+			s.Equal(tc.status, status)
+		})
+	}
+}
+
+func TestAuthMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthMiddlewareTestSuite))
+}
@@ -0,0 +1,120 @@
+package directory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingDirectory records how many times each user was requested.
+type countingDirectory struct {
+	directory referral_directory.IReferrerDirectory
+	calls     map[int]int
+	failures  map[int]error
+}
+
+func (d *countingDirectory) GetReferrer(ctx context.Context, userID int) (*referral_dto.ReferrerResponse, error) {
+	d.calls[userID]++
+	if err, ok := d.failures[userID]; ok {
+		return nil, err
+	}
+	return d.directory.GetReferrer(ctx, userID)
+}
+
+type ReferrerCacheTestSuite struct {
+	suite.Suite
+	directory *countingDirectory
+}
+
+func (s *ReferrerCacheTestSuite) SetupTest() {
+	fixture, err := referral_directory.NewFixtureDirectory(fixture_path)
+	require.NoError(s.T(), err)
+	s.directory = &countingDirectory{directory: fixture, calls: map[int]int{}, failures: map[int]error{}}
+}
+
+func (s *ReferrerCacheTestSuite) TestCachedDirectory_HitsUpstreamOnce() {
+	cached := referral_directory.NewCachedDirectory(s.directory, time.Minute)
+
+	for range 3 {
+		referrer, err := cached.GetReferrer(context.Background(), 2)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), 1, referrer.ReferrerID)
+	}
+	assert.Equal(s.T(), 1, s.directory.calls[2])
+}
+
+func (s *ReferrerCacheTestSuite) TestCachedDirectory_Expires() {
+	cached := referral_directory.NewCachedDirectory(s.directory, 20*time.Millisecond)
+
+	_, err := cached.GetReferrer(context.Background(), 2)
+	require.NoError(s.T(), err)
+	time.Sleep(40 * time.Millisecond)
+	_, err = cached.GetReferrer(context.Background(), 2)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), 2, s.directory.calls[2])
+}
+
+func (s *ReferrerCacheTestSuite) TestCachedDirectory_ErrorsAreNotCached() {
+	cached := referral_directory.NewCachedDirectory(s.directory, time.Minute)
+
+	_, err := cached.GetReferrer(context.Background(), 42)
+	assert.ErrorIs(s.T(), err, referral_directory.ErrReferrerNotFound)
+	_, err = cached.GetReferrer(context.Background(), 42)
+	assert.ErrorIs(s.T(), err, referral_directory.ErrReferrerNotFound)
+
+	assert.Equal(s.T(), 2, s.directory.calls[42])
+}
+
+func (s *ReferrerCacheTestSuite) TestResolveChain_FullChain() {
+	resolver := referral_directory.NewResolver(s.directory)
+
+	chain, err := resolver.ResolveChain(context.Background(), 3, 5)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), chain, 3)
+	assert.Equal(s.T(), []int{3, 2, 1}, []int{chain[0].UserID, chain[1].UserID, chain[2].UserID})
+}
+
+func (s *ReferrerCacheTestSuite) TestResolveChain_LimitedByDepth() {
+	resolver := referral_directory.NewResolver(s.directory)
+
+	chain, err := resolver.ResolveChain(context.Background(), 3, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), chain, 2)
+	assert.Equal(s.T(), 0, s.directory.calls[1])
+}
+
+func (s *ReferrerCacheTestSuite) TestResolveChain_Memoized() {
+	resolver := referral_directory.NewResolver(s.directory)
+
+	_, err := resolver.ResolveChain(context.Background(), 3, 3)
+	require.NoError(s.T(), err)
+	_, err = resolver.ResolveChain(context.Background(), 2, 2)
+	require.NoError(s.T(), err)
+	_, err = resolver.GetReferrer(context.Background(), 1)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), map[int]int{3: 1, 2: 1, 1: 1}, s.directory.calls)
+}
+
+func (s *ReferrerCacheTestSuite) TestResolveChain_PartialOnError() {
+	upstreamErr := errors.New("upstream unavailable")
+	s.directory.failures[1] = upstreamErr
+	resolver := referral_directory.NewResolver(s.directory)
+
+	chain, err := resolver.ResolveChain(context.Background(), 3, 3)
+	assert.ErrorIs(s.T(), err, upstreamErr)
+	require.Len(s.T(), chain, 2)
+	assert.Equal(s.T(), 1, chain[1].ReferrerID)
+}
+
+func TestReferrerCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferrerCacheTestSuite))
+}
@@ -0,0 +1,131 @@
+package directory_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const fixture_path = "fixtures/referrers.json"
+
+type ReferrerDirectoryTestSuite struct {
+	suite.Suite
+	logger *logger.Logger
+}
+
+func (s *ReferrerDirectoryTestSuite) SetupSuite() {
+	s.logger = logger.GetLogger()
+}
+
+func (s *ReferrerDirectoryTestSuite) newServer(handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	s.T().Cleanup(server.Close)
+	return server
+}
+
+func (s *ReferrerDirectoryTestSuite) TestHttpGetReferrer_Success() {
+	server := s.newServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), "/referrer/2", r.URL.Path)
+		json.NewEncoder(w).Encode(referral_dto.ReferrerResponse{UserID: 2, ReferrerID: 1, WalletAddress: "wallet"})
+	})
+
+	directory := referral_directory.NewHttpDirectory(s.logger, server.URL, time.Second, 0)
+	referrer, err := directory.GetReferrer(context.Background(), 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, referrer.ReferrerID)
+	assert.Equal(s.T(), "wallet", referrer.WalletAddress)
+}
+
+func (s *ReferrerDirectoryTestSuite) TestHttpGetReferrer_RetriesServerErrors() {
+	var calls atomic.Int32
+	server := s.newServer(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(referral_dto.ReferrerResponse{UserID: 2})
+	})
+
+	directory := referral_directory.NewHttpDirectory(s.logger, server.URL, time.Second, 2)
+	referrer, err := directory.GetReferrer(context.Background(), 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, referrer.UserID)
+	assert.Equal(s.T(), int32(3), calls.Load())
+}
+
+func (s *ReferrerDirectoryTestSuite) TestHttpGetReferrer_RetriesExhausted() {
+	var calls atomic.Int32
+	server := s.newServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	directory := referral_directory.NewHttpDirectory(s.logger, server.URL, time.Second, 1)
+	_, err := directory.GetReferrer(context.Background(), 2)
+	assert.Error(s.T(), err)
+	assert.Equal(s.T(), int32(2), calls.Load())
+}
+
+func (s *ReferrerDirectoryTestSuite) TestHttpGetReferrer_NotFoundIsNotRetried() {
+	var calls atomic.Int32
+	server := s.newServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	directory := referral_directory.NewHttpDirectory(s.logger, server.URL, time.Second, 3)
+	_, err := directory.GetReferrer(context.Background(), 2)
+	assert.ErrorIs(s.T(), err, referral_directory.ErrReferrerNotFound)
+	assert.Equal(s.T(), int32(1), calls.Load())
+}
+
+func (s *ReferrerDirectoryTestSuite) TestHttpGetReferrer_Timeout() {
+	server := s.newServer(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		json.NewEncoder(w).Encode(referral_dto.ReferrerResponse{UserID: 2})
+	})
+
+	directory := referral_directory.NewHttpDirectory(s.logger, server.URL, 50*time.Millisecond, 0)
+	_, err := directory.GetReferrer(context.Background(), 2)
+	assert.Error(s.T(), err)
+}
+
+func (s *ReferrerDirectoryTestSuite) TestFixtureGetReferrer_Success() {
+	directory, err := referral_directory.NewFixtureDirectory(fixture_path)
+	require.NoError(s.T(), err)
+
+	referrer, err := directory.GetReferrer(context.Background(), 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, referrer.ReferrerID)
+	assert.Equal(s.T(), "0QD-q5a1Z3kYfDBgYUcUX_MigynA5FuiNx0i5ySt37rfrFeP", referrer.WalletAddress)
+	require.Len(s.T(), referrer.ReferredUsers, 1)
+	assert.Equal(s.T(), 3, referrer.ReferredUsers[0].UserID)
+}
+
+func (s *ReferrerDirectoryTestSuite) TestFixtureGetReferrer_NotFound() {
+	directory, err := referral_directory.NewFixtureDirectory(fixture_path)
+	require.NoError(s.T(), err)
+
+	_, err = directory.GetReferrer(context.Background(), 42)
+	assert.ErrorIs(s.T(), err, referral_directory.ErrReferrerNotFound)
+}
+
+func (s *ReferrerDirectoryTestSuite) TestFixtureDirectory_MissingFile() {
+	_, err := referral_directory.NewFixtureDirectory("fixtures/missing.json")
+	assert.Error(s.T(), err)
+}
+
+func TestReferrerDirectoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferrerDirectoryTestSuite))
+}
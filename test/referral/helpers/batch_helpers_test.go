@@ -0,0 +1,112 @@
+package helpers_test
+
+import (
+	"testing"
+
+	referral_helper "github.com/root9464/Go_GamlerDefi/src/modules/referral/helpers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/xssnick/tonutils-go/address"
+)
+
+const (
+	first_address  = "0QC9vm__DOB74-HkN9pxfMDMLYT4YlDPYj54dZ9yqvsgXYpZ"
+	second_address = "0QD-q5a1Z3kYfDBgYUcUX_MigynA5FuiNx0i5ySt37rfrFeP"
+)
+
+type BatchHelpersTestSuite struct {
+	suite.Suite
+}
+
+func (s *BatchHelpersTestSuite) entry(addr string, amount string) referral_helper.JettonEntry {
+	return referral_helper.JettonEntry{
+		Address: address.MustParseAddr(addr),
+		Amount:  decimal.RequireFromString(amount),
+	}
+}
+
+func (s *BatchHelpersTestSuite) TestMergeJettonEntries_SameAddress() {
+	merged := referral_helper.MergeJettonEntries([]referral_helper.JettonEntry{
+		s.entry(first_address, "20"),
+		s.entry(second_address, "2"),
+		s.entry(first_address, "0.5"),
+	})
+
+	require.Len(s.T(), merged, 2)
+	assert.Equal(s.T(), address.MustParseAddr(first_address).StringRaw(), merged[0].Address.StringRaw())
+	assert.True(s.T(), decimal.RequireFromString("20.5").Equal(merged[0].Amount))
+	assert.True(s.T(), decimal.RequireFromString("2").Equal(merged[1].Amount))
+}
+
+func (s *BatchHelpersTestSuite) TestMergeJettonEntries_BounceFlagIgnored() {
+	addr := address.MustParseAddr(first_address)
+	merged := referral_helper.MergeJettonEntries([]referral_helper.JettonEntry{
+		{Address: addr.Bounce(true), Amount: decimal.NewFromInt(1)},
+		{Address: addr.Bounce(false), Amount: decimal.NewFromInt(1)},
+	})
+
+	require.Len(s.T(), merged, 1)
+	assert.True(s.T(), decimal.NewFromInt(2).Equal(merged[0].Amount))
+}
+
+func (s *BatchHelpersTestSuite) TestMergeJettonEntries_DoesNotModifyInput() {
+	entries := []referral_helper.JettonEntry{s.entry(first_address, "1"), s.entry(first_address, "1")}
+	referral_helper.MergeJettonEntries(entries)
+
+	assert.True(s.T(), decimal.NewFromInt(1).Equal(entries[0].Amount))
+}
+
+// entries returns n entries to distinct addresses.
+func (s *BatchHelpersTestSuite) entries(n int) []referral_helper.JettonEntry {
+	entries := []referral_helper.JettonEntry{}
+	for i := range n {
+		data := make([]byte, 32)
+		data[0], data[1] = byte(i>>8), byte(i)
+		entries = append(entries, referral_helper.JettonEntry{
+			Address: address.NewAddress(0, 0, data),
+			Amount:  decimal.NewFromInt(int64(i + 1)),
+		})
+	}
+	return entries
+}
+
+func (s *BatchHelpersTestSuite) TestPackJettonEntries() {
+	limits := referral_helper.CellLimits{Cells: 40, Bits: 20 * 1023, Depth: 500}
+	entries := s.entries(50)
+
+	chunks, err := referral_helper.PackJettonEntries(entries, limits)
+	require.NoError(s.T(), err)
+	require.Greater(s.T(), len(chunks), 1, "The entries should not fit into one message")
+
+	packed := []referral_helper.JettonEntry{}
+	for i, chunk := range chunks {
+		// a dictionary of n entries takes 2n-1 cells
+		assert.LessOrEqual(s.T(), 2*len(chunk)-1, limits.Cells)
+		if i < len(chunks)-1 {
+			assert.Greater(s.T(), 2*(len(chunk)+1)-1, limits.Cells, "Every chunk but the last should be full")
+		}
+		packed = append(packed, chunk...)
+	}
+	assert.Equal(s.T(), entries, packed, "Every entry should be packed once, in order")
+
+	chunks, err = referral_helper.PackJettonEntries(entries, referral_helper.CellLimits{Cells: 1 << 13, Bits: 1 << 21, Depth: 500})
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), chunks, 1)
+}
+
+func (s *BatchHelpersTestSuite) TestPackJettonEntries_TooLarge() {
+	_, err := referral_helper.PackJettonEntries(s.entries(1), referral_helper.CellLimits{Cells: 1, Bits: 100, Depth: 500})
+	assert.Error(s.T(), err, "An entry larger than a message should be rejected")
+}
+
+func (s *BatchHelpersTestSuite) TestPackJettonEntries_Empty() {
+	chunks, err := referral_helper.PackJettonEntries(nil, referral_helper.CellLimits{Cells: 1, Bits: 1023, Depth: 1})
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), chunks)
+}
+
+func TestBatchHelpersTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchHelpersTestSuite))
+}
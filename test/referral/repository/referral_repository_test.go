@@ -46,6 +46,25 @@ func (s *ReferralRepositoryTestSuite) CreateDecimal128(value string) bson.Decima
 	return totalAmount
 }
 
+func (s *ReferralRepositoryTestSuite) CreateOpenPaymentOrder(leaderID int) bson.ObjectID {
+	order := referral_model.PaymentOrder{
+		ID:          bson.NewObjectID(),
+		LeaderID:    leaderID,
+		ReferrerID:  1,
+		ReferralID:  2,
+		TotalAmount: s.CreateDecimal128("150"),
+		TicketCount: 750,
+		CreatedAt:   time.Now().Unix(),
+		Levels: []referral_model.Level{
+			{LevelNumber: 0, Rate: s.CreateDecimal128("0.2"), Amount: s.CreateDecimal128("150"), Address: "0QC9vm__DOB74-HkN9pxfMDMLYT4YlDPYj54dZ9yqvsgXYpZ"},
+		},
+	}
+
+	err := s.repository.CreatePaymentOrder(context.Background(), order)
+	require.NoError(s.T(), err, "Failed to create payment order")
+	return order.ID
+}
+
 func (s *ReferralRepositoryTestSuite) TestCreatePaymentOrder() {
 	order := referral_model.PaymentOrder{
 		LeaderID:    3,
@@ -100,15 +119,15 @@ func (s *ReferralRepositoryTestSuite) TestGetAllPaymentOrders() {
 	assert.NotEmpty(s.T(), orders, "No payment orders found")
 }
 
-func (s *ReferralRepositoryTestSuite) TestDeletePaymentOrder() {
-	orderIDStr := "682092314a00a7558247b21f"
-	orderID, err := bson.ObjectIDFromHex(orderIDStr)
-	if err != nil {
-		s.logger.Fatalf("Invalid ObjectID string: %v", err)
-	}
+func (s *ReferralRepositoryTestSuite) TestCancelPaymentOrder() {
+	orderID := s.CreateOpenPaymentOrder(3)
+
+	order, err := s.repository.UpdatePaymentOrderStatus(context.Background(), orderID, referral_model.PaymentOrderStatusCancelled, "")
+	require.NoError(s.T(), err, "Failed to cancel payment order")
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusCancelled, order.Status)
 
-	err = s.repository.DeletePaymentOrder(context.Background(), orderID)
-	assert.NoError(s.T(), err, "Failed to delete payment order")
+	_, err = s.repository.UpdatePaymentOrderStatus(context.Background(), orderID, referral_model.PaymentOrderStatusOpen, "")
+	assert.ErrorIs(s.T(), err, referral_repository.ErrPaymentOrderTransition, "Cancelled order must be final")
 }
 
 func (s *ReferralRepositoryTestSuite) TestGetDebtFromAuthorToReferrer() {
@@ -131,20 +150,295 @@ func (s *ReferralRepositoryTestSuite) TestGetPaymentOrderByID() {
 }
 
 func (s *ReferralRepositoryTestSuite) TestAddTrHashToPaymentOrder() {
-	orderIDStr := "6867db8993638565f2466928"
 	trHash := "1e95861ef87af4c75811a0e3aaebd0ef9044bbc84e31425619405b8158d2795c"
-	orderID, err := bson.ObjectIDFromHex(orderIDStr)
-	if err != nil {
-		s.logger.Fatalf("Invalid ObjectID string: %v", err)
-	}
+	orderID := s.CreateOpenPaymentOrder(3)
+
+	err := s.repository.AddTrHashToPaymentOrder(context.Background(), orderID, trHash)
+	assert.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Open order must not accept tr hash")
+
+	_, err = s.repository.UpdatePaymentOrderStatus(context.Background(), orderID, referral_model.PaymentOrderStatusAwaitingPayment, "")
+	require.NoError(s.T(), err, "Failed to move payment order to awaiting payment")
 
 	err = s.repository.AddTrHashToPaymentOrder(context.Background(), orderID, trHash)
 	assert.NoError(s.T(), err, "Failed to add tr hash to payment order")
 }
 
-func (s *ReferralRepositoryTestSuite) TestDeleteAllPaymentOrders() {
-	err := s.repository.DeleteAllPaymentOrders(context.Background(), 1)
-	assert.NoError(s.T(), err, "Failed to delete all payment orders")
+func (s *ReferralRepositoryTestSuite) TestCancelAllPaymentOrders() {
+	s.CreateOpenPaymentOrder(1)
+
+	cancelled, err := s.repository.CancelAllPaymentOrders(context.Background(), 1)
+	assert.NoError(s.T(), err, "Failed to cancel all payment orders")
+	assert.NotZero(s.T(), cancelled, "No payment orders cancelled")
+
+	orders, err := s.repository.GetPaymentOrdersByAuthorID(context.Background(), 1)
+	assert.NoError(s.T(), err, "Failed to get payment orders")
+	assert.Empty(s.T(), orders, "Cancelled orders must not count as debt")
+}
+
+func (s *ReferralRepositoryTestSuite) TestPaymentOrderLifecycle() {
+	ctx := context.Background()
+	trHash := "1e95861ef87af4c75811a0e3aaebd0ef9044bbc84e31425619405b8158d2795c"
+	orderID := s.CreateOpenPaymentOrder(4)
+
+	_, err := s.repository.UpdatePaymentOrderStatus(ctx, orderID, referral_model.PaymentOrderStatusAwaitingPayment, "")
+	require.NoError(s.T(), err, "Failed to move payment order to awaiting payment")
+
+	order, err := s.repository.UpdatePaymentOrderStatus(ctx, orderID, referral_model.PaymentOrderStatusPaid, trHash)
+	require.NoError(s.T(), err, "Failed to mark payment order as paid")
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusPaid, order.Status)
+	assert.Equal(s.T(), trHash, order.TrHash)
+
+	require.Len(s.T(), order.StatusHistory, 3)
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusOpen, order.StatusHistory[1].From)
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusAwaitingPayment, order.StatusHistory[2].From)
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusPaid, order.StatusHistory[2].To)
+
+	_, err = s.repository.UpdatePaymentOrderStatus(ctx, orderID, referral_model.PaymentOrderStatusCancelled, "")
+	assert.ErrorIs(s.T(), err, referral_repository.ErrPaymentOrderTransition, "Paid order must be final")
+
+	orders, err := s.repository.GetPaymentOrdersByAuthorID(ctx, 4)
+	assert.NoError(s.T(), err, "Failed to get payment orders")
+	for _, o := range orders {
+		assert.NotEqual(s.T(), orderID, o.ID, "Paid order must not count as debt")
+	}
+}
+
+func (s *ReferralRepositoryTestSuite) TestExpirePaymentOrders() {
+	ctx := context.Background()
+	openID := s.CreateOpenPaymentOrder(6)
+	awaitingID := s.CreateOpenPaymentOrder(6)
+
+	_, err := s.repository.UpdatePaymentOrderStatus(ctx, awaitingID, referral_model.PaymentOrderStatusAwaitingPayment, "")
+	require.NoError(s.T(), err, "Failed to move payment order to awaiting payment")
+
+	_, err = s.repository.ExpirePaymentOrders(ctx, time.Now().Add(time.Minute).Unix())
+	require.NoError(s.T(), err, "Failed to expire payment orders")
+
+	order, err := s.repository.GetPaymentOrderByID(ctx, openID)
+	require.NoError(s.T(), err, "Failed to get payment order by ID")
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusExpired, order.Status)
+
+	order, err = s.repository.GetPaymentOrderByID(ctx, awaitingID)
+	require.NoError(s.T(), err, "Failed to get payment order by ID")
+	assert.Equal(s.T(), referral_model.PaymentOrderStatusAwaitingPayment, order.Status, "Order awaiting payment must not expire")
+
+	_, err = s.repository.UpdatePaymentOrderStatus(ctx, awaitingID, referral_model.PaymentOrderStatusPaid, "hash")
+	assert.NoError(s.T(), err, "Validated payment must still settle the order")
+}
+
+func (s *ReferralRepositoryTestSuite) TestPaymentBundleLifecycle() {
+	ctx := context.Background()
+	trHash := "1e95861ef87af4c75811a0e3aaebd0ef9044bbc84e31425619405b8158d2795c"
+	bundleID := bson.NewObjectID().Hex()
+	orderIDs := []bson.ObjectID{s.CreateOpenPaymentOrder(5), s.CreateOpenPaymentOrder(5)}
+
+	bundled, err := s.repository.BundlePaymentOrders(ctx, orderIDs, bundleID)
+	require.NoError(s.T(), err, "Failed to bundle payment orders")
+	assert.Equal(s.T(), int64(len(orderIDs)), bundled)
+
+	paid, err := s.repository.UpdatePaymentBundleStatus(ctx, bundleID, referral_model.PaymentOrderStatusPaid, trHash)
+	require.NoError(s.T(), err, "Failed to mark payment bundle as paid")
+	assert.Equal(s.T(), int64(len(orderIDs)), paid)
+
+	for _, orderID := range orderIDs {
+		order, err := s.repository.GetPaymentOrderByID(ctx, orderID)
+		require.NoError(s.T(), err, "Failed to get payment order by ID")
+		assert.Equal(s.T(), referral_model.PaymentOrderStatusPaid, order.Status)
+		assert.Equal(s.T(), trHash, order.TrHash)
+		assert.Contains(s.T(), order.BundleIDs, bundleID)
+	}
+}
+
+func (s *ReferralRepositoryTestSuite) TestClaimIdempotencyKey() {
+	now := time.Now()
+	record := referral_model.IdempotencyRecord{
+		Key:         "test:" + bson.NewObjectID().Hex(),
+		Fingerprint: "fingerprint",
+		Status:      referral_model.IdempotencyProcessing,
+		CreatedAt:   now.Unix(),
+		UpdatedAt:   now.Unix(),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	_, claimed, err := s.repository.ClaimIdempotencyKey(context.Background(), record)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.True(s.T(), claimed, "Free key was not claimed")
+
+	err = s.repository.CompleteIdempotencyKey(context.Background(), record.Key, referral_model.IdempotencyCompleted, referral_model.IdempotencyResult{StatusCode: 201, Message: "done"})
+	require.NoError(s.T(), err, "Failed to complete idempotency key")
+
+	existing, claimed, err := s.repository.ClaimIdempotencyKey(context.Background(), record)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.False(s.T(), claimed, "Taken key was claimed twice")
+	assert.Equal(s.T(), referral_model.IdempotencyCompleted, existing.Status)
+	assert.Equal(s.T(), "done", existing.Result.Message)
+
+	err = s.repository.ReleaseIdempotencyKey(context.Background(), record.Key)
+	assert.NoError(s.T(), err, "Failed to release idempotency key")
+}
+
+func (s *ReferralRepositoryTestSuite) TestClaimIdempotencyKey_Expired() {
+	now := time.Now()
+	record := referral_model.IdempotencyRecord{
+		Key:       "test:" + bson.NewObjectID().Hex(),
+		Status:    referral_model.IdempotencyCompleted,
+		CreatedAt: now.Add(-2 * time.Hour).Unix(),
+		ExpiresAt: now.Add(-time.Hour),
+	}
+
+	_, claimed, err := s.repository.ClaimIdempotencyKey(context.Background(), record)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	require.True(s.T(), claimed, "Free key was not claimed")
+
+	record.Status = referral_model.IdempotencyProcessing
+	record.CreatedAt = now.Unix()
+	record.ExpiresAt = now.Add(time.Hour)
+	_, claimed, err = s.repository.ClaimIdempotencyKey(context.Background(), record)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.True(s.T(), claimed, "Expired key was not taken over")
+
+	err = s.repository.ReleaseIdempotencyKey(context.Background(), record.Key)
+	assert.NoError(s.T(), err, "Failed to release idempotency key")
+}
+
+func (s *ReferralRepositoryTestSuite) TestClaimIdempotencyKey_StaleProcessing() {
+	now := time.Now()
+	record := referral_model.IdempotencyRecord{
+		Key:         "test:" + bson.NewObjectID().Hex(),
+		Fingerprint: "fingerprint",
+		Status:      referral_model.IdempotencyProcessing,
+		CreatedAt:   now.Add(-10 * time.Minute).Unix(),
+		LeaseUntil:  now.Add(-5 * time.Minute).Unix(),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	_, claimed, err := s.repository.ClaimIdempotencyKey(context.Background(), record)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	require.True(s.T(), claimed, "Free key was not claimed")
+
+	other := record
+	other.Fingerprint = "other"
+	other.CreatedAt = now.Unix()
+	other.LeaseUntil = now.Add(5 * time.Minute).Unix()
+	_, claimed, err = s.repository.ClaimIdempotencyKey(context.Background(), other)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.False(s.T(), claimed, "Stale key was taken over with a different payload")
+
+	retry := record
+	retry.CreatedAt = now.Unix()
+	retry.LeaseUntil = now.Add(5 * time.Minute).Unix()
+	taken, claimed, err := s.repository.ClaimIdempotencyKey(context.Background(), retry)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.True(s.T(), claimed, "Stale processing key was not taken over")
+	assert.Equal(s.T(), 2, taken.Attempts, "A taken over key should count the attempt")
+	assert.Equal(s.T(), record.CreatedAt, taken.CreatedAt, "A taken over key should keep its creation time")
+
+	_, claimed, err = s.repository.ClaimIdempotencyKey(context.Background(), retry)
+	require.NoError(s.T(), err, "Failed to claim idempotency key")
+	assert.False(s.T(), claimed, "Leased key was taken over")
+
+	err = s.repository.ReleaseIdempotencyKey(context.Background(), record.Key)
+	assert.NoError(s.T(), err, "Failed to release idempotency key")
+}
+
+func (s *ReferralRepositoryTestSuite) TestPayoutLifecycle() {
+	payout, err := s.repository.CreatePayout(context.Background(), referral_model.Payout{
+		ReferrerID:  1,
+		ReferralID:  2,
+		TicketCount: 100,
+		TotalAmount: s.CreateDecimal128("20"),
+		Levels: []referral_model.Level{
+			{LevelNumber: 0, Rate: s.CreateDecimal128("0.2"), Amount: s.CreateDecimal128("20"), Address: "0QC9vm__DOB74-HkN9pxfMDMLYT4YlDPYj54dZ9yqvsgXYpZ"},
+		},
+	})
+	require.NoError(s.T(), err, "Failed to create payout")
+	assert.Equal(s.T(), referral_model.PayoutStatusPending, payout.Status)
+
+	claimed, err := s.repository.ClaimPayout(context.Background(), time.Now().Add(time.Minute).Unix())
+	require.NoError(s.T(), err, "Failed to claim payout")
+	assert.Equal(s.T(), referral_model.PayoutStatusSending, claimed.Status)
+	assert.Equal(s.T(), 1, claimed.Attempts)
+
+	err = s.repository.UpdatePayoutStatus(context.Background(), claimed.ID, referral_model.PayoutStatusSent, "hash", "")
+	require.NoError(s.T(), err, "Failed to update payout status")
+
+	err = s.repository.UpdatePayoutStatus(context.Background(), claimed.ID, referral_model.PayoutStatusSent, "hash", "")
+	assert.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Payout was updated without a lease")
+}
+
+func (s *ReferralRepositoryTestSuite) TestRequeuePayout() {
+	ctx := context.Background()
+	payout, err := s.repository.CreatePayout(ctx, referral_model.Payout{
+		ReferrerID:  1,
+		ReferralID:  2,
+		TicketCount: 100,
+		TotalAmount: s.CreateDecimal128("20"),
+	})
+	require.NoError(s.T(), err, "Failed to create payout")
+
+	_, err = s.repository.RequeuePayout(ctx, payout.ID)
+	assert.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Pending payout was requeued")
+
+	claimed, err := s.repository.ClaimPayoutByID(ctx, payout.ID, time.Now().Add(time.Minute).Unix())
+	require.NoError(s.T(), err, "Failed to claim payout")
+	err = s.repository.UpdatePayoutStatus(ctx, claimed.ID, referral_model.PayoutStatusFailed, "", "transaction execution failed")
+	require.NoError(s.T(), err, "Failed to update payout status")
+
+	requeued, err := s.repository.RequeuePayout(ctx, payout.ID)
+	require.NoError(s.T(), err, "Failed to requeue payout")
+	assert.Equal(s.T(), referral_model.PayoutStatusPending, requeued.Status)
+	assert.Equal(s.T(), 0, requeued.Attempts)
+	assert.Empty(s.T(), requeued.Error)
+}
+
+func (s *ReferralRepositoryTestSuite) TestLeaderProgramVersions() {
+	ctx := context.Background()
+	leaderID := int(time.Now().UnixNano() % 1_000_000_000)
+	require.NoError(s.T(), s.repository.EnsureIndexes(ctx), "Failed to ensure indexes")
+
+	first, err := s.repository.CreateLeaderProgram(ctx, referral_model.LeaderProgram{LeaderID: leaderID, Depth: 1})
+	require.NoError(s.T(), err, "Failed to create leader program")
+	assert.Equal(s.T(), 1, first.Version)
+
+	second, err := s.repository.CreateLeaderProgram(ctx, referral_model.LeaderProgram{LeaderID: leaderID, Depth: 2})
+	require.NoError(s.T(), err, "Failed to create leader program")
+	assert.Equal(s.T(), 2, second.Version)
+
+	stored, err := s.repository.GetLeaderProgramVersion(ctx, leaderID, first.Version)
+	require.NoError(s.T(), err, "Failed to get leader program version")
+	assert.Equal(s.T(), 1, stored.Depth, "Earlier version must stay unchanged")
+
+	require.NoError(s.T(), s.repository.DeleteLeaderProgram(ctx, leaderID), "Failed to delete leader program")
+	_, err = s.repository.GetLeaderProgram(ctx, leaderID)
+	assert.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Deleted program must not be in force")
+	assert.ErrorIs(s.T(), s.repository.DeleteLeaderProgram(ctx, leaderID), mongo.ErrNoDocuments)
+
+	recreated, err := s.repository.CreateLeaderProgram(ctx, referral_model.LeaderProgram{LeaderID: leaderID, Depth: 3})
+	require.NoError(s.T(), err, "Failed to create leader program")
+	assert.Equal(s.T(), 4, recreated.Version, "Re-created program must continue the versions")
+
+	current, err := s.repository.GetLeaderProgram(ctx, leaderID)
+	require.NoError(s.T(), err, "Failed to get leader program")
+	assert.Equal(s.T(), recreated.Version, current.Version)
+}
+
+func (s *ReferralRepositoryTestSuite) TestBonusScheduleInForce() {
+	ctx := context.Background()
+	require.NoError(s.T(), s.repository.EnsureIndexes(ctx), "Failed to ensure indexes")
+
+	inForce, err := s.repository.CreateBonusSchedule(ctx, referral_model.BonusSchedule{EffectiveFrom: time.Now().Add(-time.Hour).Unix()})
+	require.NoError(s.T(), err, "Failed to create bonus schedule")
+	planned, err := s.repository.CreateBonusSchedule(ctx, referral_model.BonusSchedule{EffectiveFrom: time.Now().Add(time.Hour).Unix()})
+	require.NoError(s.T(), err, "Failed to create bonus schedule")
+	assert.Equal(s.T(), inForce.Version+1, planned.Version)
+
+	inForce.EffectiveFrom = time.Now().Add(time.Hour).Unix()
+	_, err = s.repository.UpdateBonusSchedule(ctx, inForce)
+	assert.ErrorIs(s.T(), err, referral_repository.ErrBonusScheduleInForce, "Schedule in force must not be updated")
+	assert.ErrorIs(s.T(), s.repository.DeleteBonusSchedule(ctx, inForce.ID), referral_repository.ErrBonusScheduleInForce, "Schedule in force must not be deleted")
+
+	require.NoError(s.T(), s.repository.DeleteBonusSchedule(ctx, planned.ID), "Failed to delete planned bonus schedule")
+	assert.ErrorIs(s.T(), s.repository.DeleteBonusSchedule(ctx, planned.ID), mongo.ErrNoDocuments)
 }
 
 func TestReferralRepositoryTestSuite(t *testing.T) {
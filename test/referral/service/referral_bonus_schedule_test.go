@@ -0,0 +1,284 @@
+package referral_service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/root9464/Go_GamlerDefi/src/config"
+	referral_adapters "github.com/root9464/Go_GamlerDefi/src/modules/referral/adapters"
+	referral_directory "github.com/root9464/Go_GamlerDefi/src/modules/referral/directory"
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	referral_repository "github.com/root9464/Go_GamlerDefi/src/modules/referral/repository"
+	referral_service "github.com/root9464/Go_GamlerDefi/src/modules/referral/service"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	leaderID   = 1
+	referrerID = 2
+	referralID = 3
+
+	leaderWallet   = "0QC9vm__DOB74-HkN9pxfMDMLYT4YlDPYj54dZ9yqvsgXYpZ"
+	referrerWallet = "0QD-q5a1Z3kYfDBgYUcUX_MigynA5FuiNx0i5ySt37rfrFeP"
+)
+
+// memoryReferrals keeps the schedules, leader programs, payment orders and
+// idempotency keys the bonus engine works with in memory, the other methods
+// are not used by it.
+type memoryReferrals struct {
+	referral_repository.IReferralRepository
+	schedules []referral_model.BonusSchedule
+	programs  []referral_model.LeaderProgram
+	orders    []referral_model.PaymentOrder
+	keys      map[string]referral_model.IdempotencyRecord
+	// completeErr fails the completion of idempotency keys.
+	completeErr error
+}
+
+func newMemoryReferrals() *memoryReferrals {
+	return &memoryReferrals{keys: map[string]referral_model.IdempotencyRecord{}}
+}
+
+func (r *memoryReferrals) CreateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error) {
+	schedule.ID = bson.NewObjectID()
+	schedule.Version = len(r.schedules) + 1
+	r.schedules = append(r.schedules, schedule)
+	return schedule, nil
+}
+
+func (r *memoryReferrals) GetBonusScheduleByID(ctx context.Context, scheduleID bson.ObjectID) (referral_model.BonusSchedule, error) {
+	for _, schedule := range r.schedules {
+		if schedule.ID == scheduleID {
+			return schedule, nil
+		}
+	}
+	return referral_model.BonusSchedule{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) GetBonusScheduleAt(ctx context.Context, at int64) (referral_model.BonusSchedule, error) {
+	var found *referral_model.BonusSchedule
+	for i, schedule := range r.schedules {
+		if schedule.EffectiveFrom > at {
+			continue
+		}
+		if found == nil || schedule.EffectiveFrom > found.EffectiveFrom ||
+			(schedule.EffectiveFrom == found.EffectiveFrom && schedule.Version > found.Version) {
+			found = &r.schedules[i]
+		}
+	}
+	if found == nil {
+		return referral_model.BonusSchedule{}, mongo.ErrNoDocuments
+	}
+	return *found, nil
+}
+
+func (r *memoryReferrals) UpdateBonusSchedule(ctx context.Context, schedule referral_model.BonusSchedule) (referral_model.BonusSchedule, error) {
+	for i, stored := range r.schedules {
+		if stored.ID == schedule.ID {
+			if stored.EffectiveFrom <= time.Now().Unix() {
+				return referral_model.BonusSchedule{}, referral_repository.ErrBonusScheduleInForce
+			}
+			r.schedules[i].Levels = schedule.Levels
+			r.schedules[i].EffectiveFrom = schedule.EffectiveFrom
+			return r.schedules[i], nil
+		}
+	}
+	return referral_model.BonusSchedule{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) DeleteBonusSchedule(ctx context.Context, scheduleID bson.ObjectID) error {
+	for i, schedule := range r.schedules {
+		if schedule.ID == scheduleID {
+			if schedule.EffectiveFrom <= time.Now().Unix() {
+				return referral_repository.ErrBonusScheduleInForce
+			}
+			r.schedules = append(r.schedules[:i], r.schedules[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) GetLeaderProgram(ctx context.Context, leaderID int) (referral_model.LeaderProgram, error) {
+	for i := len(r.programs) - 1; i >= 0; i-- {
+		if r.programs[i].LeaderID != leaderID {
+			continue
+		}
+		if r.programs[i].Deleted {
+			break
+		}
+		return r.programs[i], nil
+	}
+	return referral_model.LeaderProgram{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) GetPaymentOrdersByAuthorID(ctx context.Context, authorID int) ([]referral_model.PaymentOrder, error) {
+	orders := []referral_model.PaymentOrder{}
+	for _, order := range r.orders {
+		if order.LeaderID == authorID {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *memoryReferrals) UpdatePaymentOrder(ctx context.Context, order referral_model.PaymentOrder) error {
+	return mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) CreatePaymentOrder(ctx context.Context, order referral_model.PaymentOrder) error {
+	r.orders = append(r.orders, order)
+	return nil
+}
+
+type ReferralProgramTestSuite struct {
+	suite.Suite
+	repository *memoryReferrals
+	service    referral_service.IReferralService
+}
+
+func (s *ReferralProgramTestSuite) SetupTest() {
+	s.repository = newMemoryReferrals()
+	directory := referral_directory.NewMemoryDirectory([]referral_dto.ReferrerResponse{
+		{UserID: leaderID, WalletAddress: leaderWallet, ReferredUsers: []referral_dto.ReferredUserResponse{{UserID: referrerID}}},
+		{UserID: referrerID, ReferrerID: leaderID, WalletAddress: referrerWallet, ReferredUsers: []referral_dto.ReferredUserResponse{{UserID: referralID}}},
+		{UserID: referralID, ReferrerID: referrerID},
+	})
+	s.service = referral_service.NewReferralService(logger.GetLogger(), nil, nil, &config.Config{}, nil, s.repository, directory)
+}
+
+func (s *ReferralProgramTestSuite) levels(rates ...string) []referral_dto.ScheduleLevel {
+	levels := make([]referral_dto.ScheduleLevel, len(rates))
+	for i, rate := range rates {
+		levels[i] = referral_dto.ScheduleLevel{LevelNumber: i, Rate: decimal.RequireFromString(rate)}
+	}
+	return levels
+}
+
+func (s *ReferralProgramTestSuite) createSchedule(effectiveFrom int64, rates ...string) referral_dto.BonusSchedule {
+	schedule, err := s.service.CreateBonusSchedule(context.Background(), referral_dto.BonusScheduleRequest{
+		Levels:        s.levels(rates...),
+		EffectiveFrom: effectiveFrom,
+	})
+	require.NoError(s.T(), err)
+	return schedule
+}
+
+// seedSchedule stores a schedule directly, the service accepts only schedules
+// coming into force in the future.
+func (s *ReferralProgramTestSuite) seedSchedule(effectiveFrom int64, rates ...string) referral_dto.BonusSchedule {
+	schedule, err := referral_adapters.CreateBonusScheduleFromDTO(referral_dto.BonusScheduleRequest{
+		Levels:        s.levels(rates...),
+		EffectiveFrom: effectiveFrom,
+	})
+	require.NoError(s.T(), err)
+	schedule, err = s.repository.CreateBonusSchedule(context.Background(), schedule)
+	require.NoError(s.T(), err)
+	scheduleDTO, err := referral_adapters.CreateBonusScheduleFromModel(schedule)
+	require.NoError(s.T(), err)
+	return scheduleDTO
+}
+
+// accrue runs a leader accrual for 10 tickets and returns the stored order.
+func (s *ReferralProgramTestSuite) accrue() referral_model.PaymentOrder {
+	_, err := s.service.ReferralProcess(context.Background(), referral_dto.ReferralProcessRequest{
+		PaymentType: referral_dto.PaymentLeader,
+		LeaderID:    leaderID,
+		ReferrerID:  referrerID,
+		ReferralID:  referralID,
+		TicketCount: 10,
+	}, bson.NewObjectID().Hex())
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), s.repository.orders)
+	return s.repository.orders[len(s.repository.orders)-1]
+}
+
+// amounts lists the bonus of every level of the order.
+func (s *ReferralProgramTestSuite) amounts(order referral_model.PaymentOrder) []string {
+	amounts := make([]string, len(order.Levels))
+	for i, level := range order.Levels {
+		amounts[i] = decimal.RequireFromString(level.Amount.String()).String()
+	}
+	return amounts
+}
+
+func (s *ReferralProgramTestSuite) TestBonusSchedule_DefaultFallback() {
+	order := s.accrue()
+	assert.Equal(s.T(), 0, order.ScheduleVersion, "Without schedules the built-in rates should be used")
+	assert.Equal(s.T(), "platform", order.ScheduleSource)
+	assert.Equal(s.T(), []string{"2", "0.2"}, s.amounts(order))
+
+	s.createSchedule(time.Now().Add(time.Hour).Unix(), "0.5")
+	order = s.accrue()
+	assert.Equal(s.T(), 0, order.ScheduleVersion, "A schedule not in force yet should not be used")
+	assert.Equal(s.T(), []string{"2", "0.2"}, s.amounts(order))
+}
+
+func (s *ReferralProgramTestSuite) TestBonusSchedule_InForce() {
+	old := s.seedSchedule(time.Now().Add(-2*time.Hour).Unix(), "0.1", "0.05")
+	current := s.seedSchedule(time.Now().Add(-time.Hour).Unix(), "0.3")
+	s.createSchedule(time.Now().Add(time.Hour).Unix(), "0.5")
+
+	order := s.accrue()
+	assert.NotEqual(s.T(), old.Version, order.ScheduleVersion)
+	assert.Equal(s.T(), current.Version, order.ScheduleVersion, "The latest schedule in force should be used")
+	assert.Equal(s.T(), "platform", order.ScheduleSource)
+	assert.Equal(s.T(), []string{"3"}, s.amounts(order))
+}
+
+func (s *ReferralProgramTestSuite) TestBonusSchedule_Immutable() {
+	ctx := context.Background()
+	inForce := s.seedSchedule(time.Now().Add(-time.Hour).Unix(), "0.2", "0.02")
+	planned := s.createSchedule(time.Now().Add(time.Hour).Unix(), "0.3")
+
+	_, err := s.service.UpdateBonusSchedule(ctx, inForce.ID, referral_dto.BonusScheduleRequest{
+		Levels:        s.levels("0.9"),
+		EffectiveFrom: time.Now().Add(time.Hour).Unix(),
+	})
+	assert.Equal(s.T(), 409, errors.GetCode(err), "unexpected result: %v", err)
+	assert.Equal(s.T(), 409, errors.GetCode(s.service.DeleteBonusSchedule(ctx, inForce.ID)))
+
+	id, err := bson.ObjectIDFromHex(inForce.ID)
+	require.NoError(s.T(), err)
+	stored, err := s.repository.GetBonusScheduleByID(ctx, id)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), stored.Levels, 2, "A schedule in force should stay unchanged")
+
+	updated, err := s.service.UpdateBonusSchedule(ctx, planned.ID, referral_dto.BonusScheduleRequest{
+		Levels:        s.levels("0.4"),
+		EffectiveFrom: planned.EffectiveFrom,
+	})
+	require.NoError(s.T(), err, "A planned schedule should stay editable")
+	assert.Equal(s.T(), planned.Version, updated.Version)
+	assert.NoError(s.T(), s.service.DeleteBonusSchedule(ctx, planned.ID))
+
+	assert.Equal(s.T(), 404, errors.GetCode(s.service.DeleteBonusSchedule(ctx, planned.ID)))
+	assert.Equal(s.T(), 400, errors.GetCode(s.service.DeleteBonusSchedule(ctx, "not-an-id")))
+}
+
+func (s *ReferralProgramTestSuite) TestBonusSchedule_PastStart() {
+	ctx := context.Background()
+	past := referral_dto.BonusScheduleRequest{Levels: s.levels("0.3"), EffectiveFrom: time.Now().Add(-time.Hour).Unix()}
+
+	_, err := s.service.CreateBonusSchedule(ctx, past)
+	assert.Equal(s.T(), 400, errors.GetCode(err), "A schedule starting in the past should be rejected")
+	assert.Empty(s.T(), s.repository.schedules)
+
+	planned := s.createSchedule(time.Now().Add(time.Hour).Unix(), "0.3")
+	_, err = s.service.UpdateBonusSchedule(ctx, planned.ID, past)
+	assert.Equal(s.T(), 400, errors.GetCode(err), "A schedule should not be moved into the past")
+	assert.Equal(s.T(), planned.EffectiveFrom, s.repository.schedules[0].EffectiveFrom)
+}
+
+func TestReferralProgramTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferralProgramTestSuite))
+}
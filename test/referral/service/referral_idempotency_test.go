@@ -0,0 +1,91 @@
+package referral_service_test
+
+import (
+	"context"
+	"slices"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (r *memoryReferrals) ClaimIdempotencyKey(ctx context.Context, record referral_model.IdempotencyRecord) (referral_model.IdempotencyRecord, bool, error) {
+	existing, found := r.keys[record.Key]
+	if !found {
+		record.Attempts = 1
+		r.keys[record.Key] = record
+		return record, true, nil
+	}
+	if existing.Status == referral_model.IdempotencyProcessing && existing.Fingerprint == record.Fingerprint && existing.LeaseUntil <= record.CreatedAt {
+		existing.LeaseUntil = record.LeaseUntil
+		existing.Attempts++
+		r.keys[record.Key] = existing
+		return existing, true, nil
+	}
+	return existing, false, nil
+}
+
+func (r *memoryReferrals) CompleteIdempotencyKey(ctx context.Context, key string, status referral_model.IdempotencyStatus, result referral_model.IdempotencyResult) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	record := r.keys[key]
+	record.Status = status
+	record.Result = result
+	r.keys[key] = record
+	return nil
+}
+
+func (r *memoryReferrals) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	delete(r.keys, key)
+	return nil
+}
+
+func (r *memoryReferrals) GetPayoutByIdempotencyKey(ctx context.Context, key string) (referral_model.Payout, error) {
+	return referral_model.Payout{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) GetPaymentOrderByIdempotencyKey(ctx context.Context, key string) (referral_model.PaymentOrder, error) {
+	for _, order := range r.orders {
+		if slices.Contains(order.IdempotencyKeys, key) {
+			return order, nil
+		}
+	}
+	return referral_model.PaymentOrder{}, mongo.ErrNoDocuments
+}
+
+func (s *ReferralProgramTestSuite) TestReferralProcess_CompletionFailed() {
+	ctx := context.Background()
+	key := bson.NewObjectID().Hex()
+	req := referral_dto.ReferralProcessRequest{
+		PaymentType: referral_dto.PaymentLeader,
+		LeaderID:    leaderID,
+		ReferrerID:  referrerID,
+		ReferralID:  referralID,
+		TicketCount: 10,
+	}
+
+	s.repository.completeErr = assert.AnError
+	_, err := s.service.ReferralProcess(ctx, req, key)
+	assert.Equal(s.T(), 500, errors.GetCode(err), "A failed completion should be reported")
+	require.Len(s.T(), s.repository.orders, 1)
+	assert.Equal(s.T(), []string{key}, s.repository.orders[0].IdempotencyKeys)
+
+	s.repository.completeErr = nil
+	_, err = s.service.ReferralProcess(ctx, req, key)
+	assert.Equal(s.T(), 409, errors.GetCode(err), "A leased key should not be taken over")
+
+	record := s.repository.keys[key]
+	record.LeaseUntil = 0
+	s.repository.keys[key] = record
+
+	result, err := s.service.ReferralProcess(ctx, req, key)
+	require.NoError(s.T(), err, "A stale key should be taken over")
+	assert.Equal(s.T(), "Payment order created successfully", result.Message)
+	assert.Len(s.T(), s.repository.orders, 1, "The retry should not accrue the request again")
+	assert.Equal(s.T(), referral_model.IdempotencyCompleted, s.repository.keys[key].Status)
+}
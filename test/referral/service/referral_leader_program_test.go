@@ -0,0 +1,159 @@
+package referral_service_test
+
+import (
+	"context"
+	"time"
+
+	referral_dto "github.com/root9464/Go_GamlerDefi/src/modules/referral/dto"
+	referral_model "github.com/root9464/Go_GamlerDefi/src/modules/referral/model"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (r *memoryReferrals) nextProgramVersion(leaderID int) int {
+	version := 0
+	for _, program := range r.programs {
+		if program.LeaderID == leaderID {
+			version = max(version, program.Version)
+		}
+	}
+	return version + 1
+}
+
+func (r *memoryReferrals) CreateLeaderProgram(ctx context.Context, program referral_model.LeaderProgram) (referral_model.LeaderProgram, error) {
+	program.ID = bson.NewObjectID()
+	program.Version = r.nextProgramVersion(program.LeaderID)
+	r.programs = append(r.programs, program)
+	return program, nil
+}
+
+func (r *memoryReferrals) GetLeaderProgramVersion(ctx context.Context, leaderID int, version int) (referral_model.LeaderProgram, error) {
+	for _, program := range r.programs {
+		if program.LeaderID == leaderID && program.Version == version {
+			return program, nil
+		}
+	}
+	return referral_model.LeaderProgram{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryReferrals) DeleteLeaderProgram(ctx context.Context, leaderID int) error {
+	if _, err := r.GetLeaderProgram(ctx, leaderID); err != nil {
+		return err
+	}
+	r.programs = append(r.programs, referral_model.LeaderProgram{
+		ID:       bson.NewObjectID(),
+		LeaderID: leaderID,
+		Version:  r.nextProgramVersion(leaderID),
+		Deleted:  true,
+	})
+	return nil
+}
+
+func (s *ReferralProgramTestSuite) upsertProgram(req referral_dto.LeaderProgramRequest) referral_dto.LeaderProgram {
+	program, err := s.service.UpsertLeaderProgram(context.Background(), leaderID, req)
+	require.NoError(s.T(), err)
+	return program
+}
+
+// owe stores an open payment order of the leader worth `amount`.
+func (s *ReferralProgramTestSuite) owe(amount string) {
+	total, err := bson.ParseDecimal128(amount)
+	require.NoError(s.T(), err)
+	s.repository.orders = append(s.repository.orders, referral_model.PaymentOrder{
+		ID:          bson.NewObjectID(),
+		LeaderID:    leaderID,
+		ReferrerID:  referrerID,
+		ReferralID:  referralID,
+		TotalAmount: total,
+		Status:      referral_model.PaymentOrderStatusOpen,
+	})
+}
+
+func (s *ReferralProgramTestSuite) TestLeaderProgram_Rates() {
+	s.seedSchedule(time.Now().Add(-time.Hour).Unix(), "0.3", "0.03")
+
+	s.upsertProgram(referral_dto.LeaderProgramRequest{Levels: s.levels("0.25")})
+	program := s.upsertProgram(referral_dto.LeaderProgramRequest{Levels: s.levels("0.25", "0.05")})
+	require.Equal(s.T(), 2, program.Version, "Every change should bump the program version")
+
+	order := s.accrue()
+	assert.Equal(s.T(), "leader", order.ScheduleSource)
+	assert.Equal(s.T(), program.Version, order.ProgramVersion, "The order should reference the program version")
+	assert.Equal(s.T(), []string{"2.5", "0.5"}, s.amounts(order), "Custom rates should replace the platform schedule")
+}
+
+func (s *ReferralProgramTestSuite) TestLeaderProgram_Depth() {
+	schedule := s.seedSchedule(time.Now().Add(-time.Hour).Unix(), "0.3", "0.03")
+	s.upsertProgram(referral_dto.LeaderProgramRequest{Levels: s.levels("0.25")})
+
+	program := s.upsertProgram(referral_dto.LeaderProgramRequest{Depth: 1})
+	order := s.accrue()
+	assert.Equal(s.T(), "leader", order.ScheduleSource)
+	assert.Equal(s.T(), schedule.Version, order.ScheduleVersion, "The order should reference the schedule its rates come from")
+	assert.Equal(s.T(), program.Version, order.ProgramVersion, "The order should reference the program cutting the depth")
+	assert.Equal(s.T(), []string{"3"}, s.amounts(order), "The depth should cut the platform rates")
+
+	s.upsertProgram(referral_dto.LeaderProgramRequest{Depth: 5})
+	order = s.accrue()
+	assert.Equal(s.T(), []string{"3", "0.3"}, s.amounts(order), "A depth over the rates should pay every level")
+}
+
+func (s *ReferralProgramTestSuite) TestLeaderProgram_MaxDebt() {
+	ctx := context.Background()
+	s.owe("15")
+
+	_, err := s.service.AssessInvitationAbility(ctx, leaderID)
+	assert.Equal(s.T(), 402, errors.GetCode(err), "The platform debt limit should apply without a program")
+
+	limit := decimal.NewFromInt(25)
+	s.upsertProgram(referral_dto.LeaderProgramRequest{MaxDebt: &limit})
+	ok, err := s.service.AssessInvitationAbility(ctx, leaderID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), ok, "The custom debt limit should apply")
+
+	order := s.accrue()
+	assert.Equal(s.T(), "platform", order.ScheduleSource, "A debt limit alone should keep the platform rates")
+	assert.Equal(s.T(), 0, order.ScheduleVersion)
+	assert.Equal(s.T(), 1, order.ProgramVersion)
+	assert.Equal(s.T(), []string{"2", "0.2"}, s.amounts(order))
+}
+
+func (s *ReferralProgramTestSuite) TestLeaderProgram_Deleted() {
+	ctx := context.Background()
+	s.upsertProgram(referral_dto.LeaderProgramRequest{Levels: s.levels("0.25")})
+	require.NoError(s.T(), s.service.DeleteLeaderProgram(ctx, leaderID))
+
+	order := s.accrue()
+	assert.Equal(s.T(), "platform", order.ScheduleSource, "Without a program the platform default should apply")
+	assert.Zero(s.T(), order.ProgramVersion)
+	assert.Equal(s.T(), []string{"2", "0.2"}, s.amounts(order))
+
+	assert.Equal(s.T(), 404, errors.GetCode(s.service.DeleteLeaderProgram(ctx, leaderID)))
+
+	program := s.upsertProgram(referral_dto.LeaderProgramRequest{Levels: s.levels("0.3")})
+	assert.Equal(s.T(), 3, program.Version, "A re-created program should continue the versions")
+}
+
+func (s *ReferralProgramTestSuite) TestLeaderProgram_Invalid() {
+	negative := decimal.NewFromInt(-1)
+	tests := []struct {
+		name string
+		req  referral_dto.LeaderProgramRequest
+	}{
+		{name: "rate over one", req: referral_dto.LeaderProgramRequest{Levels: s.levels("1.5")}},
+		{name: "levels with a gap", req: referral_dto.LeaderProgramRequest{Levels: []referral_dto.ScheduleLevel{{LevelNumber: 1, Rate: decimal.RequireFromString("0.1")}}}},
+		{name: "negative debt limit", req: referral_dto.LeaderProgramRequest{MaxDebt: &negative}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := s.service.UpsertLeaderProgram(context.Background(), leaderID, tt.req)
+			assert.Equal(s.T(), 400, errors.GetCode(err), "unexpected result: %v", err)
+		})
+	}
+	assert.Empty(s.T(), s.repository.programs, "An invalid program should not be stored")
+}
@@ -0,0 +1,285 @@
+package ton_service_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	jwt_dto "github.com/root9464/Go_GamlerDefi/src/modules/jwt/dto"
+	jwt_functions "github.com/root9464/Go_GamlerDefi/src/modules/jwt/functions"
+	jwt_helpers "github.com/root9464/Go_GamlerDefi/src/modules/jwt/helpers"
+	jwt_keys "github.com/root9464/Go_GamlerDefi/src/modules/jwt/keys"
+	jwt_repository "github.com/root9464/Go_GamlerDefi/src/modules/jwt/repository"
+	jwt_service "github.com/root9464/Go_GamlerDefi/src/modules/jwt/service"
+	jwt_utils "github.com/root9464/Go_GamlerDefi/src/modules/jwt/utils"
+	ton_dto "github.com/root9464/Go_GamlerDefi/src/modules/ton/dto"
+	ton_proof "github.com/root9464/Go_GamlerDefi/src/modules/ton/proof"
+	ton_repository "github.com/root9464/Go_GamlerDefi/src/modules/ton/repository"
+	ton_service "github.com/root9464/Go_GamlerDefi/src/modules/ton/service"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	privateKeyStr = "MHcCAQEEIPemCJai8w+gAm+3N30cyqvIuZqmudIulBf6soXQD+iooAoGCCqGSM49AwEHoUQDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+	publicKeyStr  = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEQ/TqYy3uYp8JyM2Yoh7PkEXZ9bF8CJk+yKaHImsB/nhBixuA4W7PEknvUWch25is4IPyiVPge6LjAUWUP+tq+w=="
+
+	domain = "gamler.online"
+	userID = int64(5187512201)
+)
+
+var user = jwt_dto.UserData{ID: userID, Roles: []jwt_dto.Role{jwt_dto.RoleHost}}
+
+// stubKeys stands for the get_public_key lookup of deployed wallets.
+type stubKeys map[string]ed25519.PublicKey
+
+func (k stubKeys) PublicKey(ctx context.Context, addr *address.Address) (ed25519.PublicKey, error) {
+	key, ok := k[addr.StringRaw()]
+	if !ok {
+		return nil, fmt.Errorf("wallet %s is not deployed", addr.StringRaw())
+	}
+	return key, nil
+}
+
+type testWallet struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	address    *address.Address
+	stateInit  string
+}
+
+type TonProofTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	helpers jwt_helpers.IJwtHelper
+	tokens  jwt_service.IJwtService
+	keys    stubKeys
+	service ton_service.ITonService
+}
+
+func (s *TonProofTestSuite) SetupTest() {
+	log := logger.GetLogger()
+	validator := validator.New()
+
+	keys, err := jwt_keys.Load(privateKeyStr, publicKeyStr, "", nil)
+	require.NoError(s.T(), err)
+
+	s.ctx = context.Background()
+	s.helpers = jwt_helpers.NewJwtHelper(log, validator)
+	funcs := jwt_functions.NewJwtFuncs(log, validator, keys, s.helpers)
+	s.tokens = jwt_service.NewJwtService(log, keys, funcs, jwt_repository.NewMemoryRepository())
+	s.keys = stubKeys{}
+
+	options := ton_service.ProofOptions{Domains: []string{domain}, TTL: 15 * time.Minute}
+	s.service = ton_service.NewTonService(log, options, s.keys, ton_repository.NewMemoryRepository(), s.tokens)
+}
+
+func (s *TonProofTestSuite) newWallet(version wallet.VersionConfig) testWallet {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(s.T(), err)
+
+	stateInit, err := wallet.GetStateInit(publicKey, version, wallet.DefaultSubwallet)
+	require.NoError(s.T(), err)
+	stateCell, err := tlb.ToCell(stateInit)
+	require.NoError(s.T(), err)
+
+	return testWallet{
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		address:    address.NewAddress(0, 0, stateCell.Hash()),
+		stateInit:  base64.StdEncoding.EncodeToString(stateCell.ToBOC()),
+	}
+}
+
+func (s *TonProofTestSuite) payload() string {
+	payload, err := s.service.GenerateProofPayload(s.ctx)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), payload.Payload, 64)
+	return payload.Payload
+}
+
+func (s *TonProofTestSuite) request(w testWallet, payload string, domain string, signedAt time.Time) ton_dto.TonProofRequest {
+	proof := ton_proof.Proof{Address: w.address, Domain: domain, Timestamp: signedAt.Unix(), Payload: payload}
+	signature := ed25519.Sign(w.privateKey, proof.Message())
+
+	return ton_dto.TonProofRequest{
+		Address:   w.address.StringRaw(),
+		Network:   "-239",
+		PublicKey: hex.EncodeToString(w.publicKey),
+		Proof: ton_dto.TonProof{
+			Timestamp: signedAt.Unix(),
+			Domain:    ton_dto.TonProofDomain{LengthBytes: uint32(len(domain)), Value: domain},
+			Payload:   payload,
+			Signature: base64.StdEncoding.EncodeToString(signature),
+			StateInit: w.stateInit,
+		},
+	}
+}
+
+func (s *TonProofTestSuite) TestCheckProof() {
+	cases := []struct {
+		name    string
+		version wallet.VersionConfig
+	}{
+		{name: "v3r2", version: wallet.V3R2},
+		{name: "v4r2", version: wallet.V4R2},
+		{name: "v5r1", version: wallet.ConfigV5R1Final{NetworkGlobalID: wallet.MainnetGlobalID}},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			w := s.newWallet(tc.version)
+
+			tokens, err := s.service.CheckProof(s.ctx, user, s.request(w, s.payload(), domain, time.Now()))
+			require.NoError(s.T(), err)
+
+			_, publicKey, err := jwt_utils.Base64ToKeys(privateKeyStr, publicKeyStr)
+			require.NoError(s.T(), err)
+			payload, err := s.helpers.ParseJwt(tokens.AccessToken, publicKey)
+			require.NoError(s.T(), err)
+			s.Equal(userID, payload.Sub)
+			s.Equal(w.address.Bounce(false).String(), payload.Wallet)
+			s.True(payload.HasRole(jwt_dto.RoleHost), "roles of the caller should be kept")
+
+			refreshed, err := s.tokens.RefreshTokens(s.ctx, tokens.RefreshToken)
+			require.NoError(s.T(), err)
+			payload, err = s.helpers.ParseJwt(refreshed.AccessToken, publicKey)
+			require.NoError(s.T(), err)
+			s.Equal(w.address.Bounce(false).String(), payload.Wallet, "wallet should survive the rotation")
+		})
+	}
+}
+
+func (s *TonProofTestSuite) TestCheckProof_ChainKey() {
+	w := s.newWallet(wallet.V4R2)
+	s.keys[w.address.StringRaw()] = w.publicKey
+
+	request := s.request(w, s.payload(), domain, time.Now())
+	request.Proof.StateInit = ""
+
+	_, err := s.service.CheckProof(s.ctx, user, request)
+	s.NoError(err)
+}
+
+func (s *TonProofTestSuite) TestCheckProof_Rejected() {
+	w := s.newWallet(wallet.V4R2)
+	other := s.newWallet(wallet.V4R2)
+
+	used := s.payload()
+	_, err := s.service.CheckProof(s.ctx, user, s.request(w, used, domain, time.Now()))
+	require.NoError(s.T(), err)
+
+	cases := []struct {
+		name    string
+		request func() ton_dto.TonProofRequest
+		code    int
+	}{
+		{
+			name:    "reused payload",
+			request: func() ton_dto.TonProofRequest { return s.request(w, used, domain, time.Now()) },
+			code:    401,
+		},
+		{
+			name:    "unknown payload",
+			request: func() ton_dto.TonProofRequest { return s.request(w, "forged", domain, time.Now()) },
+			code:    401,
+		},
+		{
+			name:    "other domain",
+			request: func() ton_dto.TonProofRequest { return s.request(w, s.payload(), "evil.example", time.Now()) },
+			code:    401,
+		},
+		{
+			name:    "expired proof",
+			request: func() ton_dto.TonProofRequest { return s.request(w, s.payload(), domain, time.Now().Add(-time.Hour)) },
+			code:    401,
+		},
+		{
+			name: "signed by another key",
+			request: func() ton_dto.TonProofRequest {
+				request := s.request(other, s.payload(), domain, time.Now())
+				request.Address = w.address.StringRaw()
+				request.PublicKey = ""
+				request.Proof.StateInit = w.stateInit
+				return request
+			},
+			code: 401,
+		},
+		{
+			name: "state init of another wallet",
+			request: func() ton_dto.TonProofRequest {
+				request := s.request(w, s.payload(), domain, time.Now())
+				request.Proof.StateInit = other.stateInit
+				return request
+			},
+			code: 401,
+		},
+		{
+			name: "public key mismatch",
+			request: func() ton_dto.TonProofRequest {
+				request := s.request(w, s.payload(), domain, time.Now())
+				request.PublicKey = hex.EncodeToString(other.publicKey)
+				return request
+			},
+			code: 401,
+		},
+		{
+			name: "not deployed without state init",
+			request: func() ton_dto.TonProofRequest {
+				request := s.request(w, s.payload(), domain, time.Now())
+				request.Proof.StateInit = ""
+				return request
+			},
+			code: 401,
+		},
+		{
+			name: "malformed address",
+			request: func() ton_dto.TonProofRequest {
+				request := s.request(w, s.payload(), domain, time.Now())
+				request.Address = "not-an-address"
+				return request
+			},
+			code: 400,
+		},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			_, err := s.service.CheckProof(s.ctx, user, tc.request())
+			s.Equal(tc.code, errors.GetCode(err), "unexpected result: %v", err)
+		})
+	}
+}
+
+func (s *TonProofTestSuite) TestCheckProof_ForgedKeepsPayload() {
+	w := s.newWallet(wallet.V4R2)
+	other := s.newWallet(wallet.V4R2)
+	payload := s.payload()
+
+	forged := s.request(other, payload, domain, time.Now())
+	forged.Address = w.address.StringRaw()
+	forged.PublicKey = ""
+	forged.Proof.StateInit = w.stateInit
+
+	_, err := s.service.CheckProof(s.ctx, user, forged)
+	s.Equal(401, errors.GetCode(err), "unexpected result: %v", err)
+
+	_, err = s.service.CheckProof(s.ctx, user, s.request(w, payload, domain, time.Now()))
+	s.NoError(err, "a forged proof should not burn the payload of the wallet")
+}
+
+func TestTonProofTestSuite(t *testing.T) {
+	suite.Run(t, new(TonProofTestSuite))
+}
@@ -0,0 +1,71 @@
+package validation_events_test
+
+import (
+	"testing"
+
+	validation_events "github.com/root9464/Go_GamlerDefi/src/modules/validation/events"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	transactionID  = "682a67342a36c14af648479b"
+	paymentOrderID = "6826ac79ff2f0eb00db5fa1d"
+)
+
+type StatusBrokerTestSuite struct {
+	suite.Suite
+	broker validation_events.IStatusBroker
+}
+
+func (s *StatusBrokerTestSuite) SetupTest() {
+	s.broker = validation_events.NewStatusBroker()
+}
+
+func (s *StatusBrokerTestSuite) TestSubscribeByTransactionID() {
+	events, unsubscribe := s.broker.Subscribe(validation_events.Filter{TransactionID: transactionID})
+	defer unsubscribe()
+
+	s.broker.Publish(validation_events.StatusEvent{TransactionID: "682a67342a36c14af648479c", Status: validation_model.WorkerStatusRunning})
+	s.broker.Publish(validation_events.StatusEvent{TransactionID: transactionID, Status: validation_model.WorkerStatusSuccess})
+
+	require.Len(s.T(), events, 1, "Only events of the transaction should be delivered")
+	assert.Equal(s.T(), validation_model.WorkerStatusSuccess, (<-events).Status)
+}
+
+func (s *StatusBrokerTestSuite) TestSubscribeByPaymentOrderID() {
+	events, unsubscribe := s.broker.Subscribe(validation_events.Filter{PaymentOrderID: paymentOrderID})
+	defer unsubscribe()
+
+	s.broker.Publish(validation_events.StatusEvent{TransactionID: transactionID, PaymentOrderID: paymentOrderID, Status: validation_model.WorkerStatusWaiting})
+	s.broker.Publish(validation_events.StatusEvent{TransactionID: transactionID, Status: validation_model.WorkerStatusWaiting})
+
+	require.Len(s.T(), events, 1, "Only events of the payment order should be delivered")
+	assert.Equal(s.T(), transactionID, (<-events).TransactionID)
+}
+
+func (s *StatusBrokerTestSuite) TestUnsubscribe() {
+	events, unsubscribe := s.broker.Subscribe(validation_events.Filter{TransactionID: transactionID})
+	unsubscribe()
+	unsubscribe()
+
+	s.broker.Publish(validation_events.StatusEvent{TransactionID: transactionID, Status: validation_model.WorkerStatusSuccess})
+
+	_, ok := <-events
+	assert.False(s.T(), ok, "Channel should be closed after unsubscribe")
+}
+
+func (s *StatusBrokerTestSuite) TestSlowSubscriberDoesNotBlock() {
+	_, unsubscribe := s.broker.Subscribe(validation_events.Filter{TransactionID: transactionID})
+	defer unsubscribe()
+
+	for range 100 {
+		s.broker.Publish(validation_events.StatusEvent{TransactionID: transactionID, Status: validation_model.WorkerStatusWaiting})
+	}
+}
+
+func TestStatusBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusBrokerTestSuite))
+}
@@ -3,6 +3,7 @@ package validation_repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/root9464/Go_GamlerDefi/src/database"
 	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
@@ -32,7 +33,7 @@ func (s *ValidationRepositoryTestSuite) SetupSuite() {
 	_, database, err := database.ConnectDatabase(db_url, s.logger, db_name)
 	require.NoError(s.T(), err, "Failed to connect to database")
 	s.db = database
-	repo := validation_repository.NewValidationRepository(s.logger, s.db)
+	repo := validation_repository.NewValidationRepository(s.logger, s.db, nil)
 	s.repository = repo
 }
 
@@ -68,12 +69,59 @@ func (s *ValidationRepositoryTestSuite) TestUpdateStatus() {
 	ctx := context.Background()
 	observerId, err := bson.ObjectIDFromHex("none")
 	require.NoError(s.T(), err, "Failed to convert observer id to bson.ObjectID")
-	tr, err := s.repository.UpdateStatus(ctx, observerId, validation_model.WorkerStatusFailed)
+	tr, err := s.repository.UpdateStatus(ctx, observerId, validation_model.WorkerStatusRunning, validation_model.WorkerStatusFailed, validation_model.StatusChange{
+		Reason: validation_model.ReasonTimeout,
+	})
 	s.logger.Infof("transaction: %+v", tr)
 	require.NoError(s.T(), err, "Failed to update status")
 	require.NotNil(s.T(), tr, "Transaction should not be nil")
 }
 
+func (s *ValidationRepositoryTestSuite) TestUpdateStatusConflict() {
+	ctx := context.Background()
+	transaction, err := s.repository.CreateTransactionObserver(ctx, validation_model.WorkerTransaction{
+		TxHash:        bson.NewObjectID().Hex(),
+		TxQueryID:     1747000636,
+		TargetAddress: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX",
+		Status:        validation_model.WorkerStatusPending,
+	})
+	require.NoError(s.T(), err, "Failed to create transaction observer")
+	defer s.repository.DeleteTransactionObserver(ctx, transaction.ID)
+
+	_, err = s.repository.UpdateStatus(ctx, transaction.ID, validation_model.WorkerStatusPending, validation_model.WorkerStatusSuccess, validation_model.StatusChange{
+		Reason: validation_model.ReasonValidated,
+	})
+	conflict := new(validation_repository.StatusConflictError)
+	require.ErrorAs(s.T(), err, &conflict, "Pending transaction should not move to success")
+	require.Empty(s.T(), conflict.Actual)
+
+	_, err = s.repository.PrecheckoutTransaction(ctx, transaction.ID)
+	require.NoError(s.T(), err, "Failed to precheckout transaction")
+
+	_, err = s.repository.UpdateStatus(ctx, transaction.ID, validation_model.WorkerStatusPending, validation_model.WorkerStatusRunning, validation_model.StatusChange{
+		Reason: validation_model.ReasonRecheck,
+	})
+	require.ErrorAs(s.T(), err, &conflict, "Transaction should be moved to running only once")
+	require.Equal(s.T(), validation_model.WorkerStatusRunning, conflict.Actual)
+
+	updated, err := s.repository.UpdateStatus(ctx, transaction.ID, validation_model.WorkerStatusRunning, validation_model.WorkerStatusWaiting, validation_model.StatusChange{
+		Reason: validation_model.ReasonActionPhaseFailed,
+		Trace:  &validation_model.TraceSummary{Hash: transaction.TxHash, Transactions: 2},
+	})
+	require.NoError(s.T(), err, "Failed to move transaction to waiting")
+	require.Equal(s.T(), validation_model.WorkerStatusWaiting, updated.Status)
+
+	require.Len(s.T(), updated.History, 3, "Submit, precheckout and waiting should be recorded")
+	require.Equal(s.T(), validation_model.ReasonSubmitted, updated.History[0].Reason)
+	require.Equal(s.T(), validation_model.ReasonPrecheckout, updated.History[1].Reason)
+	last := updated.History[2]
+	require.Equal(s.T(), validation_model.WorkerStatusRunning, last.From)
+	require.Equal(s.T(), validation_model.WorkerStatusWaiting, last.To)
+	require.Equal(s.T(), validation_model.ReasonActionPhaseFailed, last.Reason)
+	require.NotNil(s.T(), last.Trace)
+	require.Equal(s.T(), validation_repository.Instance, last.Instance)
+}
+
 func (s *ValidationRepositoryTestSuite) TestPrecheckoutTransaction() {
 	ctx := context.Background()
 	observerId, err := bson.ObjectIDFromHex("none")
@@ -90,6 +138,102 @@ func (s *ValidationRepositoryTestSuite) TestDeleteTransactionObserver() {
 	require.NoError(s.T(), err, "Failed to delete transaction observer")
 }
 
+func (s *ValidationRepositoryTestSuite) TestClaimTransactionObserver() {
+	ctx := context.Background()
+	transaction, err := s.repository.CreateTransactionObserver(ctx, validation_model.WorkerTransaction{
+		TxHash:        bson.NewObjectID().Hex(),
+		TxQueryID:     1747000636,
+		TargetAddress: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX",
+		Status:        validation_model.WorkerStatusWaiting,
+		CreatedAt:     1,
+	})
+	require.NoError(s.T(), err, "Failed to create transaction observer")
+	defer s.repository.DeleteTransactionObserver(ctx, transaction.ID)
+
+	now := time.Now()
+	claimed, err := s.repository.ClaimTransactionObserver(ctx, "first", now.Unix(), now.Unix(), now.Add(time.Minute).Unix())
+	require.NoError(s.T(), err, "Failed to claim transaction observer")
+	require.Equal(s.T(), transaction.ID, claimed.ID, "Oldest due transaction should be claimed")
+	require.Equal(s.T(), "first", claimed.LeaseOwner)
+
+	_, err = s.repository.ClaimTransactionObserver(ctx, "second", now.Unix(), now.Unix(), now.Add(time.Minute).Unix())
+	if err == nil {
+		s.T().Log("another due transaction exists in the collection")
+	} else {
+		require.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Leased transaction should not be claimed twice")
+	}
+
+	err = s.repository.ReleaseTransactionObserver(ctx, transaction.ID, "second", now.Unix())
+	require.ErrorIs(s.T(), err, mongo.ErrNoDocuments, "Only the lease owner should release the transaction")
+
+	err = s.repository.ReleaseTransactionObserver(ctx, transaction.ID, "first", now.Add(time.Hour).Unix())
+	require.NoError(s.T(), err, "Failed to release transaction observer")
+
+	released, err := s.repository.GetTransactionObserver(ctx, transaction.ID)
+	require.NoError(s.T(), err, "Failed to get transaction observer")
+	require.Equal(s.T(), 1, released.Attempts)
+	require.Empty(s.T(), released.LeaseOwner)
+	require.Equal(s.T(), now.Add(time.Hour).Unix(), released.NextCheckAt)
+}
+
+func (s *ValidationRepositoryTestSuite) TestGetTransactionObservers() {
+	ctx := context.Background()
+	paymentOrderId := bson.NewObjectID()
+	transaction, err := s.repository.CreateTransactionObserver(ctx, validation_model.WorkerTransaction{
+		TxHash:         bson.NewObjectID().Hex(),
+		TxQueryID:      1747000636,
+		TargetAddress:  "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX",
+		PaymentOrderId: paymentOrderId,
+		Status:         validation_model.WorkerStatusWaiting,
+	})
+	require.NoError(s.T(), err, "Failed to create transaction observer")
+	defer s.repository.DeleteTransactionObserver(ctx, transaction.ID)
+
+	transactions, err := s.repository.GetTransactionObservers(ctx, validation_model.TransactionFilter{
+		Status:         validation_model.WorkerStatusWaiting,
+		PaymentOrderId: paymentOrderId,
+		Limit:          10,
+	})
+	require.NoError(s.T(), err, "Failed to get transaction observers")
+	require.Len(s.T(), transactions, 1)
+	require.Equal(s.T(), transaction.ID, transactions[0].ID)
+
+	transactions, err = s.repository.GetTransactionObservers(ctx, validation_model.TransactionFilter{
+		Status:         validation_model.WorkerStatusSuccess,
+		PaymentOrderId: paymentOrderId,
+		Limit:          10,
+	})
+	require.NoError(s.T(), err, "Failed to get transaction observers")
+	require.Empty(s.T(), transactions)
+}
+
+func (s *ValidationRepositoryTestSuite) TestUniqueTxHash() {
+	ctx := context.Background()
+	require.NoError(s.T(), s.repository.EnsureIndexes(ctx), "Failed to create indexes")
+
+	txHash := bson.NewObjectID().Hex()
+	transaction, err := s.repository.CreateTransactionObserver(ctx, validation_model.WorkerTransaction{
+		TxHash:        txHash,
+		TxQueryID:     1747000636,
+		TargetAddress: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX",
+		Status:        validation_model.WorkerStatusPending,
+	})
+	require.NoError(s.T(), err, "Failed to create transaction observer")
+	defer s.repository.DeleteTransactionObserver(ctx, transaction.ID)
+
+	_, err = s.repository.CreateTransactionObserver(ctx, validation_model.WorkerTransaction{
+		TxHash:        txHash,
+		TxQueryID:     1747000636,
+		TargetAddress: "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX",
+		Status:        validation_model.WorkerStatusPending,
+	})
+	require.True(s.T(), mongo.IsDuplicateKeyError(err), "Second observer of the tx hash should be rejected")
+
+	found, err := s.repository.GetTransactionObserverByTxHash(ctx, txHash)
+	require.NoError(s.T(), err, "Failed to get transaction observer by tx hash")
+	require.Equal(s.T(), transaction.ID, found.ID)
+}
+
 func TestValidationRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(ValidationRepositoryTestSuite))
 }
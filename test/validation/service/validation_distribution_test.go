@@ -0,0 +1,101 @@
+package validation_service_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+)
+
+const (
+	payerAddress    = "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+	contractAddress = "0QC3PUCoxBdLfOmO8xFQ84TGFPQUatxvvRsSAODKEvjbb4OS"
+	firstRecipient  = "0QC9vm__DOB74-HkN9pxfMDMLYT4YlDPYj54dZ9yqvsgXYpZ"
+	secondRecipient = "0QD-q5a1Z3kYfDBgYUcUX_MigynA5FuiNx0i5ySt37rfrFeP"
+)
+
+type DistributionTestSuite struct {
+	suite.Suite
+}
+
+// transferTrace builds the trace of a jetton transfer request sent by `from`.
+func (s *DistributionTestSuite) transferTrace(from string, to string, amount string, children ...tonapi.Trace) tonapi.Trace {
+	payload, err := tlb.ToCell(jetton.TransferPayload{
+		QueryID:             1,
+		Amount:              tlb.MustFromDecimal(amount, 9),
+		Destination:         address.MustParseAddr(to),
+		ResponseDestination: address.NewAddressNone(),
+		ForwardTONAmount:    tlb.ZeroCoins,
+	})
+	require.NoError(s.T(), err, "failed to build transfer payload")
+
+	var msg tonapi.Message
+	msg.Source.SetTo(tonapi.AccountAddress{Address: address.MustParseAddr(from).StringRaw()})
+	msg.RawBody.SetTo(hex.EncodeToString(payload.ToBOC()))
+
+	var trace tonapi.Trace
+	trace.Transaction.InMsg.SetTo(msg)
+	trace.Children = children
+	return trace
+}
+
+func (s *DistributionTestSuite) expectation(total string) validation_dto.PaymentExpectation {
+	return validation_dto.PaymentExpectation{
+		TotalAmount: decimal.RequireFromString(total),
+		Recipients: []validation_dto.ExpectedTransfer{
+			{Address: firstRecipient, Amount: decimal.RequireFromString("100")},
+			{Address: secondRecipient, Amount: decimal.RequireFromString("50")},
+		},
+	}
+}
+
+func (s *DistributionTestSuite) TestVerifyDistribution_Success() {
+	trace := s.transferTrace(payerAddress, contractAddress, "150",
+		s.transferTrace(contractAddress, firstRecipient, "60"),
+		s.transferTrace(contractAddress, firstRecipient, "40"),
+		s.transferTrace(contractAddress, secondRecipient, "50"),
+	)
+
+	status, reason := validation_service.VerifyDistribution(&trace, payerAddress, s.expectation("150"))
+	assert.Equal(s.T(), validation_dto.WorkerStatusSuccess, status, reason)
+}
+
+func (s *DistributionTestSuite) TestVerifyDistribution_TotalMismatch() {
+	trace := s.transferTrace(payerAddress, contractAddress, "149",
+		s.transferTrace(contractAddress, firstRecipient, "100"),
+		s.transferTrace(contractAddress, secondRecipient, "49"),
+	)
+
+	status, _ := validation_service.VerifyDistribution(&trace, payerAddress, s.expectation("150"))
+	assert.Equal(s.T(), validation_dto.WorkerStatusMismatch, status)
+}
+
+func (s *DistributionTestSuite) TestVerifyDistribution_RecipientMismatch() {
+	trace := s.transferTrace(payerAddress, contractAddress, "150",
+		s.transferTrace(contractAddress, firstRecipient, "100"),
+	)
+
+	status, reason := validation_service.VerifyDistribution(&trace, payerAddress, s.expectation("150"))
+	assert.Equal(s.T(), validation_dto.WorkerStatusMismatch, status)
+	assert.Contains(s.T(), reason, secondRecipient)
+}
+
+func (s *DistributionTestSuite) TestVerifyDistribution_Waiting() {
+	trace := s.transferTrace(payerAddress, contractAddress, "150")
+
+	status, _ := validation_service.VerifyDistribution(&trace, payerAddress, s.expectation("150"))
+	assert.Equal(s.T(), validation_dto.WorkerStatusWaiting, status)
+}
+
+func TestDistributionTestSuite(t *testing.T) {
+	suite.Run(t, new(DistributionTestSuite))
+}
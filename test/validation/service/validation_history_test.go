@@ -0,0 +1,52 @@
+package validation_service_test
+
+import (
+	"testing"
+
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/tonkeeper/tonapi-go"
+)
+
+type HistoryTestSuite struct {
+	suite.Suite
+}
+
+func (s *HistoryTestSuite) transaction(hash string, success bool, resultCode int32, children ...tonapi.Trace) tonapi.Trace {
+	var trace tonapi.Trace
+	trace.Transaction.Hash = hash
+	trace.Transaction.Success = success
+	trace.Transaction.ActionPhase.SetTo(tonapi.ActionPhase{Success: resultCode == 0, ResultCode: resultCode})
+	trace.Children = children
+	return trace
+}
+
+func (s *HistoryTestSuite) TestSummarizeTrace_Success() {
+	trace := s.transaction("root", true, 0,
+		s.transaction("child", true, 0, s.transaction("grandchild", true, 0)),
+	)
+
+	summary := validation_service.SummarizeTrace(&trace)
+	assert.Equal(s.T(), "root", summary.Hash)
+	assert.Equal(s.T(), 3, summary.Transactions)
+	assert.True(s.T(), summary.Success)
+	assert.Empty(s.T(), summary.FailedHash)
+}
+
+func (s *HistoryTestSuite) TestSummarizeTrace_FirstFailure() {
+	trace := s.transaction("root", true, 0,
+		s.transaction("child", true, 0, s.transaction("grandchild", false, 37)),
+		s.transaction("sibling", false, 34),
+	)
+
+	summary := validation_service.SummarizeTrace(&trace)
+	assert.Equal(s.T(), 4, summary.Transactions)
+	assert.False(s.T(), summary.Success)
+	assert.Equal(s.T(), "grandchild", summary.FailedHash)
+	assert.Equal(s.T(), int32(37), summary.ActionResultCode)
+}
+
+func TestHistoryTestSuite(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
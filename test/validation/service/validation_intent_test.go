@@ -0,0 +1,255 @@
+package validation_service_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/xssnick/tonutils-go/address"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	intentPayer        = "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+	intentRecipient    = "EQBQAMflxhyqE0OlZNsuVrNuVrxN_PudrtiYBw43ojP5u292"
+	intentJettonMaster = "EQDy6a9Smm8T7n6Jqrx9LKfS32FzEyiG2MZziHa6N5U1IHtQ"
+	intentAmount       = "1500000000"
+)
+
+// stubTransfers returns the same transfers for every jetton wallet.
+type stubTransfers struct {
+	transfers []validation_trace.IncomingTransfer
+}
+
+func (s *stubTransfers) IncomingTransfers(ctx context.Context, owner string, jettonMaster string) ([]validation_trace.IncomingTransfer, error) {
+	return s.transfers, nil
+}
+
+type PaymentIntentTestSuite struct {
+	suite.Suite
+	logger     *logger.Logger
+	transfers  *stubTransfers
+	repository validation_repository.IValidationRepository
+	service    validation_service.IPaymentIntentService
+}
+
+func (s *PaymentIntentTestSuite) SetupTest() {
+	s.logger = logger.GetLogger()
+	s.transfers = &stubTransfers{}
+	s.repository = validation_repository.NewMemoryRepository(nil)
+	s.service = validation_service.NewPaymentIntentService(s.logger, validation_service.PaymentIntentOptions{
+		Recipient:    intentRecipient,
+		JettonMaster: intentJettonMaster,
+		TTL:          30 * time.Minute,
+	}, s.transfers, s.repository)
+}
+
+func (s *PaymentIntentTestSuite) transfer(queryID uint64, sender string, amount string, success bool) validation_trace.IncomingTransfer {
+	value, ok := new(big.Int).SetString(amount, 10)
+	require.True(s.T(), ok)
+	return validation_trace.IncomingTransfer{
+		TxHash:  "5a0c3f4f0b1a9c7b2f1e8d6c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928",
+		Lt:      56166043000001,
+		Utime:   time.Now().Unix(),
+		QueryID: queryID,
+		Amount:  value,
+		Sender:  address.MustParseAddr(sender).StringRaw(),
+		Success: success,
+	}
+}
+
+func (s *PaymentIntentTestSuite) TestCreatePaymentIntent() {
+	ctx := context.Background()
+
+	intent, err := s.service.CreatePaymentIntent(ctx, &validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: intentAmount})
+	require.NoError(s.T(), err)
+	assert.NotZero(s.T(), intent.QueryID, "The server should generate the query ID")
+	assert.Equal(s.T(), intentRecipient, intent.Recipient, "The platform contract should be the default recipient")
+	assert.Equal(s.T(), intentJettonMaster, intent.JettonMaster, "The platform jetton should be the default")
+	assert.Equal(s.T(), validation_dto.PaymentIntentOpen, intent.Status)
+	assert.InDelta(s.T(), time.Now().Add(30*time.Minute).Unix(), intent.ExpiresAt, 5)
+
+	other, err := s.service.CreatePaymentIntent(ctx, &validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: intentAmount, ExpiresIn: 60})
+	require.NoError(s.T(), err)
+	assert.NotEqual(s.T(), intent.QueryID, other.QueryID)
+	assert.InDelta(s.T(), time.Now().Add(time.Minute).Unix(), other.ExpiresAt, 5)
+
+	stored, err := s.service.GetPaymentIntent(ctx, intent.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), intent.QueryID, stored.QueryID)
+}
+
+func (s *PaymentIntentTestSuite) TestCreatePaymentIntent_Invalid() {
+	tests := []struct {
+		name   string
+		intent validation_dto.CreatePaymentIntentDTO
+	}{
+		{name: "invalid payer", intent: validation_dto.CreatePaymentIntentDTO{Payer: "not an address", Amount: intentAmount}},
+		{name: "invalid jetton master", intent: validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, JettonMaster: "EQ", Amount: intentAmount}},
+		{name: "zero amount", intent: validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: "0"}},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			_, err := s.service.CreatePaymentIntent(context.Background(), &test.intent)
+			assert.Equal(s.T(), 400, errors.GetCode(err))
+		})
+	}
+}
+
+func (s *PaymentIntentTestSuite) TestMatchPaymentIntents() {
+	tests := []struct {
+		name    string
+		sender  string
+		amount  string
+		success bool
+		other   bool
+		status  validation_dto.PaymentIntentStatus
+		noted   bool
+	}{
+		{name: "paid", sender: intentPayer, amount: intentAmount, success: true, status: validation_dto.PaymentIntentPaid},
+		{name: "other amount", sender: intentPayer, amount: "1000000000", success: true, status: validation_dto.PaymentIntentOpen, noted: true},
+		{name: "other payer", sender: intentRecipient, amount: intentAmount, success: true, status: validation_dto.PaymentIntentOpen, noted: true},
+		{name: "rejected transfer", sender: intentPayer, amount: intentAmount, status: validation_dto.PaymentIntentOpen},
+		{name: "other query id", sender: intentPayer, amount: intentAmount, success: true, other: true, status: validation_dto.PaymentIntentOpen},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.SetupTest()
+			ctx := context.Background()
+
+			intent, err := s.service.CreatePaymentIntent(ctx, &validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: intentAmount})
+			require.NoError(s.T(), err)
+
+			queryID := intent.QueryID
+			if test.other {
+				queryID++
+			}
+			s.transfers.transfers = []validation_trace.IncomingTransfer{s.transfer(queryID, test.sender, test.amount, test.success)}
+
+			_, err = s.service.MatchPaymentIntents(ctx)
+			require.NoError(s.T(), err)
+
+			matched, err := s.service.GetPaymentIntent(ctx, intent.ID)
+			require.NoError(s.T(), err)
+			assert.Equal(s.T(), test.status, matched.Status)
+			if test.noted {
+				assert.Contains(s.T(), matched.Detail, s.transfers.transfers[0].TxHash, "The mismatch should be explained")
+			}
+			if test.status != validation_dto.PaymentIntentOpen {
+				assert.Equal(s.T(), s.transfers.transfers[0].TxHash, matched.TxHash)
+			} else {
+				assert.Empty(s.T(), matched.TxHash)
+			}
+		})
+	}
+}
+
+func (s *PaymentIntentTestSuite) TestMatchPaymentIntents_MismatchFirst() {
+	ctx := context.Background()
+
+	intent, err := s.service.CreatePaymentIntent(ctx, &validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: intentAmount})
+	require.NoError(s.T(), err)
+
+	spoiler := s.transfer(intent.QueryID, intentRecipient, "1", true)
+	s.transfers.transfers = []validation_trace.IncomingTransfer{spoiler}
+	settled, err := s.service.MatchPaymentIntents(ctx)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), settled, "A transfer from someone else should not settle the intent")
+
+	payment := s.transfer(intent.QueryID, intentPayer, intentAmount, true)
+	payment.TxHash = "6b1d4a5a1c2b0d8c3a2f9e7d5c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a39"
+	s.transfers.transfers = append(s.transfers.transfers, payment)
+	settled, err = s.service.MatchPaymentIntents(ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, settled)
+
+	paid, err := s.service.GetPaymentIntent(ctx, intent.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), validation_dto.PaymentIntentPaid, paid.Status)
+	assert.Equal(s.T(), payment.TxHash, paid.TxHash)
+	assert.Contains(s.T(), paid.Detail, spoiler.TxHash, "The ignored transfer should stay noted")
+}
+
+func (s *PaymentIntentTestSuite) TestMatchPaymentIntents_Expired() {
+	ctx := context.Background()
+
+	intent, err := s.service.CreatePaymentIntent(ctx, &validation_dto.CreatePaymentIntentDTO{Payer: intentPayer, Amount: intentAmount, ExpiresIn: 60})
+	require.NoError(s.T(), err)
+
+	id, err := bson.ObjectIDFromHex(intent.ID)
+	require.NoError(s.T(), err)
+	expired, err := s.repository.ExpirePaymentIntents(ctx, time.Now().Add(2*time.Minute).Unix(), []bson.ObjectID{id})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), expired)
+
+	s.transfers.transfers = []validation_trace.IncomingTransfer{s.transfer(intent.QueryID, intentPayer, intentAmount, true)}
+	settled, err := s.service.MatchPaymentIntents(ctx)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), settled, "Expired intents should not be paid")
+
+	stored, err := s.service.GetPaymentIntent(ctx, intent.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), validation_dto.PaymentIntentExpired, stored.Status)
+}
+
+func (s *PaymentIntentTestSuite) TestMatchPaymentIntents_Overdue() {
+	tests := []struct {
+		name   string
+		sentAt int64
+		paid   bool
+		status validation_dto.PaymentIntentStatus
+	}{
+		{name: "paid in time", sentAt: -2 * 60, paid: true, status: validation_dto.PaymentIntentPaid},
+		{name: "paid too late", sentAt: 30, paid: true, status: validation_dto.PaymentIntentExpired},
+		{name: "not paid", status: validation_dto.PaymentIntentExpired},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.SetupTest()
+			ctx := context.Background()
+
+			expiresAt := time.Now().Add(-time.Minute).Unix()
+			intent, err := s.repository.CreatePaymentIntent(ctx, validation_model.PaymentIntent{
+				QueryID:      42,
+				Payer:        intentPayer,
+				Recipient:    intentRecipient,
+				JettonMaster: intentJettonMaster,
+				Amount:       intentAmount,
+				Status:       validation_model.PaymentIntentOpen,
+				ExpiresAt:    expiresAt,
+			})
+			require.NoError(s.T(), err)
+
+			if test.paid {
+				transfer := s.transfer(intent.QueryID, intentPayer, intentAmount, true)
+				transfer.Utime = expiresAt + test.sentAt
+				s.transfers.transfers = []validation_trace.IncomingTransfer{transfer}
+			}
+
+			_, err = s.service.MatchPaymentIntents(ctx)
+			require.NoError(s.T(), err)
+
+			stored, err := s.service.GetPaymentIntent(ctx, intent.ID.Hex())
+			require.NoError(s.T(), err)
+			assert.Equal(s.T(), test.status, stored.Status)
+		})
+	}
+}
+
+func TestPaymentIntentTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentIntentTestSuite))
+}
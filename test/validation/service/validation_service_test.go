@@ -10,6 +10,7 @@ import (
 	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
 	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
 	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
 
 	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
 	"github.com/stretchr/testify/require"
@@ -47,8 +48,8 @@ func (s *ValidationServiceTestSuite) SetupSuite() {
 	s.ton_api = client
 	s.database = database
 
-	s.repository = validation_repository.NewValidationRepository(s.logger, s.database)
-	s.service = validation_service.NewValidationService(s.logger, s.ton_api, s.repository)
+	s.repository = validation_repository.NewValidationRepository(s.logger, s.database, nil)
+	s.service = validation_service.NewValidationService(s.logger, validation_trace.NewTonapiProvider(s.ton_api), s.repository, nil)
 }
 
 func (s *ValidationServiceTestSuite) MockTransaction() validation_dto.WorkerTransactionDTO {
@@ -0,0 +1,411 @@
+package validation_service_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	validation_dto "github.com/root9464/Go_GamlerDefi/src/modules/validation/dto"
+	validation_model "github.com/root9464/Go_GamlerDefi/src/modules/validation/model"
+	validation_repository "github.com/root9464/Go_GamlerDefi/src/modules/validation/repository"
+	validation_service "github.com/root9464/Go_GamlerDefi/src/modules/validation/service"
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	errors "github.com/root9464/Go_GamlerDefi/src/packages/lib/error"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Recorded traces, see test/validation/trace/fixtures.
+const (
+	traceFixtures = "../trace/fixtures"
+
+	traceSuccess           = "105f7620bf78d534941ebcf97dda0dbe8e79c134a8ab346843787c71fe3308d5"
+	traceBounced           = "b0a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"
+	traceActionPhaseFailed = "c0a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"
+	traceWrongDestination  = "d0a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"
+	traceNestedChildren    = "e0a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"
+	traceSecondBranch      = "a0b1c2d3e4f5061728394a5b6c7d8e9fa0b1c2d3e4f5061728394a5b6c7d8e9f"
+	traceMissing           = "f0a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"
+
+	workerTargetAddress = "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+)
+
+// WorkerTestSuite runs the validation steps against an in-memory repository
+// and recorded traces, it needs neither mongo nor tonapi.
+type WorkerTestSuite struct {
+	suite.Suite
+	logger     *logger.Logger
+	traces     validation_trace.ITraceProvider
+	repository validation_repository.IValidationRepository
+	service    validation_service.IValidationService
+}
+
+func (s *WorkerTestSuite) SetupSuite() {
+	s.logger = logger.GetLogger()
+
+	traces, err := validation_trace.NewFixtureProvider(traceFixtures)
+	require.NoError(s.T(), err)
+	s.traces = traces
+}
+
+func (s *WorkerTestSuite) SetupTest() {
+	s.repository = validation_repository.NewMemoryRepository(nil)
+	s.service = validation_service.NewValidationService(s.logger, s.traces, s.repository, nil)
+}
+
+func (s *WorkerTestSuite) transaction(txHash string) validation_dto.WorkerTransactionDTO {
+	return validation_dto.WorkerTransactionDTO{
+		TxHash:         txHash,
+		TxQueryID:      1747000636,
+		TargetAddress:  workerTargetAddress,
+		PaymentOrderId: "6826ac79ff2f0eb00db5fa1d",
+		Status:         validation_dto.WorkerStatusPending,
+		CreatedAt:      time.Now().Unix(),
+		UpdatedAt:      time.Now().Unix(),
+	}
+}
+
+func (s *WorkerTestSuite) lastTransition(transactionID string) validation_model.StatusTransition {
+	id, err := bson.ObjectIDFromHex(transactionID)
+	require.NoError(s.T(), err)
+
+	observer, err := s.repository.GetTransactionObserver(context.Background(), id)
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), observer.History)
+	return observer.History[len(observer.History)-1]
+}
+
+func (s *WorkerTestSuite) TestRunnerTransaction() {
+	ctx := context.Background()
+	existing := s.transaction(traceSuccess)
+	created, ok, err := s.service.RunnerTransaction(ctx, &existing)
+	require.NoError(s.T(), err)
+	require.True(s.T(), ok)
+
+	tests := []struct {
+		name     string
+		mutate   func(*validation_dto.WorkerTransactionDTO)
+		ok       bool
+		code     int
+		existing bool
+	}{
+		{
+			name:   "new transaction is queued",
+			mutate: func(tr *validation_dto.WorkerTransactionDTO) { tr.TxHash = traceNestedChildren },
+			ok:     true,
+			code:   200,
+		},
+		{
+			name:   "transaction not pending",
+			mutate: func(tr *validation_dto.WorkerTransactionDTO) { tr.Status = validation_dto.WorkerStatusRunning },
+			code:   400,
+		},
+		{
+			name:     "same tx hash reuses the observer",
+			mutate:   func(tr *validation_dto.WorkerTransactionDTO) {},
+			ok:       true,
+			code:     200,
+			existing: true,
+		},
+		{
+			name:     "same transaction id returns the observer",
+			mutate:   func(tr *validation_dto.WorkerTransactionDTO) { tr.ID = created.ID },
+			ok:       true,
+			code:     200,
+			existing: true,
+		},
+		{
+			name:   "same tx hash for another payment order",
+			mutate: func(tr *validation_dto.WorkerTransactionDTO) { tr.PaymentOrderId = "6826ac79ff2f0eb00db5fa1e" },
+			code:   409,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			transaction := s.transaction(traceSuccess)
+			test.mutate(&transaction)
+
+			tr, ok, err := s.service.RunnerTransaction(ctx, &transaction)
+			assert.Equal(s.T(), test.ok, ok)
+			assert.Equal(s.T(), test.code, errors.GetCode(err))
+			if test.existing {
+				assert.Equal(s.T(), created.ID, tr.ID, "The existing observer should be returned")
+			}
+		})
+	}
+}
+
+func (s *WorkerTestSuite) TestSubWorkerTransaction() {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		status validation_dto.WorkerStatus
+		ok     bool
+		code   int
+	}{
+		{name: "pending transaction starts running", status: validation_dto.WorkerStatusPending, ok: true, code: 200},
+		{name: "running transaction is already processed", status: validation_dto.WorkerStatusRunning, code: 409},
+		{name: "unknown transaction", code: 400},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.SetupTest()
+			transaction := s.transaction(traceSuccess)
+			transaction.ID = bson.NewObjectID().Hex()
+			if test.status != "" {
+				created, _, err := s.service.RunnerTransaction(ctx, &transaction)
+				require.NoError(s.T(), err)
+				transaction = *created
+			}
+			if test.status == validation_dto.WorkerStatusRunning {
+				_, _, err := s.service.SubWorkerTransaction(ctx, &transaction)
+				require.NoError(s.T(), err)
+			}
+
+			tr, ok, err := s.service.SubWorkerTransaction(ctx, &transaction)
+			assert.Equal(s.T(), test.ok, ok)
+			assert.Equal(s.T(), test.code, errors.GetCode(err))
+			if test.ok {
+				assert.Equal(s.T(), validation_dto.WorkerStatusRunning, tr.Status)
+				assert.Equal(s.T(), validation_model.ReasonPrecheckout, s.lastTransition(tr.ID).Reason)
+			}
+		})
+	}
+}
+
+func (s *WorkerTestSuite) TestWorkerTransaction() {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		txHash string
+		status validation_dto.WorkerStatus
+		reason validation_model.TransitionReason
+		ok     bool
+		code   int
+	}{
+		{name: "success", txHash: traceSuccess, status: validation_dto.WorkerStatusSuccess, reason: validation_model.ReasonValidated, ok: true, code: 200},
+		{name: "bounced", txHash: traceBounced, status: validation_dto.WorkerStatusFailed, reason: validation_model.ReasonComputePhaseFailed, code: 200},
+		{name: "action phase failed", txHash: traceActionPhaseFailed, status: validation_dto.WorkerStatusWaiting, reason: validation_model.ReasonActionPhaseFailed, code: 200},
+		{name: "wrong destination", txHash: traceWrongDestination, status: validation_dto.WorkerStatusFailed, reason: validation_model.ReasonInvalidAccount, code: 400},
+		{name: "nested children", txHash: traceNestedChildren, status: validation_dto.WorkerStatusSuccess, reason: validation_model.ReasonValidated, ok: true, code: 200},
+		{name: "failure in a later branch", txHash: traceSecondBranch, status: validation_dto.WorkerStatusFailed, reason: validation_model.ReasonComputePhaseFailed, code: 200},
+		{name: "trace not recorded", txHash: traceMissing, status: validation_dto.WorkerStatusWaiting, reason: validation_model.ReasonTraceFetchError, code: 502},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			transaction := s.transaction(test.txHash)
+			created, _, err := s.service.RunnerTransaction(ctx, &transaction)
+			require.NoError(s.T(), err)
+			running, _, err := s.service.SubWorkerTransaction(ctx, created)
+			require.NoError(s.T(), err)
+
+			tr, ok, err := s.service.WorkerTransaction(ctx, running)
+			assert.Equal(s.T(), test.ok, ok)
+			assert.Equal(s.T(), test.code, errors.GetCode(err))
+			require.NotNil(s.T(), tr)
+			assert.Equal(s.T(), test.status, tr.Status)
+
+			transition := s.lastTransition(created.ID)
+			assert.Equal(s.T(), test.reason, transition.Reason)
+			if test.reason != validation_model.ReasonTraceFetchError {
+				require.NotNil(s.T(), transition.Trace, "The decision should keep the trace summary")
+				assert.Equal(s.T(), test.txHash, transition.Trace.Hash)
+			}
+		})
+	}
+}
+
+func (s *WorkerTestSuite) TestEvaluateTrace() {
+	tests := []struct {
+		name   string
+		txHash string
+		status validation_dto.WorkerStatus
+		nodes  int
+		failed string
+	}{
+		{name: "success", txHash: traceSuccess, status: validation_dto.WorkerStatusSuccess, nodes: 2},
+		{name: "bounced", txHash: traceBounced, status: validation_dto.WorkerStatusFailed, nodes: 3, failed: "b1a1c2d3e4f5061728394a5b6c7d8e9fb0a1c2d3e4f5061728394a5b6c7d8e9f"},
+		{name: "action phase failed", txHash: traceActionPhaseFailed, status: validation_dto.WorkerStatusWaiting, nodes: 1, failed: traceActionPhaseFailed},
+		{name: "nested children", txHash: traceNestedChildren, status: validation_dto.WorkerStatusSuccess, nodes: 5},
+		{name: "failure in a later branch", txHash: traceSecondBranch, status: validation_dto.WorkerStatusFailed, nodes: 4, failed: "a3b1c2d3e4f5061728394a5b6c7d8e9fa0b1c2d3e4f5061728394a5b6c7d8e9f"},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			trace, err := s.traces.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: test.txHash})
+			require.NoError(s.T(), err)
+
+			status, report := validation_service.EvaluateTrace(trace)
+			assert.Equal(s.T(), test.status, status)
+			assert.Equal(s.T(), test.status, validation_service.IsTransactionValid(trace))
+			require.Len(s.T(), report, test.nodes, "Every transaction of the trace should be reported")
+			assert.Equal(s.T(), test.txHash, report[0].Hash)
+
+			failed := ""
+			for _, node := range report {
+				if node.Status != validation_model.WorkerStatusSuccess {
+					failed = node.Hash
+					break
+				}
+			}
+			assert.Equal(s.T(), test.failed, failed)
+		})
+	}
+}
+
+func (s *WorkerTestSuite) TestWorkerTransaction_Report() {
+	ctx := context.Background()
+	transaction := s.transaction(traceSecondBranch)
+	created, _, err := s.service.RunnerTransaction(ctx, &transaction)
+	require.NoError(s.T(), err)
+	running, _, err := s.service.SubWorkerTransaction(ctx, created)
+	require.NoError(s.T(), err)
+
+	tr, _, err := s.service.WorkerTransaction(ctx, running)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), tr.Report, 4, "The report should be stored on the observer")
+
+	hop := tr.Report[3]
+	assert.Equal(s.T(), "0:6e5d4c3b2a190807f6e5d4c3b2a190807f6e5d4c3b2a190807f6e5d4c3b2a19", hop.Address)
+	assert.Equal(s.T(), "0x0f8a7ea5", hop.OpCode)
+	assert.Equal(s.T(), 1, hop.Depth)
+	assert.False(s.T(), hop.Success)
+	assert.Equal(s.T(), int32(48), hop.ExitCode)
+	assert.Equal(s.T(), validation_dto.WorkerStatusFailed, hop.Status)
+
+	assert.Contains(s.T(), s.lastTransition(created.ID).Detail, hop.Hash, "The history should name the failed hop")
+}
+
+// paidTraces serves the recorded traces with a jetton transfer of `amount`
+// from the target address added to the first message, so the payment check
+// of the bound order passes.
+type paidTraces struct {
+	validation_trace.ITraceProvider
+	payload string
+}
+
+func (p *paidTraces) GetTrace(ctx context.Context, request validation_trace.TraceRequest) (*tonapi.Trace, error) {
+	trace, err := p.ITraceProvider.GetTrace(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	trace.Transaction.InMsg.Value.Source.SetTo(tonapi.AccountAddress{Address: address.MustParseAddr(workerTargetAddress).StringRaw()})
+	trace.Transaction.InMsg.Value.RawBody.SetTo(p.payload)
+	return trace, nil
+}
+
+// paymentOrders records the orders marked as paid and reopened, marking fails
+// while failPaid is set.
+type paymentOrders struct {
+	failPaid bool
+	paid     []string
+	reopened []string
+}
+
+func (h *paymentOrders) MarkPaymentOrderPaid(ctx context.Context, paymentOrderID string, trHash string) error {
+	if h.failPaid {
+		return fmt.Errorf("payment order storage is unavailable")
+	}
+	h.paid = append(h.paid, paymentOrderID)
+	return nil
+}
+
+func (h *paymentOrders) ReopenPaymentOrder(ctx context.Context, paymentOrderID string) error {
+	h.reopened = append(h.reopened, paymentOrderID)
+	return nil
+}
+
+func (h *paymentOrders) MarkPaymentBundlePaid(ctx context.Context, bundleID string, trHash string) error {
+	return h.MarkPaymentOrderPaid(ctx, bundleID, trHash)
+}
+
+func (h *paymentOrders) ReopenPaymentBundle(ctx context.Context, bundleID string) error {
+	return h.ReopenPaymentOrder(ctx, bundleID)
+}
+
+func (h *paymentOrders) PaymentExpectation(ctx context.Context, paymentOrderID string, bundleID string) (validation_dto.PaymentExpectation, error) {
+	return validation_dto.PaymentExpectation{TotalAmount: decimal.RequireFromString("150")}, nil
+}
+
+func (s *WorkerTestSuite) TestWorkerTransaction_SettlementPending() {
+	ctx := context.Background()
+
+	payload, err := tlb.ToCell(jetton.TransferPayload{
+		QueryID:             1747000636,
+		Amount:              tlb.MustFromDecimal("150", 9),
+		Destination:         address.MustParseAddr(workerTargetAddress),
+		ResponseDestination: address.NewAddressNone(),
+		ForwardTONAmount:    tlb.ZeroCoins,
+	})
+	require.NoError(s.T(), err)
+
+	hook := &paymentOrders{failPaid: true}
+	traces := &paidTraces{ITraceProvider: s.traces, payload: hex.EncodeToString(payload.ToBOC())}
+	service := validation_service.NewValidationService(s.logger, traces, s.repository, hook)
+
+	transaction := s.transaction(traceSuccess)
+	created, _, err := service.RunnerTransaction(ctx, &transaction)
+	require.NoError(s.T(), err)
+	running, _, err := service.SubWorkerTransaction(ctx, created)
+	require.NoError(s.T(), err)
+
+	tr, ok, err := service.WorkerTransaction(ctx, running)
+	assert.False(s.T(), ok)
+	assert.Equal(s.T(), 500, errors.GetCode(err))
+	require.NotNil(s.T(), tr)
+	assert.Equal(s.T(), validation_dto.WorkerStatusWaiting, tr.Status, "An unsettled transaction should not be stored as success")
+	assert.Equal(s.T(), validation_model.ReasonSettlementPending, s.lastTransition(created.ID).Reason)
+	assert.Empty(s.T(), hook.paid)
+
+	hook.failPaid = false
+	tr, err = service.RecheckTransaction(ctx, tr, true)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), validation_dto.WorkerStatusSuccess, tr.Status, "The scheduler should retry the settlement")
+	assert.Equal(s.T(), validation_model.ReasonValidated, s.lastTransition(created.ID).Reason)
+	assert.Equal(s.T(), []string{transaction.PaymentOrderId}, hook.paid)
+}
+
+func (s *WorkerTestSuite) TestWorkerTransaction_TraceFetchError() {
+	ctx := context.Background()
+
+	hook := &paymentOrders{}
+	service := validation_service.NewValidationService(s.logger, s.traces, s.repository, hook)
+
+	transaction := s.transaction(traceMissing)
+	created, _, err := service.RunnerTransaction(ctx, &transaction)
+	require.NoError(s.T(), err)
+	running, _, err := service.SubWorkerTransaction(ctx, created)
+	require.NoError(s.T(), err)
+
+	tr, ok, err := service.WorkerTransaction(ctx, running)
+	assert.False(s.T(), ok)
+	assert.Equal(s.T(), 502, errors.GetCode(err))
+	require.NotNil(s.T(), tr)
+	assert.Equal(s.T(), validation_dto.WorkerStatusWaiting, tr.Status, "A missing trace should be fetched again")
+	assert.Empty(s.T(), hook.reopened, "The orders should stay awaiting payment while the trace is fetched again")
+
+	tr, err = service.RecheckTransaction(ctx, tr, true)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), validation_dto.WorkerStatusFailed, tr.Status, "The transaction should fail at the deadline")
+	assert.Equal(s.T(), validation_model.ReasonDeadlineExceeded, s.lastTransition(created.ID).Reason)
+	assert.Equal(s.T(), []string{transaction.PaymentOrderId}, hook.reopened)
+}
+
+func TestWorkerTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkerTestSuite))
+}
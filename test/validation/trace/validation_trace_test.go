@@ -0,0 +1,77 @@
+package validation_trace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	validation_trace "github.com/root9464/Go_GamlerDefi/src/modules/validation/trace"
+	"github.com/root9464/Go_GamlerDefi/src/packages/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/tonkeeper/tonapi-go"
+)
+
+const (
+	fixtures      = "fixtures"
+	txHash        = "105f7620bf78d534941ebcf97dda0dbe8e79c134a8ab346843787c71fe3308d5"
+	targetAddress = "0QANsjLvOX2MERlT4oyv2bSPEVc9lunSPIs5a1kPthCXydUX"
+)
+
+type failingProvider struct {
+	err error
+}
+
+func (p failingProvider) GetTrace(ctx context.Context, request validation_trace.TraceRequest) (*tonapi.Trace, error) {
+	return nil, p.err
+}
+
+type TraceProviderTestSuite struct {
+	suite.Suite
+	logger   *logger.Logger
+	fixtures validation_trace.ITraceProvider
+}
+
+func (s *TraceProviderTestSuite) SetupSuite() {
+	s.logger = logger.GetLogger()
+
+	provider, err := validation_trace.NewFixtureProvider(fixtures)
+	require.NoError(s.T(), err)
+	s.fixtures = provider
+}
+
+func (s *TraceProviderTestSuite) TestFixtureProvider() {
+	trace, err := s.fixtures.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: txHash, Account: targetAddress})
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), txHash, trace.Transaction.Hash)
+	require.Len(s.T(), trace.Children, 1, "The jetton wallet transaction should be replayed")
+	assert.Equal(s.T(), "0x0f8a7ea5", trace.Children[0].Transaction.InMsg.Value.OpCode.Value)
+}
+
+func (s *TraceProviderTestSuite) TestFixtureNotFound() {
+	_, err := s.fixtures.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: "00" + txHash[2:]})
+	assert.True(s.T(), errors.Is(err, validation_trace.ErrTraceNotFound), "Missing fixture should report trace not found")
+
+	_, err = s.fixtures.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: "../" + txHash})
+	assert.Error(s.T(), err, "Hash should not escape the fixture directory")
+}
+
+func (s *TraceProviderTestSuite) TestFallbackProvider() {
+	unavailable := errors.New("tonapi is unavailable")
+	provider := validation_trace.NewFallbackProvider(s.logger, failingProvider{err: unavailable}, s.fixtures)
+
+	trace, err := provider.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: txHash})
+	require.NoError(s.T(), err, "The next provider should be asked")
+	assert.Equal(s.T(), txHash, trace.Transaction.Hash)
+
+	provider = validation_trace.NewFallbackProvider(s.logger, failingProvider{err: unavailable}, failingProvider{err: validation_trace.ErrTraceNotFound})
+	_, err = provider.GetTrace(context.Background(), validation_trace.TraceRequest{TxHash: txHash})
+	assert.True(s.T(), errors.Is(err, unavailable), "Errors of all providers should be kept")
+	assert.True(s.T(), errors.Is(err, validation_trace.ErrTraceNotFound), "Errors of all providers should be kept")
+}
+
+func TestTraceProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(TraceProviderTestSuite))
+}
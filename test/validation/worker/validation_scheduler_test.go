@@ -0,0 +1,29 @@
+package validation_worker_test
+
+import (
+	"testing"
+	"time"
+
+	validation_worker "github.com/root9464/Go_GamlerDefi/src/modules/validation/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchedulerTestSuite struct {
+	suite.Suite
+}
+
+func (s *SchedulerTestSuite) TestBackoff() {
+	interval := 10 * time.Second
+	maxBackoff := time.Minute
+
+	assert.Equal(s.T(), 10*time.Second, validation_worker.Backoff(interval, maxBackoff, 0))
+	assert.Equal(s.T(), 20*time.Second, validation_worker.Backoff(interval, maxBackoff, 1))
+	assert.Equal(s.T(), 40*time.Second, validation_worker.Backoff(interval, maxBackoff, 2))
+	assert.Equal(s.T(), time.Minute, validation_worker.Backoff(interval, maxBackoff, 3))
+	assert.Equal(s.T(), time.Minute, validation_worker.Backoff(interval, maxBackoff, 1000))
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}